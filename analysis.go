@@ -0,0 +1,147 @@
+package main
+
+// This file builds the end-of-run analysis. Once the exit is reached, the
+// cells walked by the player are compared to the shortest path of the maze.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	ANALYSIS = "analysis"
+	AWIDTH   = 40
+	// number of equal parts of the shortest path timed separately.
+	ANALYSIS_SEGMENTS = 4
+)
+
+// runAnalysis compares a completed run to the shortest path.
+type runAnalysis struct {
+	// share of the cells walked which belong to the shortest path.
+	overlap float64
+	// times the player left the shortest path and the longest
+	// number of cells walked in a row outside of it.
+	wrongTurns    int
+	longestDetour int
+	// time spent on each part of the shortest path.
+	segments []time.Duration
+}
+
+// walkedCells converts the recorded cursor positions into the list
+// of maze cells walked. Wall columns and the top line are skipped.
+func walkedCells(steps []replayStep) ([][2]int, []time.Duration) {
+	var cells [][2]int
+	var times []time.Duration
+	for _, step := range steps {
+		if step.Y < 1 || step.X%2 == 0 {
+			continue
+		}
+
+		cell := [2]int{(step.X - 1) / 2, step.Y - 1}
+		if len(cells) > 0 && cells[len(cells)-1] == cell {
+			continue
+		}
+		cells = append(cells, cell)
+		times = append(times, step.At)
+	}
+
+	return cells, times
+}
+
+// analyzeRun compares the recorded steps of a run to the shortest path.
+func analyzeRun(maze *Grid, steps []replayStep) runAnalysis {
+	var report runAnalysis
+	in, out := mazeDoors(maze)
+	solution := solveMaze(maze, in, out)
+	cells, times := walkedCells(steps)
+	if solution == nil || len(cells) == 0 {
+		return report
+	}
+
+	index := make(map[[2]int]int, len(solution))
+	for i, cell := range solution {
+		index[cell] = i
+	}
+
+	onPath, detour := 0, 0
+	// first time each part of the shortest path is completed.
+	reached := make([]time.Duration, ANALYSIS_SEGMENTS)
+	next := 0
+	for i, cell := range cells {
+		pos, found := index[cell]
+		if !found {
+			if detour == 0 {
+				report.wrongTurns++
+			}
+			detour++
+			if detour > report.longestDetour {
+				report.longestDetour = detour
+			}
+			continue
+		}
+
+		onPath++
+		detour = 0
+		for next < ANALYSIS_SEGMENTS && pos >= (next+1)*len(solution)/ANALYSIS_SEGMENTS-1 {
+			reached[next] = times[i]
+			next++
+		}
+	}
+
+	report.overlap = float64(onPath) / float64(len(cells))
+	var start time.Duration
+	for _, at := range reached[:next] {
+		report.segments = append(report.segments, at-start)
+		start = at
+	}
+
+	return report
+}
+
+// displayAnalysisView shows the analysis of the run just completed
+// on top right of the outputs view.
+func (gm *Game) displayAnalysisView(g *gocui.Gui) {
+	if gm.maze == nil {
+		return
+	}
+
+	report := analyzeRun(gm.maze, gm.run.log)
+	fields := [][2]string{
+		{"Moves made", strconv.Itoa(gm.run.moves)},
+		{"Path overlap", fmt.Sprintf("%.0f%%", report.overlap*100)},
+		{"Wrong turns", strconv.Itoa(report.wrongTurns)},
+		{"Longest detour", trf("%d cells", report.longestDetour)},
+	}
+	for i, d := range report.segments {
+		fields = append(fields, [2]string{trf("Segment %d/%d", i+1, ANALYSIS_SEGMENTS), d.Round(100 * time.Millisecond).String()})
+	}
+	lines := formatFields(fields...)
+
+	maxX, _ := g.Size()
+	analysisView, err := setView(g, ANALYSIS, maxX-AWIDTH-2, 1, maxX-2, len(lines)+2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to create analysis view", "err", err)
+		return
+	}
+
+	analysisView.Title = tr(" Run Analysis ")
+	analysisView.Frame = true
+	analysisView.FgColor = gocui.ColorGreen
+	analysisView.Editable = false
+	analysisView.Wrap = false
+	_, _ = g.SetViewOnTop(ANALYSIS)
+
+	clearView(analysisView)
+	fmt.Fprint(analysisView, strings.Join(lines, "\n"))
+}
+
+// closeAnalysisView removes the run analysis view if any.
+func closeAnalysisView(g *gocui.Gui) {
+	if err := g.DeleteView(ANALYSIS); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete analysis view", "err", err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+// This file implements the auto-run mode. After each move, the cursor keeps
+// advancing through corridors until it reaches a junction or a dead end.
+
+import (
+	"github.com/awesome-gocui/gocui"
+)
+
+var isAutoRun = false
+
+// toggleAutoRun switches the auto-run mode on/off.
+func toggleAutoRun(g *gocui.Gui, v *gocui.View) error {
+	isAutoRun = !isAutoRun
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// isWayFree tells if the cursor can move by (dx, dy) from its position.
+func (gm *Game) isWayFree(v *gocui.View, dx, dy int) bool {
+	switch {
+	case dy > 0:
+		return gm.noWallBelow(v)
+	case dy < 0:
+		return gm.noWallAbove(v)
+	case dx > 0:
+		return gm.noWallOnRight(v)
+	case dx < 0:
+		return gm.noWallOnLeft(v)
+	}
+	return false
+}
+
+// autoRun keeps moving the cursor while there is a single way to go
+// other than going back. (dx, dy) is the direction of the last move.
+func (gm *Game) autoRun(g *gocui.Gui, v *gocui.View, dx, dy int) {
+	if !isAutoRun {
+		return
+	}
+
+	// a corridor cannot be longer than the maze itself.
+	for limit := cursorPositions(gm.maze); limit > 0 && gm.canMove(); limit-- {
+		ways := 0
+		var nextX, nextY int
+		for _, way := range [4][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}} {
+			if way[0] == -dx && way[1] == -dy {
+				continue
+			}
+
+			if gm.isWayFree(v, way[0], way[1]) {
+				ways++
+				nextX, nextY = way[0], way[1]
+			}
+		}
+
+		// stop at junctions and dead ends.
+		if ways != 1 {
+			return
+		}
+
+		dx, dy = nextX, nextY
+		gm.moveMazeCursor(v, dx, dy)
+		cx, cy := gm.mazeCursor(v)
+		publishMove(cx, cy)
+		gm.playerMoved(g, v)
+	}
+}
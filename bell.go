@@ -0,0 +1,192 @@
+package main
+
+// This file rings the terminal bell as audio cues for the players who rely
+// on them. Each cue has its own pattern of beeps so the wall bumps, the
+// checkpoints reached, the solution revealed and the victory sound apart.
+// The B key switches the bell on/off while the settings pick the pattern of
+// each cue, all kept into the config file.
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	// time between two beeps of a pattern and the pause of a dash.
+	BELL_GAP   = 150 * time.Millisecond
+	BELL_PAUSE = 400 * time.Millisecond
+	// longest pattern accepted so a cue never rings for long.
+	BELL_MAX_PATTERN = 12
+)
+
+// bellCue is an event told by the bell. Its pattern is made of stars
+// ringing the bell and dashes pausing. An empty pattern keeps it silent.
+type bellCue struct {
+	name    string
+	label   string
+	pattern string
+}
+
+var (
+	// ring the terminal bell for the audio cues.
+	isBell = false
+
+	bellBump    = &bellCue{"bump", "Bell bump", "*"}
+	bellPickup  = &bellCue{"pickup", "Bell item", "**"}
+	bellHint    = &bellCue{"hint", "Bell hint", "*-*"}
+	bellVictory = &bellCue{"victory", "Bell victory", "***-*"}
+	bellCues    = []*bellCue{bellBump, bellPickup, bellHint, bellVictory}
+
+	// patterns offered by the settings.
+	bellPatterns = []string{"", "*", "**", "***", "*-*", "**-**", "*-*-*", "***-*"}
+	// identifies the latest cue so its beeps cut the ones left of an older one.
+	bellSeq int
+)
+
+// isBellPattern tells if <pattern> is made of stars and dashes only.
+func isBellPattern(pattern string) bool {
+	return len(pattern) <= BELL_MAX_PATTERN && strings.Trim(pattern, "*-") == ""
+}
+
+// toggleBell switches the terminal bell on/off.
+func toggleBell(g *gocui.Gui, v *gocui.View) error {
+	isBell = !isBell
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// ring plays the pattern of the cue <c> when the bell is on.
+func (c *bellCue) ring(g *gocui.Gui) {
+	if isBell {
+		c.play(g)
+	}
+}
+
+// play rings the pattern of the cue <c>. The first beep rings at once and
+// the next ones from the gui loop so they never split the screen updates.
+func (c *bellCue) play(g *gocui.Gui) {
+	bellSeq++
+	seq := bellSeq
+	var at time.Duration
+	for _, r := range c.pattern {
+		if r == '-' {
+			at += BELL_PAUSE
+			continue
+		}
+
+		if at == 0 {
+			beep()
+		} else {
+			time.AfterFunc(at, func() {
+				g.Update(func(g *gocui.Gui) error {
+					if seq == bellSeq {
+						beep()
+					}
+					return nil
+				})
+			})
+		}
+		at += BELL_GAP
+	}
+}
+
+// beep rings the terminal bell once.
+func beep() {
+	fmt.Fprint(os.Stdout, "\a")
+}
+
+// bellSetting returns the bell state as shown into the settings.
+func bellSetting() string {
+	if isBell {
+		return "on"
+	}
+	return "off"
+}
+
+// changeBell switches the bell on/off from the settings.
+func changeBell(g *gocui.Gui, step int) {
+	_ = toggleBell(g, nil)
+}
+
+// patternSetting returns the pattern of <c> as shown into the settings.
+func (c *bellCue) patternSetting() string {
+	if c.pattern == "" {
+		return "off"
+	}
+	return c.pattern
+}
+
+// changePattern switches the pattern of <c> then plays it so the player
+// hears the change even with the bell off. A custom pattern comes back
+// to the first one offered.
+func (c *bellCue) changePattern(g *gocui.Gui, step int) {
+	i := -1
+	for j, p := range bellPatterns {
+		if p == c.pattern {
+			i = j
+		}
+	}
+
+	if i < 0 {
+		c.pattern = bellPatterns[0]
+	} else {
+		c.pattern = bellPatterns[stepIndex(i, len(bellPatterns), step)]
+	}
+	c.play(g)
+}
+
+// editPattern asks a custom pattern for <c>.
+func (c *bellCue) editPattern(g *gocui.Gui) error {
+	return askInput(g, " Pattern (* beep, - pause) ", c.pattern, SETTINGS, func(g *gocui.Gui, text string) error {
+		text = strings.TrimSpace(text)
+		if !isBellPattern(text) {
+			notify("Wrong bell pattern %q", text)
+			return nil
+		}
+
+		c.pattern = text
+		c.play(g)
+		drawSettings(g)
+		return nil
+	}, nil)
+}
+
+// bellSettings returns the lines of the settings screen about the bell.
+func bellSettings() []gameSetting {
+	settings := []gameSetting{{"Bell", bellSetting, changeBell, nil}}
+	for _, c := range bellCues {
+		settings = append(settings, gameSetting{c.label, c.patternSetting, c.changePattern, c.editPattern})
+	}
+	return settings
+}
+
+// loadBellSettings applies the bell <values> of the config file.
+func loadBellSettings(values map[string]string) {
+	if on, err := strconv.ParseBool(values["bell"]); err == nil {
+		isBell = on
+	}
+
+	for _, c := range bellCues {
+		if pattern, found := values["bell_"+c.name]; found {
+			if isBellPattern(pattern) {
+				c.pattern = pattern
+			} else {
+				logWarn("Skipped wrong bell pattern of config file", "cue", c.name, "pattern", pattern)
+			}
+		}
+	}
+}
+
+// writeBellSettings writes the bell settings into the config <b>.
+func writeBellSettings(b *strings.Builder) {
+	fmt.Fprintf(b, "bell: %t\n", isBell)
+	fmt.Fprintf(b, "# beeps (*) and pauses (-) of each cue. empty means silent.\n")
+	for _, c := range bellCues {
+		fmt.Fprintf(b, "bell_%s: %s\n", c.name, configValue(c.pattern))
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+// This file provides the bench command. It times the generation and the
+// solving of mazes for each algorithm and size so performance changes
+// between versions can be measured.
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// benchResult holds the timings of an algorithm on a maze size.
+type benchResult struct {
+	algorithm     string
+	width, height int
+	runs          int
+	genTotal      time.Duration
+	genMin        time.Duration
+	solveTotal    time.Duration
+	solveMin      time.Duration
+}
+
+// benchMazes generates <runs> mazes of a size with an algorithm and solves them with <solve>.
+// The seeds go from 1 to runs so the same mazes are timed each time.
+func benchMazes(algorithm string, solve SolverFunc, width, height, runs int) benchResult {
+	generator, _ := findGenerator(algorithm)
+	result := benchResult{algorithm: algorithm, width: width, height: height, runs: runs}
+
+	for seed := int64(1); seed <= int64(runs); seed++ {
+		start := time.Now()
+		maze := generator(width, height, seededRand(seed), width/2, width/2)
+		elapsed := time.Since(start)
+		result.genTotal += elapsed
+		if result.genMin == 0 || elapsed < result.genMin {
+			result.genMin = elapsed
+		}
+
+		in, out := mazeDoors(maze)
+		start = time.Now()
+		solve(maze, in, out)
+		elapsed = time.Since(start)
+		result.solveTotal += elapsed
+		if result.solveMin == 0 || elapsed < result.solveMin {
+			result.solveMin = elapsed
+		}
+	}
+
+	return result
+}
+
+// runBench times the generation and solving for each algorithm and size
+// then prints a table and optionally writes the results as CSV.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "bench [flags]", "time the generation and solving of mazes for each algorithm and size")
+	sizes := fs.String("sizes", "15x10,25x15,40x20,60x28,100x50", "comma separated <width>x<height> sizes to bench")
+	algorithms := fs.String("algorithms", "", "comma separated algorithms to bench (default all)")
+	runs := fs.Int("runs", 20, "number of mazes timed per algorithm and size")
+	solverName := fs.String("solver", "bfs", "maze solving algorithm timed: "+strings.Join(solverNames(), "/"))
+	csvPath := fs.String("csv", "", "file to write the results as CSV or - for the standard output")
+	workers := fs.String("workers", "", "comma separated numbers of regions carved at once by the parallel algorithm, each timed apart (default all the cpus)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *runs < 1 {
+		return fmt.Errorf("invalid runs %d", *runs)
+	}
+
+	solver, found := findSolver(*solverName)
+	if !found {
+		return fmt.Errorf("unknown maze solver %q. expected one of %s", *solverName, strings.Join(solverNames(), "/"))
+	}
+
+	list, err := parseSizes(*sizes)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if *algorithms == "" {
+		names = generatorNames()
+	} else {
+		for _, name := range strings.Split(*algorithms, ",") {
+			name = strings.TrimSpace(name)
+			if _, found := findGenerator(name); !found {
+				return fmt.Errorf("unknown maze algorithm %q", name)
+			}
+			names = append(names, name)
+		}
+	}
+
+	for _, size := range list {
+		if size[0] < CLI_MIN_SIZE || size[0] > CLI_MAX_SIZE || size[1] < CLI_MIN_SIZE || size[1] > CLI_MAX_SIZE {
+			return fmt.Errorf("maze size %d x %d out of [%d, %d]", size[0], size[1], CLI_MIN_SIZE, CLI_MAX_SIZE)
+		}
+	}
+
+	counts := []int{0}
+	if *workers != "" {
+		counts = nil
+		for _, item := range strings.Split(*workers, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid workers %q", item)
+			}
+			counts = append(counts, n)
+		}
+	}
+
+	var results []benchResult
+	for _, name := range names {
+		for _, size := range list {
+			if name != "parallel" {
+				results = append(results, benchMazes(name, solver, size[0], size[1], *runs))
+				continue
+			}
+
+			// the speedup shows by timing the same mazes with more workers.
+			for _, n := range counts {
+				parallelWorkers = n
+				result := benchMazes(name, solver, size[0], size[1], *runs)
+				if *workers != "" {
+					result.algorithm = fmt.Sprintf("%s/%d", name, n)
+				}
+				results = append(results, result)
+			}
+			parallelWorkers = 0
+		}
+	}
+
+	if *csvPath != "-" {
+		printBenchTable(os.Stdout, results)
+	}
+
+	if *csvPath == "" {
+		return nil
+	}
+
+	var out io.Writer = os.Stdout
+	if *csvPath != "-" {
+		file, err := os.Create(*csvPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	return writeBenchCSV(out, results)
+}
+
+// printBenchTable prints the results as an aligned table.
+func printBenchTable(w io.Writer, results []benchResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "algorithm\tsize\truns\tgen avg\tgen min\tsolve avg\tsolve min\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%dx%d\t%d\t%v\t%v\t%v\t%v\t\n", r.algorithm, r.width, r.height, r.runs,
+			(r.genTotal / time.Duration(r.runs)).Round(time.Microsecond), r.genMin.Round(time.Microsecond),
+			(r.solveTotal / time.Duration(r.runs)).Round(time.Microsecond), r.solveMin.Round(time.Microsecond))
+	}
+	tw.Flush()
+}
+
+// writeBenchCSV writes the results as CSV with durations in nanoseconds.
+func writeBenchCSV(w io.Writer, results []benchResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"algorithm", "width", "height", "runs", "gen_avg_ns", "gen_min_ns", "solve_avg_ns", "solve_min_ns"})
+	for _, r := range results {
+		cw.Write([]string{
+			r.algorithm,
+			strconv.Itoa(r.width),
+			strconv.Itoa(r.height),
+			strconv.Itoa(r.runs),
+			strconv.FormatInt(int64(r.genTotal)/int64(r.runs), 10),
+			strconv.FormatInt(int64(r.genMin), 10),
+			strconv.FormatInt(int64(r.solveTotal)/int64(r.runs), 10),
+			strconv.FormatInt(int64(r.solveMin), 10),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
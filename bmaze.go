@@ -8,20 +8,33 @@ package main
 // Created  : 22 November 2021
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 	"strings"
-	"time"
+	"sync"
+
+	"github.com/jeamon/gomazes/maze"
 )
 
 // assign the 4 directions code to powers of 2.
 // directions are : North, South, East, West.
 const (
-	N = 1 // N : 0001
-	S = 2 // S : 0010
-	E = 4 // E : 0100
-	W = 8 // W : 1000
+	N = maze.N // N : 0001
+	S = maze.S // S : 0010
+	E = maze.E // E : 0100
+	W = maze.W // W : 1000
 )
 
+// wallStacks keeps the walls stacks of the former generations so the
+// batches and the benchmarks generating many mazes reuse their memory
+// rather than growing millions of walls again for each maze.
+var wallStacks = sync.Pool{
+	New: func() interface{} {
+		return new([][3]int32)
+	},
+}
+
 // moveTo returns coordinates (x,y) based on wanted direction.
 func moveTo(posX, posY, direction int) (int, int) {
 
@@ -40,51 +53,59 @@ func moveTo(posX, posY, direction int) (int, int) {
 }
 
 // shuffleDirection shuffles a given array of 4 directions.
-func shuffleDirection(directions *[4]int) {
-	rand.Shuffle(len(*directions), func(i, j int) {
+func shuffleDirection(r *rand.Rand, directions *[4]int) {
+	r.Shuffle(len(*directions), func(i, j int) {
 		(*directions)[i], (*directions)[j] = (*directions)[j], (*directions)[i]
 	})
 }
 
-// maze constructs the full maze data.
-func createMaze(width, height int) *[][]int {
-	// seed sourcing for randomness.
-	rand.Seed(time.Now().UnixNano())
+// seededRand returns a source of randomness always giving the same numbers
+// for <seed>. Each step of a generation gets its own one so a seed keeps
+// producing the same mazes, whatever the other steps draw.
+func seededRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
 
+// createMaze constructs the full maze data with the entrance on top row at
+// column inX and the exit on bottom row at column outX. The randomness comes
+// from <r> so the same seeded source always produces the same maze for a
+// given size and doors.
+func createMaze(width, height int, r *rand.Rand, inX, outX int) *Grid {
 	// map the 4 directions code to their opposite direction.
 	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
 
 	// choose random list of directions.
 	var randomDirections = [4]int{N, S, E, W}
-	shuffleDirection(&randomDirections)
+	shuffleDirection(r, &randomDirections)
 
-	// create 2D maze grid (width x height) with 0 for cells.
-	maze := make([][]int, height)
-	for y := 0; y < height; y++ {
-		maze[y] = make([]int, width)
-		for x := 0; x < width; x++ {
-			maze[y][x] = 0
-		}
-	}
+	// create maze grid (width x height) with 0 for cells.
+	maze := NewGrid(width, height)
 
-	// hold all walls. each wall is made of slice of X / Y / D.
-	var walls [][3]int
+	// hold all walls. each wall is made of slice of X / Y / D. int32
+	// values take half the memory of the stack on huge mazes. the stack
+	// often grows to twice the number of cells so it starts that large.
+	stack := wallStacks.Get().(*[][3]int32)
+	if size := 2*width*height + 8; cap(*stack) < size {
+		*stack = make([][3]int32, 0, size)
+	}
+	walls := (*stack)[:0]
 	// choose a random position as starting cell to dig.
-	startX, startY := rand.Intn(width), rand.Intn(height)
+	startX, startY := r.Intn(width), r.Intn(height)
 
-	// lets fix entrance & outdoor cell position at top/bottom center.
-	inX, inY := width/2, 0
-	outX, outY := width/2, (height - 1)
+	// entrance & outdoor cell positions are on top/bottom rows.
+	inY, outY := 0, (height - 1)
+	// open the entrance north wall.
+	maze.Set(inX, inY, N)
 
 	// add all 4 directions (which constitutes the 4 walls) from the starting cell.
 	for _, d := range randomDirections {
-		walls = append(walls, [3]int{startX, startY, d})
+		walls = append(walls, [3]int32{int32(startX), int32(startY), int32(d)})
 	}
 
 	// add all 4 directions (which constitutes the 4 walls) from the entrace cell.
 	for _, d := range randomDirections {
 		//walls = append(walls, [3]int{startX, startY, d})
-		walls = append(walls, [3]int{inX, inY, d})
+		walls = append(walls, [3]int32{int32(inX), int32(inY), int32(d)})
 	}
 
 	var paths [][2]int
@@ -96,13 +117,13 @@ func createMaze(width, height int) *[][]int {
 		nX, nY := moveTo(x, y, d)
 
 		// new position (nx, ny) must be valid and unvisited cell (value to 0).
-		if nY >= 0 && nY < height && nX >= 0 && nX < width && maze[nY][nX] == 0 {
+		if nY >= 0 && nY < height && nX >= 0 && nX < width && maze.At(nX, nY) == 0 {
 
 			// bitwise (OR) between initial cell (x,y) value and direction which returns value of direction
 			// so something different than 0. This means there is no more wall toward that direction d.
 			// same between new cell (moved to) and opposite/backward direction. just to dig that wall.
-			maze[y][x] = maze[y][x] | d
-			maze[nY][nX] = maze[nY][nX] | oppositeDirections[d]
+			maze.Open(x, y, d)
+			maze.Open(nX, nY, oppositeDirections[d])
 
 			if addPaths {
 				paths = append(paths, [2]int{nX, nY})
@@ -110,19 +131,19 @@ func createMaze(width, height int) *[][]int {
 
 			if nX == outX && nY == outY {
 				// reached the outdoor so open the south wall.
-				maze[nY][nX] = maze[nY][nX] | S
+				maze.Open(nX, nY, S)
 				// fmt.Println("reached outdoor position")
 				// no need to keep track of path solution.
 				addPaths = false
 				// shuffle the paths entries.
-				rand.Shuffle(len(paths), func(i, j int) {
+				r.Shuffle(len(paths), func(i, j int) {
 					paths[i], paths[j] = paths[j], paths[i]
 				})
 				for _, path := range paths {
 					// add all 4 directions (which constitutes the 4 walls) from this cell.
-					shuffleDirection(&randomDirections)
+					shuffleDirection(r, &randomDirections)
 					for _, d := range randomDirections {
-						walls = append(walls, [3]int{path[0], path[1], d})
+						walls = append(walls, [3]int32{int32(path[0]), int32(path[1]), int32(d)})
 					}
 				}
 
@@ -136,75 +157,232 @@ func createMaze(width, height int) *[][]int {
 			}
 
 			// add all 4 directions (which constitutes the 4 walls) from the new cell.
-			shuffleDirection(&randomDirections)
+			shuffleDirection(r, &randomDirections)
 			for _, d := range randomDirections {
-				walls = append(walls, [3]int{nX, nY, d})
+				walls = append(walls, [3]int32{int32(nX), int32(nY), int32(d)})
 			}
 		}
 	}
-	return &maze
+
+	// keep the grown stack for the next generation.
+	*stack = walls[:0]
+	wallStacks.Put(stack)
+	return maze
 	// displayMaze(&maze, width, height)
 }
 
 // getWallInfos retrieves/pop infos of last wall added.
-func getWallInfos(walls *[][3]int) (int, int, int) {
+func getWallInfos(walls *[][3]int32) (int, int, int) {
 	wall := (*walls)[len(*walls)-1]
-	x, y, d := wall[0], wall[1], wall[2]
+	x, y, d := int(wall[0]), int(wall[1]), int(wall[2])
 	(*walls) = (*walls)[:len(*walls)-1]
 	return x, y, d
 }
 
-// formatMaze interprets the slice of slice content into ascii.
-func formatMaze(maze *[][]int, width, height int) strings.Builder {
+// formatMaze interprets the grid content into ascii.
+func formatMaze(maze *Grid, width, height int) strings.Builder {
+	return formatMazeWindow(maze, 0, 0, 2*width+1, height+1)
+}
 
+// formatMazeWindow interprets into ascii only the window of the maze from
+// the cursor position (cx0, cy0) included to (cx1, cy1) excluded, so the
+// cost follows the size of the window rather than the one of the maze. The
+// lines are the ones of formatMaze cut to the window.
+func formatMazeWindow(maze *Grid, cx0, cy0, cx1, cy1 int) strings.Builder {
 	var mazeFormat strings.Builder
 
-	// display first horizontal line. We use 2 underscores since
-	// one will stay above vertical wall (East/West)
-	// we fix entrance cell position at top center.
-	topLine := " " + strings.Repeat("_", (width*2-3)) + " "
-	topLine = topLine[:width] + "   " + topLine[(width+1):]
-	mazeFormat.WriteString(topLine)
+	// keep the window inside the lines of the maze.
+	if cx0 < 0 {
+		cx0 = 0
+	}
+	if cy0 < 0 {
+		cy0 = 0
+	}
+	if right := 2*maze.Width() + 1; cx1 > right {
+		cx1 = right
+	}
+	if bottom := maze.Height() + 1; cy1 > bottom {
+		cy1 = bottom
+	}
+	if cx1 <= cx0 || cy1 <= cy0 {
+		return mazeFormat
+	}
+
+	mazeFormat.Grow((cx1 - cx0 + 1) * (cy1 - cy0))
+	for cy := cy0; cy < cy1; cy++ {
+		if cy > cy0 {
+			mazeFormat.WriteByte('\n')
+		}
+		for cx := cx0; cx < cx1; cx++ {
+			mazeFormat.WriteByte(mazeGlyph(maze, cx, cy))
+		}
+	}
+
+	return mazeFormat
+}
+
+// mazeGlyph returns the ascii character of the cursor position (cx, cy).
+func mazeGlyph(maze *Grid, cx, cy int) byte {
+	width := maze.Width()
 
-	var rowFormat strings.Builder
+	// first horizontal line. We use 2 underscores since one will
+	// stay above vertical wall (East/West). The entrance cell has
+	// its north wall opened so it gets 3 spaces above.
+	if cy == 0 {
+		if cx == 0 || cx == 2*width {
+			return ' '
+		}
+		for x := (cx - 2) / 2; x <= cx/2 && x < width; x++ {
+			if x >= 0 && (maze.At(x, 0)&N) != 0 && cx >= 2*x && cx <= 2*x+2 {
+				return ' '
+			}
+		}
+		return '_'
+	}
 
-	// loop over each row
-	for y, row := range *maze {
-		// construct each line. Left is a vertical bar.
-		mazeFormat.WriteString("\n")
-		rowFormat.WriteRune('|')
+	// each row line starts with a vertical bar.
+	if cx == 0 {
+		return '|'
+	}
 
-		// loop over each cell value.
-		for x, cell := range row {
+	y := cy - 1
+	if cx%2 == 1 {
+		if (maze.At((cx-1)/2, y) & S) != 0 {
+			// south wall is opened.
+			return ' '
+		}
+		// south wall is closed.
+		return '_'
+	}
+
+	x := cx/2 - 1
+	cell := maze.At(x, y)
+	if (cell & W) == 0 {
+		// west wall is closed.
+		return '|'
+	}
+
+	// west wall is opened.
+	if x+1 < width && ((cell|maze.At(x+1, y))&S) != 0 {
+		// cell and its west neighnor have their south wall opened.
+		return ' '
+	}
+	// west neighour cell has its south wall closed.
+	return '_'
+}
+
+// parseMaze rebuilds the maze data from its ascii format. It is the
+// reverse of formatMaze and allows to recover the grid of a saved maze.
+func parseMaze(data string) (*Grid, error) {
+	lines := strings.Split(strings.TrimRight(data, "\n"), "\n")
+	// first line is the top wall. each next line is a row.
+	if len(lines) < 2 {
+		return nil, errors.New("not enough lines to parse maze")
+	}
 
-			if (cell & S) != 0 {
+	height := len(lines) - 1
+	width := (len(lines[1]) - 1) / 2
+	if width <= 0 {
+		return nil, errors.New("not enough columns to parse maze")
+	}
+
+	maze := NewGrid(width, height)
+
+	// entrance is the first cell with space above it on the top line.
+	for x := 0; x < width && 2*x+1 < len(lines[0]); x++ {
+		if lines[0][2*x+1] == ' ' {
+			maze.Open(x, 0, N)
+			break
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		line := lines[y+1]
+		if len(line) != 2*width+1 {
+			return nil, fmt.Errorf("wrong length of maze row %d", y)
+		}
+
+		for x := 0; x < width; x++ {
+			if line[2*x+1] == ' ' {
 				// south wall is opened.
-				rowFormat.WriteRune(' ')
-			} else {
-				// south wall is closed.
-				rowFormat.WriteRune('_')
+				maze.Open(x, y, S)
+				if y+1 < height {
+					maze.Open(x, y+1, N)
+				}
 			}
 
-			if (cell & W) != 0 {
+			if x+1 < width && line[2*x+2] != '|' {
 				// west wall is opened.
-				if ((cell | (*maze)[y][x+1]) & S) != 0 {
-					// cell and its west neighnor have their south wall opened.
-					rowFormat.WriteRune(' ')
-				} else {
-					// west neighour cell has its south wall closed.
-					rowFormat.WriteRune('_')
+				maze.Open(x, y, W)
+				maze.Open(x+1, y, E)
+			}
+		}
+	}
+
+	return maze, nil
+}
+
+// braidMaze removes a share <factor> (between 0 and 1) of dead ends by digging
+// one of their closed walls chosen with <r>. This creates loops so the maze
+// gets more paths.
+func braidMaze(maze *Grid, factor float64, r *rand.Rand) {
+	if factor <= 0 {
+		return
+	}
+
+	height := maze.Height()
+	width := maze.Width()
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+	var randomDirections = [4]int{N, S, E, W}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := maze.At(x, y)
+			// a dead end has a single opened wall.
+			if cell != N && cell != S && cell != E && cell != W {
+				continue
+			}
+
+			if r.Float64() >= factor {
+				continue
+			}
+
+			shuffleDirection(r, &randomDirections)
+			for _, d := range randomDirections {
+				nX, nY := moveTo(x, y, d)
+				if (cell&d) != 0 || nY < 0 || nY >= height || nX < 0 || nX >= width {
+					continue
 				}
 
-			} else {
-				// west wall is closed.
-				rowFormat.WriteRune('|')
+				maze.Open(x, y, d)
+				maze.Open(nX, nY, oppositeDirections[d])
+				break
 			}
 		}
+	}
+}
 
-		mazeFormat.WriteString(rowFormat.String())
-		rowFormat.Reset()
+// mazeDoors returns the entrance cell (north wall opened on top row) and
+// the exit cell (south wall opened on bottom row) of a maze. It defaults
+// to the center of each row when a door is not found.
+func mazeDoors(maze *Grid) ([2]int, [2]int) {
+	height := maze.Height()
+	width := maze.Width()
+	in, out := [2]int{width / 2, 0}, [2]int{width / 2, height - 1}
+
+	for x := 0; x < width; x++ {
+		if (maze.At(x, 0) & N) != 0 {
+			in[0] = x
+			break
+		}
 	}
 
-	return mazeFormat
+	for x := 0; x < width; x++ {
+		if (maze.At(x, height-1) & S) != 0 {
+			out[0] = x
+			break
+		}
+	}
 
+	return in, out
 }
@@ -0,0 +1,214 @@
+package main
+
+// This file draws mazes with the unicode box drawing characters. Each cell
+// is 3 columns wide and walls meet on corners joined by the right glyph.
+// The gui keeps 2 columns per cell and draws the walls as a lattice where
+// each line is the border below a row of cells.
+
+import (
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// boxGlyphs maps the wall segments reaching a corner to its glyph. The
+// index bits are up (1), down (2), left (4) and right (8).
+var boxGlyphs = [16]rune{
+	' ', '╵', '╷', '│', '╴', '┘', '┐', '┤',
+	'╶', '└', '┌', '├', '─', '┴', '┬', '┼',
+}
+
+// heavyBoxGlyphs is the thick variant of boxGlyphs.
+var heavyBoxGlyphs = [16]rune{
+	' ', '╹', '╻', '┃', '╸', '┛', '┓', '┫',
+	'╺', '┗', '┏', '┣', '━', '┻', '┳', '╋',
+}
+
+// doubleBoxGlyphs is the double line variant of boxGlyphs. There is no
+// half segment so lone ones are drawn as full ones.
+var doubleBoxGlyphs = [16]rune{
+	' ', '║', '║', '║', '═', '╝', '╗', '╣',
+	'═', '╚', '╔', '╠', '═', '╩', '╦', '╬',
+}
+
+// wallStyle is a set of glyphs drawing the walls on the gui. The ascii
+// style has no corners and keeps the lines of the theme.
+type wallStyle struct {
+	name       string
+	corners    *[16]rune
+	horizontal rune
+}
+
+var (
+	wallStyles = []wallStyle{
+		{"ascii", nil, 0},
+		{"box", &boxGlyphs, '─'},
+		{"heavy", &heavyBoxGlyphs, '━'},
+		{"double", &doubleBoxGlyphs, '═'},
+	}
+
+	// index of the walls style in use.
+	currentWallStyle = 0
+)
+
+// findWallStyle returns the index of the walls style named <name>.
+func findWallStyle(name string) (int, bool) {
+	for i, style := range wallStyles {
+		if style.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// wallStyleNames returns the names of the walls styles.
+func wallStyleNames() string {
+	names := make([]string, 0, len(wallStyles))
+	for _, style := range wallStyles {
+		names = append(names, style.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// styleMazeLines returns the lines displaying the maze of <lines> with
+// the walls style <style>. The corners are found from the classic ascii
+// format <ascii> of the maze: a pipe at an even column is a wall going
+// up from the corner below it and an underscore is a wall along the line.
+func styleMazeLines(lines []string, ascii string, style wallStyle) []string {
+	if style.corners == nil {
+		return lines
+	}
+
+	rows := strings.Split(ascii, "\n")
+	at := func(x, y int) byte {
+		if y < 0 || y >= len(rows) || x < 0 || x >= len(rows[y]) {
+			return ' '
+		}
+		return rows[y][x]
+	}
+
+	styled := make([]string, len(rows))
+	for y, row := range rows {
+		var b strings.Builder
+		for x := 0; x < len(row); x++ {
+			if x%2 == 1 {
+				if row[x] == '_' {
+					b.WriteRune(style.horizontal)
+				} else {
+					b.WriteRune(' ')
+				}
+				continue
+			}
+
+			glyph := 0
+			if at(x, y) == '|' {
+				glyph |= 1
+			}
+			if at(x, y+1) == '|' {
+				glyph |= 2
+			}
+			if at(x-1, y) == '_' {
+				glyph |= 4
+			}
+			if at(x+1, y) == '_' {
+				glyph |= 8
+			}
+			b.WriteRune(style.corners[glyph])
+		}
+		styled[y] = b.String()
+	}
+
+	return styled
+}
+
+// cycleWallStyle switches to the next walls style and redraws the
+// displayed maze in place.
+func (gm *Game) cycleWallStyle(g *gocui.Gui, v *gocui.View) error {
+	currentWallStyle = (currentWallStyle + 1) % len(wallStyles)
+	gm.applyWallStyle(g)
+	return nil
+}
+
+// applyWallStyle draws again the displayed maze with the walls style in use.
+func (gm *Game) applyWallStyle(g *gocui.Gui) {
+	refreshOutputsTitle(g)
+
+	mv, err := g.View(MAZE)
+	if err != nil || gm.lines == nil {
+		return
+	}
+
+	gm.display = styleMazeLines(gm.lines, gm.data.String(), wallStyles[currentWallStyle])
+	gm.drawMaze(mv)
+}
+
+// formatMazeUnicode draws the maze with unicode box drawing characters.
+func formatMazeUnicode(maze *Grid, width, height int) string {
+	// wallAbove tells if the cell (x,y) has its north wall. the row
+	// below the last one stands for the south walls of the maze.
+	wallAbove := func(x, y int) bool {
+		if y == height {
+			return (maze.At(x, height-1) & S) == 0
+		}
+		return (maze.At(x, y) & N) == 0
+	}
+
+	// wallLeft tells if the cell (x,y) has a wall on its left side
+	// which is the west wall of its left neighbor.
+	wallLeft := func(x, y int) bool {
+		if x == 0 || x == width {
+			return true
+		}
+		return (maze.At(x-1, y) & W) == 0
+	}
+
+	var b strings.Builder
+	for y := 0; y <= height; y++ {
+		// corners and horizontal walls line.
+		for x := 0; x <= width; x++ {
+			glyph := 0
+			if y > 0 && wallLeft(x, y-1) {
+				glyph |= 1
+			}
+			if y < height && wallLeft(x, y) {
+				glyph |= 2
+			}
+			if x > 0 && wallAbove(x-1, y) {
+				glyph |= 4
+			}
+			if x < width && wallAbove(x, y) {
+				glyph |= 8
+			}
+			b.WriteRune(boxGlyphs[glyph])
+
+			if x < width {
+				if wallAbove(x, y) {
+					b.WriteString("───")
+				} else {
+					b.WriteString("   ")
+				}
+			}
+		}
+
+		if y == height {
+			break
+		}
+		b.WriteString("\n")
+
+		// cells and vertical walls line.
+		for x := 0; x <= width; x++ {
+			if wallLeft(x, y) {
+				b.WriteRune('│')
+			} else {
+				b.WriteRune(' ')
+			}
+
+			if x < width {
+				b.WriteString("   ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,21 @@
+package main
+
+// This file brings the grid of the maze package into the game. The grid
+// lives there with the algorithms registries so other modules can build
+// mazes and register their own algorithms, while the game keeps naming
+// the grid without the package prefix.
+
+import (
+	"github.com/jeamon/gomazes/maze"
+)
+
+// Grid is the cells of a maze. See maze.Grid.
+type Grid = maze.Grid
+
+var (
+	// NewGrid returns a grid with all walls closed. See maze.NewGrid.
+	NewGrid = maze.NewGrid
+	// GridFromRows returns the grid of the cells given row by row.
+	// See maze.GridFromRows.
+	GridFromRows = maze.GridFromRows
+)
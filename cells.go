@@ -0,0 +1,117 @@
+package main
+
+// This file paints the cells of the maze with their own colors. The trail
+// walked during the run, the solution once revealed, the doors and the ice
+// tiles get the background of the color scheme in use through the ansi
+// sequences understood by the views.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// cellPaint holds the colors of a cell. ColorDefault keeps
+// the colors of the view.
+type cellPaint struct {
+	fg, bg gocui.Attribute
+}
+
+// escape returns the ansi sequences setting the paint. Besides
+// the colors, the bold, underline and reverse attributes are kept.
+func (p cellPaint) escape() string {
+	var b strings.Builder
+	b.WriteString(colorSequence(p.fg, 30))
+	for _, attr := range []struct {
+		attr gocui.Attribute
+		code int
+	}{{gocui.AttrBold, 1}, {gocui.AttrUnderline, 4}, {gocui.AttrReverse, 7}} {
+		if p.fg&attr.attr != 0 {
+			fmt.Fprintf(&b, "\x1b[%dm", attr.code)
+		}
+	}
+	b.WriteString(colorSequence(p.bg, 40))
+	return b.String()
+}
+
+// paintAt returns the paint of the maze view cursor position (cx, cy).
+// The doors come first, then the solution, the trail and the ice.
+func (gm *Game) paintAt(cx, cy int) cellPaint {
+	s := scheme()
+	if gm.maze != nil {
+		in, out := mazeDoors(gm.maze)
+		if (cy == 0 && cx == 2*in[0]+1) || (cy == out[1]+1 && cx == 2*out[0]+1) {
+			return cellPaint{bg: s.doors}
+		}
+	}
+
+	switch {
+	case gm.solution[[2]int{cx, cy}]:
+		return cellPaint{bg: s.solution}
+	case gm.run.trail[[2]int{cx, cy}]:
+		return cellPaint{bg: s.trail}
+	case gm.isOnIce(cx, cy):
+		return cellPaint{bg: ICE_COLOR}
+	}
+	return cellPaint{}
+}
+
+// writePainted writes <glyph> with the paint of the cursor position (cx, cy).
+func (gm *Game) writePainted(b *strings.Builder, glyph rune, cx, cy int) {
+	seq := gm.paintAt(cx, cy).escape()
+	if seq == "" {
+		b.WriteRune(glyph)
+		return
+	}
+
+	b.WriteString(seq)
+	b.WriteRune(glyph)
+	b.WriteString("\x1b[0m")
+}
+
+// markTrail adds the cursor position (cx, cy) to the trail.
+func (gm *Game) markTrail(cx, cy int) {
+	gm.run.trail[[2]int{cx, cy}] = true
+	gm.invalidateMaze([2]int{cx, cy})
+}
+
+// invalidateCells marks the painted <cells> to redraw.
+func (gm *Game) invalidateCells(cells map[[2]int]bool) {
+	for pos := range cells {
+		gm.invalidateMaze(pos)
+	}
+}
+
+// toggleSolution reveals or hides the solution of the maze. A run
+// with the solution revealed is not recorded into the best times.
+func (gm *Game) toggleSolution(g *gocui.Gui, mv *gocui.View) error {
+	if gm.maze == nil {
+		return nil
+	}
+
+	if gm.solution != nil {
+		gm.invalidateCells(gm.solution)
+		gm.solution = nil
+	} else {
+		in, out := mazeDoors(gm.maze)
+		x, _ := gm.entranceCursor(mv)
+		gm.solution = make(map[[2]int]bool)
+		for _, pos := range cursorPath(solveMaze(gm.maze, in, out), x) {
+			gm.solution[pos] = true
+		}
+		gm.invalidateCells(gm.solution)
+		gm.solutionRevealed = true
+		bellHint.ring(g)
+	}
+
+	gm.drawMaze(mv)
+	return nil
+}
+
+// clearSolution hides the solution when the maze is left.
+func (gm *Game) clearSolution() {
+	gm.invalidateCells(gm.solution)
+	gm.solution = nil
+	gm.solutionRevealed = false
+}
@@ -0,0 +1,112 @@
+package main
+
+// This file implements the checkpoint cells. They are spread along the
+// solution path and once reached, the session is saved at that position
+// so falling back (reset or reload) resumes from there, not the entrance.
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	CHECKPOINT = "checkpoint"
+	// number of checkpoints spread along the solution path.
+	CHECKPOINTS = 3
+)
+
+var isCheckpoints = false
+
+// checkpointsState holds the cursor coordinates of each checkpoint of the
+// current maze with their markers and the index of the last reached one.
+type checkpointsState struct {
+	cells   [][2]int
+	markers []*overlayMarker
+	last    int
+}
+
+// toggleCheckpoints switches the checkpoint cells on/off.
+// It applies to the next maze displayed.
+func toggleCheckpoints(g *gocui.Gui, v *gocui.View) error {
+	isCheckpoints = !isCheckpoints
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// setupCheckpoints places the checkpoints of the current maze at equal
+// distances along its solution path and draws them over the maze view.
+func (gm *Game) setupCheckpoints(g *gocui.Gui) {
+	gm.closeCheckpoints(g)
+	if !isCheckpoints || gm.maze == nil {
+		return
+	}
+
+	in, out := mazeDoors(gm.maze)
+	path := solveMaze(gm.maze, in, out)
+	if len(path) <= CHECKPOINTS+1 {
+		return
+	}
+
+	for i := 1; i <= CHECKPOINTS; i++ {
+		cell := path[i*len(path)/(CHECKPOINTS+1)]
+		cx, cy := 2*cell[0]+1, cell[1]+1
+		marker := gm.newMarker(fmt.Sprintf("%s%d", CHECKPOINT, i), '+', scheme().item)
+		gm.checkpoints.cells = append(gm.checkpoints.cells, [2]int{cx, cy})
+		gm.checkpoints.markers = append(gm.checkpoints.markers, marker)
+		_ = gm.renderer.DrawOverlay(marker, cx, cy)
+	}
+}
+
+// isCheckpoint tells whether the cursor position (cx, cy) is a checkpoint.
+func (gm *Game) isCheckpoint(cx, cy int) bool {
+	for _, cp := range gm.checkpoints.cells {
+		if cp == [2]int{cx, cy} {
+			return true
+		}
+	}
+	return false
+}
+
+// reachCheckpoint marks the checkpoint under the cursor of the maze view
+// as reached. It returns true only when that checkpoint is further than
+// the last reached one so going back never loses progress.
+func (gm *Game) reachCheckpoint(g *gocui.Gui, mv *gocui.View) bool {
+	cx, cy := gm.mazeCursor(mv)
+	for i, cp := range gm.checkpoints.cells {
+		if cp != [2]int{cx, cy} || i <= gm.checkpoints.last {
+			continue
+		}
+
+		for j := gm.checkpoints.last + 1; j <= i; j++ {
+			gm.checkpoints.markers[j].glyph = '*'
+			gm.checkpoints.markers[j].color = scheme().visited
+			_ = gm.renderer.DrawOverlay(gm.checkpoints.markers[j], gm.checkpoints.cells[j][0], gm.checkpoints.cells[j][1])
+		}
+		gm.checkpoints.last = i
+		return true
+	}
+
+	return false
+}
+
+// checkpointCursor returns the cursor position to fall back to. It is the
+// last reached checkpoint if any or the entrance of the maze.
+func (gm *Game) checkpointCursor(mv *gocui.View) (int, int) {
+	if gm.checkpoints.last < 0 || gm.checkpoints.last >= len(gm.checkpoints.cells) {
+		return gm.entranceCursor(mv)
+	}
+
+	return gm.checkpoints.cells[gm.checkpoints.last][0], gm.checkpoints.cells[gm.checkpoints.last][1]
+}
+
+// closeCheckpoints removes the checkpoints of the current maze.
+func (gm *Game) closeCheckpoints(g *gocui.Gui) {
+	for _, marker := range gm.checkpoints.markers {
+		marker.close(g)
+	}
+
+	gm.checkpoints.cells = nil
+	gm.checkpoints.markers = nil
+	gm.checkpoints.last = -1
+}
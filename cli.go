@@ -0,0 +1,752 @@
+package main
+
+// This file provides the command line interface. The game into the terminal
+// is the default command while the other ones generate, solve and export
+// mazes without any gui so the program can be used from scripts.
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// bounds of the mazes dimensions accepted from the command line.
+	CLI_MIN_SIZE = 2
+	CLI_MAX_SIZE = 2000
+)
+
+// command describes a subcommand of the program.
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+// commands of the program. play is the default one.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"play", "play the mazes into the terminal (default)", play},
+		{"gen", "generate a maze and print it", runGen},
+		{"solve", "print the solution of a maze read from a file or generated", runSolve},
+		{"validate", "check mazes read from files for problems", runValidate},
+		{"analyze", "print the structure of mazes read from a file or generated", runAnalyze},
+		{"verify", "check that a list of moves solves a maze", runVerify},
+		{"bench", "time the generation and solving of mazes", runBench},
+		{"export", "generate or convert a maze and write it as text, JSON, image or pdf sheets", runExport},
+		{"serve", "serve the maze engine over a REST api", runServe},
+		{"watch", "draw live the game streamed by another player", runWatch},
+		{"version", "print the version, the commit and the build date", runVersion},
+		{"help", "show the help of a command", runHelp},
+	}
+}
+
+// runCLI runs the command named by the first argument with the remaining
+// ones and returns the exit code. Without command name, the game is played
+// with all the arguments so the older invocations keep working.
+func runCLI(args []string) int {
+	cmd := commands[0]
+	if len(args) > 0 {
+		if c, found := findCommand(args[0]); found {
+			cmd, args = c, args[1:]
+		}
+	}
+
+	if err := cmd.run(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "gomazes %s: %v\n", cmd.name, err)
+		return 1
+	}
+
+	return 0
+}
+
+// findCommand returns the command named <name> if any.
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// commandUsage returns the usage function of the flags <fs> of a command.
+func commandUsage(fs *flag.FlagSet, synopsis, description string) func() {
+	return func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: gomazes %s\n\n%s.\n\nFlags:\n", synopsis, strings.ToUpper(description[:1])+description[1:])
+
+		// the hidden flags are left out of a copy of the flags.
+		shown := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+		shown.SetOutput(out)
+		fs.VisitAll(func(f *flag.Flag) {
+			if !hiddenFlags[f.Name] {
+				shown.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+		shown.PrintDefaults()
+	}
+}
+
+// runHelp prints the list of commands or the usage of the given one.
+func runHelp(args []string) error {
+	if len(args) > 0 {
+		if c, found := findCommand(args[0]); found && c.name != "help" {
+			return c.run([]string{"-h"})
+		}
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	fmt.Println("Usage: gomazes [command] [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, c := range commands {
+		fmt.Printf("  %-8s %s\n", c.name, c.description)
+	}
+	fmt.Println()
+	fmt.Println("Run 'gomazes help <command>' for the flags of a command.")
+	return nil
+}
+
+// mazeOptions holds the flags describing the maze to generate.
+type mazeOptions struct {
+	width, height int
+	seed          int64
+	algorithm     string
+	doors         string
+	preset        string
+	braid         float64
+	unique        bool
+	output        string
+	json          bool
+	// names of the flags given.
+	set map[string]bool
+}
+
+// register adds the maze flags to <fs>.
+func (o *mazeOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.width, "width", 0, "maze width in cells (default 15 or the preset one)")
+	fs.IntVar(&o.height, "height", 0, "maze height in cells (default 10 or the preset one)")
+	fs.Int64Var(&o.seed, "seed", 0, "seed of the maze (default random)")
+	fs.StringVar(&o.algorithm, "algorithm", "", "maze generation algorithm: "+strings.Join(generatorNames(), "/")+" (default backtracker or the preset one)")
+	fs.StringVar(&o.doors, "doors", "center", "entrance & exit placement: center, random, corners or <entrance,exit> columns")
+	fs.StringVar(&o.preset, "preset", "", "difficulty preset: "+presetNames())
+	fs.Float64Var(&o.braid, "braid", 0, "share of dead ends removed between 0 and 1 (default 0 or the preset one)")
+	fs.BoolVar(&o.unique, "unique", false, "repair the maze so a single path links the entrance to the exit")
+	fs.StringVar(&o.output, "o", "-", "output file or - for the standard output")
+	fs.BoolVar(&o.json, "json", false, "print the maze, its solution and stats as JSON")
+}
+
+// parse parses the flags <fs> and keeps the names of the ones given.
+// The maze flags do not take any argument.
+func (o *mazeOptions) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	o.set = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		o.set[f.Name] = true
+	})
+	return nil
+}
+
+// generate applies the options to the maze settings then generates the
+// maze. The size not given becomes the preset one, else the default one.
+func (o *mazeOptions) generate() (*Grid, error) {
+	width, height := 15, 10
+	if o.preset != "" {
+		p, found := findPreset(o.preset)
+		if !found {
+			return nil, fmt.Errorf("unknown preset %q. expected one of %s", o.preset, presetNames())
+		}
+		applyPreset(p)
+		width, height = p.width, p.height
+	}
+
+	if o.width <= 0 {
+		o.width = width
+	}
+
+	if o.height <= 0 {
+		o.height = height
+	}
+
+	if o.width < CLI_MIN_SIZE || o.width > CLI_MAX_SIZE || o.height < CLI_MIN_SIZE || o.height > CLI_MAX_SIZE {
+		return nil, fmt.Errorf("maze size %d x %d out of [%d, %d]", o.width, o.height, CLI_MIN_SIZE, CLI_MAX_SIZE)
+	}
+
+	if o.algorithm != "" {
+		mazeAlgorithm = o.algorithm
+	}
+
+	if o.set["braid"] {
+		mazeBraid = o.braid
+	}
+
+	doorsPlacement = o.doors
+	if !o.set["seed"] {
+		o.seed = time.Now().UnixNano()
+	}
+
+	maze, err := generateMaze(o.width, o.height, o.seed)
+	if err != nil || !o.unique {
+		return maze, err
+	}
+
+	repair := makeSolutionUnique(maze)
+	logInfo("Made the solution unique", "seed", o.seed, "solution_length", repair.length, "opened", repair.opened, "closed", repair.closed)
+	return maze, nil
+}
+
+// create opens the output file or returns the standard output.
+func (o *mazeOptions) create() (io.WriteCloser, error) {
+	if o.output == "" || o.output == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(o.output)
+}
+
+// nopCloser keeps the standard output opened once written.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// parseMazeFlags parses the maze flags of a command and generates the maze.
+func parseMazeFlags(name, synopsis, description string, args []string) (*mazeOptions, *Grid, error) {
+	opts := &mazeOptions{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, synopsis, description)
+	opts.register(fs)
+	if err := opts.parse(fs, args); err != nil {
+		return nil, nil, err
+	}
+
+	maze, err := opts.generate()
+	return opts, maze, err
+}
+
+// report returns the JSON report of a maze generated from the options.
+func (o *mazeOptions) report(maze *Grid, withSolution bool) *mazeReport {
+	seed := o.seed
+	return newMazeReport(maze, &seed, mazeAlgorithm, withSolution)
+}
+
+// writeReport writes the JSON report <v> into the output of the options.
+func writeReport(opts *mazeOptions, v interface{}) error {
+	out, err := opts.create()
+	if err != nil {
+		return err
+	}
+
+	if err = writeJSON(out, v); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// writeOutput writes <data> into the output of the options.
+func writeOutput(opts *mazeOptions, data string) error {
+	out, err := opts.create()
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.WriteString(out, data); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// runGen generates a maze and prints it with ascii or unicode
+// characters. It never starts the gui so it can be piped. With a
+// count or an output folder, a batch of mazes is written instead.
+func runGen(args []string) error {
+	opts := &mazeOptions{}
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "gen [flags]", "generate a maze and print it or a batch of mazes into a folder")
+	opts.register(fs)
+	style := fs.String("style", "ascii", "characters of the walls: ascii or unicode")
+	count := fs.Int("count", 1, "number of mazes to generate with consecutive seeds")
+	out := fs.String("out", "", "folder to write one file per maze (default current folder when count > 1)")
+	sizes := fs.String("sizes", "", "comma separated <width>x<height> sizes cycled over the batch (default width & height)")
+	if err := opts.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *style != "ascii" && *style != "unicode" {
+		return fmt.Errorf("unknown style %q", *style)
+	}
+
+	if *count > 1 || *out != "" || *sizes != "" {
+		return genBatch(opts, *style, *count, *out, *sizes)
+	}
+
+	maze, err := opts.generate()
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		return writeReport(opts, opts.report(maze, false))
+	}
+
+	return writeOutput(opts, formatStyle(maze, *style)+"\n")
+}
+
+// formatStyle formats the maze with ascii or unicode characters.
+func formatStyle(maze *Grid, style string) string {
+	width, height := maze.Width(), maze.Height()
+	if style == "unicode" {
+		return formatMazeUnicode(maze, width, height)
+	}
+
+	data := formatMaze(maze, width, height)
+	return data.String()
+}
+
+// parseSizes reads a comma separated list of <width>x<height> sizes.
+func parseSizes(list string) ([][2]int, error) {
+	var sizes [][2]int
+	for _, item := range strings.Split(list, ",") {
+		var w, h int
+		if _, err := fmt.Sscanf(strings.TrimSpace(item), "%dx%d", &w, &h); err != nil {
+			return nil, fmt.Errorf("invalid maze size %q", item)
+		}
+		sizes = append(sizes, [2]int{w, h})
+	}
+	return sizes, nil
+}
+
+// genBatch writes <count> mazes into the folder <out>. The seeds follow
+// each other from the given or a random one so a batch can be produced
+// again, and the sizes are cycled over the batch if any.
+func genBatch(opts *mazeOptions, style string, count int, out, sizes string) error {
+	if count < 1 {
+		return fmt.Errorf("invalid count %d", count)
+	}
+
+	if out == "" {
+		out = "."
+	}
+
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+
+	var cycle [][2]int
+	if sizes != "" {
+		var err error
+		if cycle, err = parseSizes(sizes); err != nil {
+			return err
+		}
+	}
+
+	base := opts.seed
+	if !opts.set["seed"] {
+		base = time.Now().UnixNano()
+	}
+	opts.set["seed"] = true
+
+	ext := ".txt"
+	if style == "unicode" {
+		ext = ".utf8.txt"
+	}
+
+	var reports []*mazeReport
+	digits := len(fmt.Sprint(count))
+	for i := 0; i < count; i++ {
+		opts.seed = base + int64(i)
+		if len(cycle) > 0 {
+			opts.width, opts.height = cycle[i%len(cycle)][0], cycle[i%len(cycle)][1]
+		}
+
+		maze, err := opts.generate()
+		if err != nil {
+			return err
+		}
+
+		name := fmt.Sprintf("maze-%0*d-%dx%d-%d%s", digits, i+1, opts.width, opts.height, opts.seed, ext)
+		path := filepath.Join(out, name)
+		if err = os.WriteFile(path, []byte(formatStyle(maze, style)+"\n"), 0644); err != nil {
+			return err
+		}
+
+		if opts.json {
+			report := opts.report(maze, false)
+			report.File = path
+			reports = append(reports, report)
+			continue
+		}
+		fmt.Println(path)
+	}
+
+	if opts.json {
+		return writeJSON(os.Stdout, reports)
+	}
+
+	return nil
+}
+
+// runSolve prints the solution of a maze read from a file or from the
+// standard input. Without input, a maze is generated from the flags.
+func runSolve(args []string) error {
+	opts := &mazeOptions{}
+	fs := flag.NewFlagSet("solve", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "solve [flags]", "print the solution of a maze read from a file or generated")
+	opts.register(fs)
+	input := fs.String("i", "", "maze file in text or JSON format or saved session to solve, - for the standard input (default a generated maze)")
+	format := fs.String("format", "maze", "solution format: maze (annotated), coords (one x,y cell per line) or moves (N/E/S/W letters)")
+	solverName := fs.String("solver", "bfs", "maze solving algorithm: "+strings.Join(solverNames(), "/"))
+	if err := opts.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *format != "maze" && *format != "coords" && *format != "moves" {
+		return fmt.Errorf("unknown solution format %q", *format)
+	}
+
+	solver, found := findSolver(*solverName)
+	if !found {
+		return fmt.Errorf("unknown maze solver %q. expected one of %s", *solverName, strings.Join(solverNames(), "/"))
+	}
+
+	var maze *Grid
+	var err error
+	if *input == "" {
+		maze, err = opts.generate()
+	} else {
+		maze, err = readMazeFile(*input)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		var report *mazeReport
+		if *input == "" {
+			report = opts.report(maze, true)
+		} else {
+			report = newMazeReport(maze, nil, "", true)
+		}
+
+		if !report.Stats.Solvable {
+			return errors.New("maze has no solution")
+		}
+		return writeReport(opts, report)
+	}
+
+	if *format == "coords" || *format == "moves" {
+		in, out := mazeDoors(maze)
+		path := solver(maze, in, out)
+		if path == nil {
+			return errors.New("maze has no solution")
+		}
+
+		if *format == "moves" {
+			return writeOutput(opts, solutionMoves(path)+"\n")
+		}
+
+		var b strings.Builder
+		for _, cell := range path {
+			fmt.Fprintf(&b, "%d,%d\n", cell[0], cell[1])
+		}
+		return writeOutput(opts, b.String())
+	}
+
+	data := formatMaze(maze, maze.Width(), maze.Height())
+	solved, err := annotateSolution(maze, data.String(), solver)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(opts, solved+"\n")
+}
+
+// readMaze reads a maze either in ascii format as printed by the gen
+// command, drawn with # blocks, in the JSON interchange format or in JSON format as an
+// object with its cells into "grid" like the reports.
+func readMaze(r io.Reader) (*Grid, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(strings.TrimSpace(text), "{") {
+		return parseTextMaze(text)
+	}
+
+	var content struct {
+		Format string  `json:"format"`
+		Grid   [][]int `json:"grid"`
+	}
+	if err = json.Unmarshal(data, &content); err != nil {
+		return nil, err
+	}
+
+	if content.Format != "" {
+		return decodeMazeDocument(data)
+	}
+
+	if len(content.Grid) == 0 || len(content.Grid[0]) == 0 {
+		return nil, errors.New("maze grid is empty")
+	}
+
+	for y, row := range content.Grid {
+		if len(row) != len(content.Grid[0]) {
+			return nil, fmt.Errorf("wrong length of maze row %d", y)
+		}
+
+		for x, cell := range row {
+			if cell < 0 || cell > N|S|E|W {
+				return nil, fmt.Errorf("wrong value of maze cell (%d,%d)", x, y)
+			}
+		}
+	}
+
+	return GridFromRows(content.Grid), nil
+}
+
+// annotateSolution marks the solution path of <maze> found by <solve>
+// with stars on its ascii format <data>.
+func annotateSolution(maze *Grid, data string, solve SolverFunc) (string, error) {
+	in, out := mazeDoors(maze)
+	path := solve(maze, in, out)
+	if path == nil {
+		return "", errors.New("maze has no solution")
+	}
+
+	lines := strings.Split(data, "\n")
+	rows := make([][]byte, len(lines))
+	for i, line := range lines {
+		rows[i] = []byte(line)
+	}
+
+	for _, step := range cursorPath(path, 2*in[0]+1)[1:] {
+		x, y := step[0], step[1]
+		if y < len(rows) && x < len(rows[y]) {
+			rows[y][x] = '*'
+		}
+	}
+
+	for i, row := range rows {
+		lines[i] = string(row)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// exportSettings holds the flags describing how a maze is exported.
+type exportSettings struct {
+	format   string
+	style    imageStyle
+	solution bool
+	// maze file read instead of generating a maze.
+	input string
+}
+
+// runExport generates or reads a maze and writes it as ascii text, JSON
+// or image. The format defaults to the extension of the output file.
+func runExport(args []string) error {
+	opts := &mazeOptions{}
+	settings := &exportSettings{style: defaultImageStyle}
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "export [flags] -o <file>", "generate a maze and write it as text, image or printable pdf sheets")
+	opts.register(fs)
+	fs.StringVar(&settings.format, "format", "", "output format: text, json, gif, png or pdf (default from the output file extension)")
+	fs.StringVar(&settings.input, "i", "", "maze file in text or JSON format or saved session to convert, - for the standard input (default a generated maze)")
+	fs.BoolVar(&settings.solution, "solution", false, "draw the solution path (text and png)")
+	fs.IntVar(&settings.style.cell, "cell", CELL_PIXELS, "size of each cell in pixels (png)")
+	count := fs.Int("count", 1, "number of mazes with following seeds (pdf)")
+	perPage := fs.Int("per-page", 1, "number of mazes on each page (pdf)")
+	title := fs.String("title", "Mazes", "title printed on top of each page (pdf)")
+	colors := []struct {
+		name, usage string
+		c           *color.RGBA
+	}{
+		{"wall-color", "color of the walls as #rrggbb (png)", &settings.style.wall},
+		{"bg-color", "color of the background as #rrggbb (png)", &settings.style.background},
+		{"path-color", "color of the solution path as #rrggbb (png)", &settings.style.path},
+	}
+	for _, col := range colors {
+		c := col.c
+		fs.Func(col.name, col.usage, func(value string) error {
+			parsed, err := parseColor(value)
+			*c = parsed
+			return err
+		})
+	}
+	if err := opts.parse(fs, args); err != nil {
+		return err
+	}
+
+	if settings.format == "" {
+		settings.format = strings.TrimPrefix(strings.ToLower(filepath.Ext(opts.output)), ".")
+		if settings.format == "" || settings.format == "txt" {
+			settings.format = "text"
+		}
+	}
+
+	if opts.json && (opts.output == "" || opts.output == "-") {
+		return errors.New("an output file is needed to print the JSON report")
+	}
+
+	if settings.format == "pdf" && settings.input == "" {
+		return exportSheets(opts, *count, *perPage, *title)
+	}
+
+	var maze *Grid
+	var err error
+	if settings.input == "" {
+		maze, err = opts.generate()
+	} else {
+		maze, err = readMazeFile(settings.input)
+	}
+	if err != nil {
+		return err
+	}
+
+	if settings.format == "pdf" {
+		err = writeSheets(opts, []*Grid{maze}, *perPage, *title)
+	} else {
+		err = exportMaze(opts, maze, settings)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.json {
+		report := newMazeReport(maze, nil, "", settings.solution)
+		if settings.input == "" {
+			report = opts.report(maze, settings.solution)
+		}
+		report.File = opts.output
+		return writeJSON(os.Stdout, report)
+	}
+
+	return nil
+}
+
+// exportMaze writes <maze> into the output of the options with the settings.
+func exportMaze(opts *mazeOptions, maze *Grid, settings *exportSettings) error {
+	switch settings.format {
+	case "text":
+		ascii := formatMaze(maze, maze.Width(), maze.Height())
+		data := ascii.String()
+		if settings.solution {
+			var err error
+			if data, err = annotateSolution(maze, data, solveMaze); err != nil {
+				return err
+			}
+		}
+		return writeOutput(opts, data+"\n")
+
+	case "json":
+		if settings.input != "" {
+			return writeReport(opts, newMazeDocument(maze, nil, ""))
+		}
+		seed := opts.seed
+		return writeReport(opts, newMazeDocument(maze, &seed, mazeAlgorithm))
+
+	case "gif", "png":
+		out, err := opts.create()
+		if err != nil {
+			return err
+		}
+
+		if settings.format == "gif" {
+			err = gif.Encode(out, renderMazeImage(maze, classicTheme), nil)
+		} else {
+			err = writeMazePNG(out, maze, settings.style, settings.solution)
+		}
+
+		if err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+
+	return fmt.Errorf("unknown export format %q", settings.format)
+}
+
+// exportSheets writes <count> mazes with following seeds as printable pdf
+// pages, <perPage> on each, followed by the pages of their answers.
+func exportSheets(opts *mazeOptions, count, perPage int, title string) error {
+	if count < 1 {
+		return fmt.Errorf("invalid count %d", count)
+	}
+
+	base := opts.seed
+	if !opts.set["seed"] {
+		base = time.Now().UnixNano()
+	}
+	opts.set["seed"] = true
+
+	var mazes []*Grid
+	var reports []*mazeReport
+	for i := 0; i < count; i++ {
+		opts.seed = base + int64(i)
+		maze, err := opts.generate()
+		if err != nil {
+			return err
+		}
+		mazes = append(mazes, maze)
+
+		if opts.json {
+			report := opts.report(maze, true)
+			report.File = opts.output
+			reports = append(reports, report)
+		}
+	}
+
+	if err := writeSheets(opts, mazes, perPage, title); err != nil {
+		return err
+	}
+
+	if opts.json {
+		return writeJSON(os.Stdout, reports)
+	}
+	return nil
+}
+
+// writeSheets writes the pdf sheets of <mazes> into the output of the options.
+func writeSheets(opts *mazeOptions, mazes []*Grid, perPage int, title string) error {
+	if perPage < 1 || perPage > PDF_MAX_PER_PAGE {
+		return fmt.Errorf("invalid number of mazes per page %d. expected [1, %d]", perPage, PDF_MAX_PER_PAGE)
+	}
+
+	out, err := opts.create()
+	if err != nil {
+		return err
+	}
+
+	if err = writeMazesPDF(out, mazes, perPage, title); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
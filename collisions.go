@@ -0,0 +1,89 @@
+package main
+
+// This file counts the attempted moves into walls. The blocked wall flashes
+// (with the bump cue of the bell) and collisions lower the score of a run.
+
+import (
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	COLLISION = "collision"
+	// how long the blocked wall stays highlighted.
+	COLLISION_FLASH = 150 * time.Millisecond
+	// extra moves charged to the score for each collision.
+	COLLISION_PENALTY = 2
+	// best score of a run.
+	MAX_SCORE = 1000
+)
+
+// bumpWall counts a move toward (dx, dy) blocked by a wall then flashes
+// that wall over the maze view and rings the bell if enabled.
+func (gm *Game) bumpWall(g *gocui.Gui, mv *gocui.View, dx, dy int) {
+	gm.run.collisions++
+	bellBump.ring(g)
+
+	cx, cy := gm.mazeCursor(mv)
+	// the south wall of a cell is drawn on the cursor line itself.
+	spots := [][2]int{{cx + dx, cy + dy}}
+	if dy > 0 {
+		spots = append([][2]int{{cx, cy}}, spots...)
+	}
+
+	for _, spot := range spots {
+		switch {
+		case sideWallAt(gm.maze, spot[0], spot[1]):
+			gm.flashWall(g, spot[0], spot[1], rune(gm.theme.vertical))
+		case southWallAt(gm.maze, spot[0], spot[1]):
+			gm.flashWall(g, spot[0], spot[1], rune(gm.theme.horizontal))
+		default:
+			continue
+		}
+		return
+	}
+}
+
+// flashWall highlights the wall character at (cx, cy) for a short while.
+func (gm *Game) flashWall(g *gocui.Gui, cx, cy int, glyph rune) {
+	marker := gm.newMarker(COLLISION, glyph, scheme().alert)
+	if err := gm.renderer.DrawOverlay(marker, cx, cy); err != nil {
+		return
+	}
+
+	gm.flashID++
+	id := gm.flashID
+	time.AfterFunc(COLLISION_FLASH, func() {
+		g.Update(func(g *gocui.Gui) error {
+			if id == gm.flashID {
+				marker.close(g)
+			}
+			return nil
+		})
+	})
+}
+
+// runScore rates a completed run out of MAX_SCORE by comparing
+// the <optimal> moves to the <moves> done plus the <collisions>.
+func runScore(moves, collisions, optimal int) int {
+	spent := moves + collisions*COLLISION_PENALTY
+	if spent <= 0 || optimal <= 0 {
+		return 0
+	}
+
+	if spent <= optimal {
+		return MAX_SCORE
+	}
+
+	return MAX_SCORE * optimal / spent
+}
+
+// closeCollisionFlash removes the highlighted wall if any.
+func (gm *Game) closeCollisionFlash(g *gocui.Gui) {
+	gm.flashID++
+	delete(markerViews, COLLISION)
+	if err := g.DeleteView(COLLISION); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete collision view", "err", err)
+	}
+}
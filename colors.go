@@ -0,0 +1,255 @@
+package main
+
+// This file provides the color schemes of the gui. A scheme gives the colors
+// of the walls, the paths, the player, the visited spots, the bottom bar and
+// the items drawn over the maze. Some schemes are built-in, including palettes
+// which do not rely on red & green and a high contrast one, and more can be
+// defined into the colors file of the config directory. The scheme can be
+// switched while playing.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// name of the file defining user color schemes into the config directory.
+const COLORS_FILE = "colors"
+
+// colorScheme holds the colors used to draw the gui.
+type colorScheme struct {
+	name string
+	// maze walls and paths (background of the maze).
+	wall gocui.Attribute
+	path gocui.Attribute
+	// player position and the spots already visited like checkpoints.
+	player  gocui.Attribute
+	visited gocui.Attribute
+	// text of the bottom bar views.
+	accent gocui.Attribute
+	// checkpoints to reach, opponent racing the player and warnings
+	// like wall bumps or the moves left.
+	item  gocui.Attribute
+	rival gocui.Attribute
+	alert gocui.Attribute
+	// backgrounds of the trail walked, the solution revealed and the doors.
+	trail    gocui.Attribute
+	solution gocui.Attribute
+	doors    gocui.Attribute
+}
+
+var (
+	// built-in color schemes. the first one is the default.
+	colorSchemes = []colorScheme{
+		{"classic", gocui.ColorYellow, gocui.ColorBlack, gocui.ColorGreen, gocui.ColorYellow | gocui.AttrBold, gocui.ColorGreen,
+			gocui.ColorGreen | gocui.AttrBold, gocui.ColorRed | gocui.AttrBold, gocui.ColorRed | gocui.AttrBold,
+			gocui.ColorBlue, gocui.ColorMagenta, gocui.ColorGreen},
+		{"solarized", gocui.ColorBlue, gocui.ColorBlack, gocui.ColorYellow, gocui.ColorCyan | gocui.AttrBold, gocui.ColorCyan,
+			gocui.ColorGreen | gocui.AttrBold, gocui.ColorRed | gocui.AttrBold, gocui.ColorRed | gocui.AttrBold,
+			gocui.ColorCyan, gocui.ColorYellow, gocui.ColorMagenta},
+		{"matrix", gocui.ColorGreen, gocui.ColorBlack, gocui.ColorWhite | gocui.AttrBold, gocui.ColorGreen | gocui.AttrBold, gocui.ColorGreen,
+			gocui.ColorWhite | gocui.AttrBold, gocui.ColorRed | gocui.AttrBold, gocui.ColorRed | gocui.AttrBold,
+			gocui.ColorBlue, gocui.ColorWhite, gocui.ColorMagenta},
+		// blue & yellow tell things apart without red or green.
+		{"colorblind", gocui.ColorBlue, gocui.ColorBlack, gocui.ColorWhite | gocui.AttrBold, gocui.ColorYellow | gocui.AttrBold, gocui.ColorCyan,
+			gocui.ColorCyan | gocui.AttrBold, gocui.ColorMagenta | gocui.AttrBold, gocui.ColorYellow | gocui.AttrReverse,
+			gocui.ColorWhite, gocui.ColorYellow, gocui.ColorCyan},
+		// bright walls and reversed items for low vision.
+		{"contrast", gocui.ColorWhite | gocui.AttrBold, gocui.ColorBlack, gocui.ColorYellow | gocui.AttrBold, gocui.ColorCyan | gocui.AttrBold, gocui.ColorWhite | gocui.AttrBold,
+			gocui.ColorYellow | gocui.AttrBold | gocui.AttrReverse, gocui.ColorMagenta | gocui.AttrBold | gocui.AttrReverse, gocui.ColorWhite | gocui.AttrBold | gocui.AttrReverse,
+			gocui.ColorBlue, gocui.ColorMagenta, gocui.ColorRed},
+	}
+
+	// index of the color scheme in use.
+	currentColorScheme = 0
+
+	// names of the colors accepted into the colors file.
+	colorNames = map[string]gocui.Attribute{
+		"default": gocui.ColorDefault,
+		"black":   gocui.ColorBlack,
+		"red":     gocui.ColorRed,
+		"green":   gocui.ColorGreen,
+		"yellow":  gocui.ColorYellow,
+		"blue":    gocui.ColorBlue,
+		"magenta": gocui.ColorMagenta,
+		"cyan":    gocui.ColorCyan,
+		"white":   gocui.ColorWhite,
+	}
+
+	// names of the attributes which may be added to a color with a plus.
+	attributeNames = map[string]gocui.Attribute{
+		"bold":      gocui.AttrBold,
+		"underline": gocui.AttrUnderline,
+		"reverse":   gocui.AttrReverse,
+	}
+)
+
+// scheme returns the color scheme in use.
+func scheme() colorScheme {
+	return colorSchemes[currentColorScheme]
+}
+
+// findColorScheme returns the index of the color scheme named <name>.
+func findColorScheme(name string) (int, bool) {
+	for i, s := range colorSchemes {
+		if s.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// colorSchemeNames returns the names of the color schemes.
+func colorSchemeNames() string {
+	names := make([]string, 0, len(colorSchemes))
+	for _, s := range colorSchemes {
+		names = append(names, s.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseSchemeColor reads a color like "cyan", "red+bold" or "#ff8800".
+func parseSchemeColor(value string) (gocui.Attribute, error) {
+	parts := strings.Split(strings.ToLower(value), "+")
+	color, found := colorNames[parts[0]]
+	if !found && len(parts[0]) == 7 && strings.HasPrefix(parts[0], "#") {
+		color = gocui.GetColor(parts[0])
+		found = color != gocui.ColorDefault
+	}
+	if !found {
+		return 0, fmt.Errorf("unknown color %q", parts[0])
+	}
+
+	for _, part := range parts[1:] {
+		attr, found := attributeNames[part]
+		if !found {
+			return 0, fmt.Errorf("unknown color attribute %q", part)
+		}
+		color |= attr
+	}
+
+	return color, nil
+}
+
+// parseColorScheme reads a line of the colors file. It is made of the name
+// then the colors of the walls, paths, player, visited spots and accents.
+// The colors of the items, rivals and alerts then the backgrounds of the
+// trail, solution and doors may follow, else the classic ones are used.
+func parseColorScheme(line string) (colorScheme, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 6 && len(fields) != 9 && len(fields) != 12 {
+		return colorScheme{}, fmt.Errorf("expected 6, 9 or 12 fields but got %d", len(fields))
+	}
+
+	classic := colorSchemes[0]
+	colors := [11]gocui.Attribute{5: classic.item, 6: classic.rival, 7: classic.alert, 8: classic.trail, 9: classic.solution, 10: classic.doors}
+	for i, field := range fields[1:] {
+		color, err := parseSchemeColor(field)
+		if err != nil {
+			return colorScheme{}, err
+		}
+		colors[i] = color
+	}
+
+	return colorScheme{fields[0], colors[0], colors[1], colors[2], colors[3], colors[4], colors[5], colors[6], colors[7], colors[8], colors[9], colors[10]}, nil
+}
+
+// loadColorSchemes adds the schemes of the colors file to the built-in ones.
+// A scheme with the name of an existing one replaces it. Lines starting with
+// # are comments and wrong lines are skipped.
+func loadColorSchemes() error {
+	file, err := os.Open(configPath(COLORS_FILE))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		s, err := parseColorScheme(line)
+		if err != nil {
+			logWarn("Skipped line of colors file", "line", n, "err", err)
+			continue
+		}
+
+		if i, found := findColorScheme(s.name); found {
+			colorSchemes[i] = s
+		} else {
+			colorSchemes = append(colorSchemes, s)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// wallColor returns the color of the maze walls. Decorative walls
+// themes keep their own color.
+func (gm *Game) wallColor() gocui.Attribute {
+	if gm.theme.name != classicTheme.name {
+		return gm.theme.color
+	}
+	return scheme().wall
+}
+
+// applyColorScheme paints the displayed views with the scheme in use.
+func (gm *Game) applyColorScheme(g *gocui.Gui) {
+	s := scheme()
+	for _, name := range []string{TIMER, SIZE, SEED} {
+		if v, err := g.View(name); err == nil {
+			v.FgColor = s.accent
+		}
+	}
+
+	if v, err := g.View(POSITION); err == nil {
+		v.FgColor = s.player
+	}
+
+	for _, name := range []string{STATUS, MOVES} {
+		if v, err := g.View(name); err == nil {
+			v.FgColor = s.alert
+		}
+	}
+
+	for _, name := range []string{MAZE, ZOOM} {
+		if v, err := g.View(name); err == nil {
+			v.FgColor = gm.wallColor()
+			v.BgColor = s.path
+			v.SelBgColor = s.path
+		}
+	}
+
+	for j, marker := range gm.checkpoints.markers {
+		if j <= gm.checkpoints.last {
+			marker.color = s.visited
+		} else {
+			marker.color = s.item
+		}
+	}
+	gm.opponent.color = s.rival
+	gm.player.color = s.player
+	redrawMarkers(g)
+
+	// the painted cells take the new colors.
+	if mv, err := g.View(MAZE); err == nil && gm.lines != nil {
+		gm.invalidateMaze()
+		gm.drawMaze(mv)
+	}
+}
+
+// cycleColorScheme switches to the next color scheme.
+func (gm *Game) cycleColorScheme(g *gocui.Gui, v *gocui.View) error {
+	currentColorScheme = (currentColorScheme + 1) % len(colorSchemes)
+	refreshOutputsTitle(g)
+	gm.applyColorScheme(g)
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+// This file provides the confirm overlay. It asks a yes/no question at the
+// center of the screen and hands the answer over to a callback.
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const CONFIRM = "confirm"
+
+// askConfirm displays <question> and waits for the Y or N key to call
+// onAnswer. Escape and Ctrl+Q cancel the question and call onCancel if
+// not nil. In all cases, the focus goes back to the view named <back>.
+func askConfirm(g *gocui.Gui, question, back string, onAnswer func(g *gocui.Gui, yes bool) error, onCancel func(g *gocui.Gui) error) error {
+	maxX, maxY := g.Size()
+	text := " " + question + " [y/n] "
+	width := textWidth(text) + 1
+
+	confirmView, err := setView(g, CONFIRM, (maxX-width)/2, maxY/2-1, (maxX+width)/2, maxY/2+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display confirm view", "err", err)
+		return err
+	}
+
+	confirmView.Title = tr(" Confirm ")
+	confirmView.Frame = true
+	confirmView.FgColor = gocui.ColorYellow | gocui.AttrBold
+	confirmView.Editable = false
+	clearView(confirmView)
+	fmt.Fprint(confirmView, text)
+
+	if _, err = g.SetCurrentView(CONFIRM); err != nil {
+		logError("Failed to set focus on confirm view", "err", err)
+		return err
+	}
+	_, _ = g.SetViewOnTop(CONFIRM)
+	g.Cursor = false
+
+	answer := func(yes bool) func(g *gocui.Gui, v *gocui.View) error {
+		return func(g *gocui.Gui, v *gocui.View) error {
+			closeConfirmView(g, back)
+			return onAnswer(g, yes)
+		}
+	}
+
+	cancel := func(g *gocui.Gui, v *gocui.View) error {
+		closeConfirmView(g, back)
+		if onCancel != nil {
+			return onCancel(g)
+		}
+		return nil
+	}
+
+	bindings := map[interface{}]func(g *gocui.Gui, v *gocui.View) error{
+		'Y': answer(true), 'y': answer(true),
+		'N': answer(false), 'n': answer(false),
+		gocui.KeyEsc: cancel, gocui.KeyCtrlQ: cancel,
+	}
+	for key, handler := range bindings {
+		if err = g.SetKeybinding(CONFIRM, key, gocui.ModNone, handler); err != nil {
+			logError("Failed to bind key to confirm view", "key", key, "err", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeConfirmView removes the confirm view and moves
+// the focus back to the view named <back> if it exists.
+func closeConfirmView(g *gocui.Gui, back string) {
+	g.DeleteKeybindings(CONFIRM)
+	if err := g.DeleteView(CONFIRM); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete confirm view", "err", err)
+	}
+
+	if _, err := g.SetCurrentView(back); err != nil {
+		logError("Failed to set back focus", "view", back, "err", err)
+	}
+}
+
+// isConfirming tells if a question is being asked.
+func isConfirming(g *gocui.Gui) bool {
+	_, err := g.View(CONFIRM)
+	return err == nil
+}
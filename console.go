@@ -0,0 +1,50 @@
+package main
+
+// This file adapts the game to the console it runs into. The consoles of
+// some platforms keep a few Ctrl combinations for themselves, so the actions
+// bound to them by default get fallback keys (see console_windows.go).
+
+// title of the console while playing.
+const CONSOLE_TITLE = "[ GoMazes By Jerome Amon ]"
+
+// isInterceptedKey tells if the console catches the key <k> before the game.
+func isInterceptedKey(k interface{}) bool {
+	_, found := interceptedKeys[k]
+	return found
+}
+
+// consoleKeys returns <keys> with the keys caught by the console replaced
+// by their fallback keys, or left out when there is none.
+func consoleKeys(keys []interface{}) []interface{} {
+	kept := make([]interface{}, 0, len(keys))
+	add := func(k interface{}) {
+		for _, other := range kept {
+			if other == k {
+				return
+			}
+		}
+		kept = append(kept, k)
+	}
+
+	for _, k := range keys {
+		if fallback, found := interceptedKeys[k]; !found {
+			add(k)
+		} else if fallback != nil {
+			add(fallback)
+		}
+	}
+	return kept
+}
+
+// checkConsoleKeys warns about the keys of the keys file that the
+// console catches so the actions bound to them only would not respond.
+func checkConsoleKeys() {
+	for _, a := range keyActions {
+		for _, k := range a.keys {
+			if isInterceptedKey(k) {
+				logWarn("Key caught by the console", "action", a.id(), "key", keyName(k))
+				notify("%s is caught by the console. Rebind %s", keyName(k), a.id())
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+// interceptedKeys are the keys caught by the console with their fallback
+// keys. The terminals of the unix-like platforms pass all the keys.
+var interceptedKeys = map[interface{}]interface{}{}
+
+// setupConsole prepares the console for the program and returns the
+// function restoring it. The terminals of the unix-like platforms
+// already handle the ansi sequences and utf-8.
+func setupConsole() func() {
+	return func() {}
+}
+
+// setConsoleTitle sets the title of the console. The title of the
+// terminals of the unix-like platforms is left to the shell.
+func setConsoleTitle(title string) {}
@@ -0,0 +1,72 @@
+//go:build windows
+// +build windows
+
+package main
+
+// This file drives the Windows console through its api rather than through
+// commands. The console gets the title of the game, the ansi sequences used
+// by the commands like watch and the utf-8 output of the unicode walls and
+// the emoji. The keys kept by the consoles and the terminals get fallbacks.
+
+import (
+	"os"
+	"unsafe"
+
+	"github.com/awesome-gocui/gocui"
+	"golang.org/x/sys/windows"
+)
+
+// code page of the utf-8 output.
+const CONSOLE_UTF8 = 65001
+
+// interceptedKeys are the keys caught by the console with their fallback
+// keys. Ctrl+H comes as Backspace, Ctrl+A selects all and Ctrl+F finds into
+// the classic console while Windows Terminal pastes with Ctrl+V.
+var interceptedKeys = map[interface{}]interface{}{
+	gocui.KeyCtrlH: nil,
+	gocui.KeyCtrlA: gocui.KeyF6,
+	gocui.KeyCtrlF: gocui.KeyF5,
+	gocui.KeyCtrlV: gocui.KeyF7,
+}
+
+var procSetConsoleTitle = windows.NewLazySystemDLL("kernel32.dll").NewProc("SetConsoleTitleW")
+
+// setupConsole turns on the ansi sequences and the utf-8 output of the
+// console and returns the function restoring its previous settings. The
+// output redirected to a file or a pipe is left as is.
+func setupConsole() func() {
+	out := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(out, &mode); err != nil {
+		return func() {}
+	}
+
+	if err := windows.SetConsoleMode(out, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		logDebug("Failed to enable the console ansi sequences", "err", err)
+	}
+
+	cp, err := windows.GetConsoleOutputCP()
+	if err == nil && cp != CONSOLE_UTF8 {
+		if err = windows.SetConsoleOutputCP(CONSOLE_UTF8); err != nil {
+			logDebug("Failed to set the console output to utf-8", "err", err)
+		}
+	}
+
+	return func() {
+		_ = windows.SetConsoleMode(out, mode)
+		if cp != 0 && cp != CONSOLE_UTF8 {
+			_ = windows.SetConsoleOutputCP(cp)
+		}
+	}
+}
+
+// setConsoleTitle sets the title of the console.
+func setConsoleTitle(title string) {
+	p, err := windows.UTF16PtrFromString(title)
+	if err != nil {
+		return
+	}
+	if r, _, err := procSetConsoleTitle.Call(uintptr(unsafe.Pointer(p))); r == 0 {
+		logDebug("Failed to set the console title", "err", err)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+// This file handles the generation of the daily maze. Its seed is derived from
+// the calendar date so every player gets the exact same maze on the same day.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	// fixed size of the daily maze.
+	DAILY_WIDTH  = 30
+	DAILY_HEIGHT = 15
+
+	// accepted difficulty band of the daily maze.
+	DAILY_MIN_DIFFICULTY = 0.18
+	DAILY_MAX_DIFFICULTY = 0.45
+	// maximum number of seeds tried before keeping the last one.
+	DAILY_MAX_ATTEMPTS = 50
+
+	DAILY         = "daily"
+	DWIDTH        = 36
+	DAILY_RECORDS = "dailyrecords"
+)
+
+// dailySeed returns the base seed of a given day. The date is
+// taken in UTC to get the same value around the world.
+func dailySeed(day time.Time) int64 {
+	y, m, d := day.UTC().Date()
+	return int64(y*10000 + int(m)*100 + d)
+}
+
+// nextDailySeed re-rolls a seed deterministically with the linear
+// congruential step of PCG. Its period covers all the int64 values so the
+// rerolls of a day never repeat, but nothing keeps them off the base seed
+// of another day. Such an overlap would only give two days the same maze,
+// and is unlikely within DAILY_MAX_ATTEMPTS steps.
+func nextDailySeed(seed int64) int64 {
+	return seed*6364136223846793005 + 1442695040888963407
+}
+
+// createDailyMaze generates the maze of a given day without displaying it.
+// Each candidate is solved and rejected when its difficulty falls outside
+// [DAILY_MIN_DIFFICULTY, DAILY_MAX_DIFFICULTY]. It returns the maze and
+// the seed which was finally kept.
+func createDailyMaze(day time.Time) (*Grid, int64) {
+	seed := dailySeed(day)
+	var maze *Grid
+
+	for attempt := 1; attempt <= DAILY_MAX_ATTEMPTS; attempt++ {
+		maze = createMaze(DAILY_WIDTH, DAILY_HEIGHT, seededRand(seed), DAILY_WIDTH/2, DAILY_WIDTH/2)
+		difficulty := mazeDifficulty(maze, DAILY_WIDTH, DAILY_HEIGHT)
+		if difficulty >= DAILY_MIN_DIFFICULTY && difficulty <= DAILY_MAX_DIFFICULTY {
+			return maze, seed
+		}
+
+		logDebug("Rejected daily maze seed", "seed", seed, "attempt", attempt,
+			"difficulty", fmt.Sprintf("%.2f", difficulty), "band", fmt.Sprintf("[%.2f, %.2f]", DAILY_MIN_DIFFICULTY, DAILY_MAX_DIFFICULTY))
+		if attempt == DAILY_MAX_ATTEMPTS {
+			break
+		}
+		seed = nextDailySeed(seed)
+	}
+
+	logWarn("No daily maze within difficulty band", "attempts", DAILY_MAX_ATTEMPTS, "seed", seed)
+	return maze, seed
+}
+
+// displayDailyMaze generates and displays the maze of the current day.
+func (gm *Game) displayDailyMaze(g *gocui.Gui, v *gocui.View) error {
+	xLines, yLines := v.Size()
+	if !givenMazePlays(DAILY_WIDTH, DAILY_HEIGHT, xLines, yLines) {
+		err := errTooSmallFor(DAILY_WIDTH, DAILY_HEIGHT)
+		logWarn("Cannot display daily maze", "err", err)
+		return showErrorDialog(g, tr("The daily maze cannot be displayed."), err, v.Name(), retryAction(func(g *gocui.Gui) error {
+			return gm.displayDailyMaze(g, v)
+		}))
+	}
+
+	day := time.Now().UTC()
+	maze, seed := createDailyMaze(day)
+
+	gm.setMaze(maze)
+	gm.lastSave = time.Time{}
+	gm.seed = seed
+	gm.displayMazeSize(g)
+
+	clearView(v)
+
+	if err := gm.createMazeView(g, v); err != nil {
+		logError("Failed to create & display daily maze", "err", err)
+		return err
+	}
+	countGeneratedMaze()
+
+	gm.isDaily = true
+	gm.dailyDate = day.Format("2006-01-02")
+	gm.id = "daily " + gm.dailyDate
+	logInfo("Displayed daily maze", "date", gm.dailyDate, "seed", seed)
+
+	if err := gm.displayDailyView(g, ""); err != nil {
+		logError("Failed to display daily view", "err", err)
+	}
+
+	// reset and start timer.
+	events.publish(gameEvent{kind: EVENT_START, maze: gm.maze})
+	return nil
+}
+
+// displayDailyView shows the date of the daily maze and its best time
+// on top left of the outputs view. Any <note> is appended to it.
+func (gm *Game) displayDailyView(g *gocui.Gui, note string) error {
+	dailyView, err := setView(g, DAILY, 1, 0, DWIDTH+1, 2)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+
+	dailyView.Frame = true
+	dailyView.FgColor = gocui.ColorCyan
+	dailyView.Editable = false
+	dailyView.Wrap = false
+	_, _ = g.SetViewOnTop(DAILY)
+
+	best := "--:--:--"
+	records, err := loadDailyRecords()
+	if err != nil {
+		logError("Failed to load daily records", "err", err)
+	} else if secs, found := records[gm.dailyDate]; found {
+		best = formatSeconds(secs)
+	}
+
+	clearView(dailyView)
+	fmt.Fprint(dailyView, center(trf("%s BEST %s %s", gm.dailyDate, best, tr(note)), DWIDTH-1, " "))
+	return nil
+}
+
+// closeDailyView removes the daily view and leaves the daily mode.
+func (gm *Game) closeDailyView(g *gocui.Gui) {
+	gm.isDaily = false
+	if err := g.DeleteView(DAILY); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete daily view", "err", err)
+	}
+}
+
+// formatSeconds formats a duration in seconds as hh:mm:ss.
+func formatSeconds(secs int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, (secs/60)%60, secs%60)
+}
+
+// loadDailyRecords reads the best time in seconds of each day.
+// Each line of the records file is made of <date> <seconds>.
+func loadDailyRecords() (map[string]int64, error) {
+	records := make(map[string]int64)
+
+	file, err := os.Open(dataPath(DAILY_RECORDS))
+	if os.IsNotExist(err) {
+		return records, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		secs, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		records[fields[0]] = secs
+	}
+
+	return records, scanner.Err()
+}
+
+// saveDailyRecords writes the best time of each day into records file.
+func saveDailyRecords(records map[string]int64) error {
+	file, err := os.Create(dataPath(DAILY_RECORDS))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	days := make([]string, 0, len(records))
+	for day := range records {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	for _, day := range days {
+		if _, err = fmt.Fprintln(file, day, records[day]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordDailyTime keeps the elapsed time of a completed daily maze
+// when there is no record yet for that day or when it is faster.
+func (gm *Game) recordDailyTime(g *gocui.Gui) {
+	if !gm.isDaily {
+		return
+	}
+
+	secs := gm.clock.Seconds()
+	records, err := loadDailyRecords()
+	if err != nil {
+		logError("Failed to load daily records", "err", err)
+		return
+	}
+
+	if best, found := records[gm.dailyDate]; found && best <= secs {
+		_ = gm.displayDailyView(g, "")
+		return
+	}
+
+	records[gm.dailyDate] = secs
+	if err = saveDailyRecords(records); err != nil {
+		logError("Failed to save daily records", "err", err)
+		return
+	}
+
+	_ = gm.displayDailyView(g, "NEW!")
+}
@@ -0,0 +1,122 @@
+package main
+
+// This file provides the difficulty presets. Each preset bundles the maze size
+// and the rules applied to new mazes: algorithm, braiding, fog and time limit.
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// difficultyPreset describes a named set of maze settings.
+type difficultyPreset struct {
+	name          string
+	width, height int
+	algorithm     string
+	// share of dead ends removed to create loops.
+	braid float64
+	// visibility radius in cells around the player. 0 means no fog.
+	fog int
+	// seconds allowed to reach the exit. 0 means no limit.
+	timeLimit int64
+}
+
+var (
+	difficultyPresets = []difficultyPreset{
+		{"easy", 15, 10, "backtracker", 0.5, 0, 0},
+		{"normal", 25, 15, "backtracker", 0.2, 0, 0},
+		{"hard", 40, 20, "backtracker", 0, 6, 300},
+		{"insane", 60, 28, "backtracker", 0, 3, 240},
+	}
+
+	// rules applied to next generated mazes. empty preset means custom.
+	currentPreset = ""
+	mazeAlgorithm = "backtracker"
+	mazeBraid     float64
+	mazeFog       int
+	mazeTimeLimit int64
+)
+
+// findPreset returns the preset named <name> if any.
+func findPreset(name string) (difficultyPreset, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range difficultyPresets {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return difficultyPreset{}, false
+}
+
+// applyPreset sets the rules of the next mazes from a preset. Its
+// size is left to the caller.
+func applyPreset(p difficultyPreset) {
+	currentPreset = p.name
+	mazeAlgorithm = p.algorithm
+	mazeBraid = p.braid
+	mazeFog = p.fog
+	mazeTimeLimit = p.timeLimit
+}
+
+// clearPreset goes back to custom settings with default rules.
+func clearPreset() {
+	currentPreset = ""
+	mazeAlgorithm = "backtracker"
+	mazeBraid = 0
+	mazeFog = 0
+	mazeTimeLimit = 0
+}
+
+// presetNames returns the list of presets names separated by slash.
+func presetNames() string {
+	names := make([]string, 0, len(difficultyPresets))
+	for _, p := range difficultyPresets {
+		names = append(names, p.name)
+	}
+	return strings.Join(names, "/")
+}
+
+// generateMaze creates a maze with the current algorithm then braids it.
+func generateMaze(width, height int, seed int64) (*Grid, error) {
+	return generateMazeWith(width, height, seed, mazeAlgorithm, doorsPlacement, mazeBraid)
+}
+
+// generateMazeWith creates a maze with the generator named <algorithm> and
+// the doors <placement> then braids it by <braid>. It does not read the
+// current settings so it may run from several goroutines at once.
+func generateMazeWith(width, height int, seed int64, algorithm, placement string, braid float64) (*Grid, error) {
+	defer traceRegion("generateMaze")()
+
+	generator, found := findGenerator(algorithm)
+	if !found {
+		return nil, fmt.Errorf("%w: unknown maze algorithm %q", ErrGenerationFailed, algorithm)
+	}
+
+	inX, outX, err := resolveDoors(placement, width, seededRand(seed))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGenerationFailed, err)
+	}
+
+	maze := generator(width, height, seededRand(seed), inX, outX)
+	braidMaze(maze, braid, seededRand(seed))
+
+	// the generators are checked independently of their own logic.
+	if logEnabled(LEVEL_DEBUG) {
+		checkGeneratedMaze(maze, algorithm, seed, braid > 0)
+	}
+	return maze, nil
+}
+
+// activateRules applies the current fog and time limit to the next
+// displayed maze. It is reset when the maze view gets closed.
+func (gm *Game) activateRules() {
+	gm.fog = mazeFog
+	atomic.StoreInt64(&gm.timeLimit, mazeTimeLimit)
+}
+
+// deactivateRules removes fog and time limit.
+func (gm *Game) deactivateRules() {
+	gm.fog = 0
+	atomic.StoreInt64(&gm.timeLimit, 0)
+}
@@ -0,0 +1,106 @@
+package main
+
+// This file resolves the per-user directories where the game keeps its files
+// instead of the current working directory. Saves and records go into the
+// data directory, logs into the cache directory and settings into the config
+// directory. A single base directory may be given to override all of them.
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const APP_NAME = "gomazes"
+
+var (
+	// resolved directories. they default to the current
+	// working directory until setupDirs is called.
+	dataDir   = "."
+	configDir = "."
+	cacheDir  = "."
+)
+
+// userDataDir returns the per-user data directory of the platform. It
+// follows XDG_DATA_HOME on unix-like systems and AppData on windows.
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin", "ios", "plan9":
+		return os.UserConfigDir()
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// setupDirs resolves and creates the data, config and cache directories.
+// When <override> is not empty, it is used for all of them.
+func setupDirs(override string) error {
+	if override != "" {
+		dataDir, configDir, cacheDir = override, override, override
+		return os.MkdirAll(override, 0755)
+	}
+
+	data, err := userDataDir()
+	if err != nil {
+		return err
+	}
+
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+
+	dataDir = filepath.Join(data, APP_NAME)
+	configDir = filepath.Join(config, APP_NAME)
+	cacheDir = filepath.Join(cache, APP_NAME)
+	for _, dir := range []string{dataDir, configDir, cacheDir} {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dataPath returns the path of the file <name> into the data directory.
+func dataPath(name string) string {
+	return filepath.Join(dataDir, name)
+}
+
+// configPath returns the path of the file <name> into the config directory.
+func configPath(name string) string {
+	return filepath.Join(configDir, name)
+}
+
+// cachePath returns the path of the file <name> into the cache directory.
+func cachePath(name string) string {
+	return filepath.Join(cacheDir, name)
+}
+
+// expandHome replaces the leading ~ of <path> by the home directory
+// since the paths read from the config file are not given by a shell.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,172 @@
+package main
+
+// This file lets the players of a local network find the streamed games
+// without typing addresses. A game started with -spectate on an address
+// reachable from the network broadcasts a small UDP announce every few
+// seconds, and the watch command without address listens to them, lists
+// the games found and connects to the one picked.
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// UDP port of the announces.
+	DISCOVERY_PORT = 47777
+	// period of the announces and time spent listening to them.
+	DISCOVERY_PERIOD = 2 * time.Second
+	DISCOVERY_WAIT   = 5 * time.Second
+	// tag of the announces telling them apart from other traffic.
+	DISCOVERY_APP = "gomazes"
+)
+
+// lanAnnounce is the content of an announce. The address of the game
+// is the source address of the datagram with the port announced.
+type lanAnnounce struct {
+	App     string `json:"app"`
+	Version string `json:"version"`
+	Name    string `json:"name"`
+	Port    int    `json:"port"`
+}
+
+// lanGame is a streamed game found on the local network.
+type lanGame struct {
+	name    string
+	addr    string
+	version string
+}
+
+// startAnnouncing broadcasts the game streamed on the TCP <port> until the
+// returned function is called.
+func startAnnouncing(port int) (func(), error) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: net.IPv4bcast, Port: DISCOVERY_PORT})
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := os.Hostname()
+	if user := os.Getenv("USER"); user != "" {
+		name = user + "@" + name
+	}
+	data, _ := json.Marshal(lanAnnounce{App: DISCOVERY_APP, Version: appVersion(), Name: name, Port: port})
+
+	done := make(chan struct{})
+	go func() {
+		defer conn.Close()
+		ticker := time.NewTicker(DISCOVERY_PERIOD)
+		defer ticker.Stop()
+		for {
+			if _, err := conn.Write(data); err != nil {
+				logWarn("Failed to announce the game on the network", "err", err)
+			}
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	logInfo("Announcing the game on the network", "port", port, "name", name)
+	return func() { close(done) }, nil
+}
+
+// discoverGames listens to the announces for <wait> and returns the games
+// found sorted by name.
+func discoverGames(wait time.Duration) ([]lanGame, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: DISCOVERY_PORT})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	found := make(map[string]lanGame)
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(wait))
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			return nil, err
+		}
+
+		a := lanAnnounce{}
+		if json.Unmarshal(buf[:n], &a) != nil || a.App != DISCOVERY_APP || a.Port <= 0 || a.Port > 65535 {
+			continue
+		}
+		addr := net.JoinHostPort(from.IP.String(), strconv.Itoa(a.Port))
+		found[addr] = lanGame{name: a.Name, addr: addr, version: a.Version}
+	}
+
+	games := make([]lanGame, 0, len(found))
+	for _, game := range found {
+		games = append(games, game)
+	}
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].name != games[j].name {
+			return games[i].name < games[j].name
+		}
+		return games[i].addr < games[j].addr
+	})
+	return games, nil
+}
+
+// pickGame discovers the games of the network and returns the address of
+// the one picked from the standard input, or of the only one found.
+func pickGame() (string, error) {
+	fmt.Printf("Looking for games on the local network for %s...\n", DISCOVERY_WAIT)
+	games, err := discoverGames(DISCOVERY_WAIT)
+	if err != nil {
+		return "", fmt.Errorf("cannot listen to the announces: %w", err)
+	}
+
+	switch len(games) {
+	case 0:
+		return "", errors.New("no game found on the local network. give its address instead")
+	case 1:
+		fmt.Printf("Found %s at %s\n", games[0].name, games[0].addr)
+		return games[0].addr, nil
+	}
+
+	for i, g := range games {
+		fmt.Printf("  %d. %s at %s (gomazes %s)\n", i+1, g.name, g.addr, g.version)
+	}
+	fmt.Print("Game to watch: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	i, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || i < 1 || i > len(games) {
+		return "", fmt.Errorf("invalid choice %q", strings.TrimSpace(line))
+	}
+	return games[i-1].addr, nil
+}
+
+// isLoopback tells if the listen address <addr> like ":7777" is
+// only reachable from the local machine.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,81 @@
+package main
+
+// This file handles the placement of the maze entrance on the top row and of
+// its exit on the bottom row: centered, random, at opposite corners or at
+// explicit columns given as <entrance,exit>.
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+var (
+	// placements switched from the gui.
+	doorsPlacements = []string{"center", "random", "corners"}
+	// current placement which may be explicit columns.
+	doorsPlacement = "center"
+)
+
+// resolveDoors returns the entrance and exit columns of a maze of <width>
+// cells for a placement. Random placement is drawn from <r>.
+func resolveDoors(placement string, width int, r *rand.Rand) (int, int, error) {
+	switch placement {
+	case "center":
+		return width / 2, width / 2, nil
+	case "corners":
+		return 0, width - 1, nil
+	case "random":
+		return r.Intn(width), r.Intn(width), nil
+	}
+
+	columns := strings.Split(placement, ",")
+	if len(columns) != 2 {
+		return 0, 0, fmt.Errorf("invalid doors placement %q", placement)
+	}
+
+	inX, err := strconv.Atoi(strings.TrimSpace(columns[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid entrance column %q", columns[0])
+	}
+
+	outX, err := strconv.Atoi(strings.TrimSpace(columns[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid exit column %q", columns[1])
+	}
+
+	// keep the columns into the maze.
+	return clamp(inX, 0, width-1), clamp(outX, 0, width-1), nil
+}
+
+// clamp limits n to the range [low, high].
+func clamp(n, low, high int) int {
+	if n < low {
+		return low
+	}
+
+	if n > high {
+		return high
+	}
+
+	return n
+}
+
+// cycleDoorsPlacement switches to the next doors placement.
+// It applies to the next maze generated.
+func cycleDoorsPlacement(g *gocui.Gui, v *gocui.View) error {
+	next := 0
+	for i, placement := range doorsPlacements {
+		if placement == doorsPlacement {
+			next = (i + 1) % len(doorsPlacements)
+			break
+		}
+	}
+
+	doorsPlacement = doorsPlacements[next]
+	refreshOutputsTitle(g)
+	return nil
+}
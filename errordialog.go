@@ -0,0 +1,150 @@
+package main
+
+// This file provides the error dialog. When loading, saving or generating
+// a maze fails, it describes the problem at the center of the screen and
+// offers the actions to recover from it like retrying or quitting.
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const ERROR_DIALOG = "errordialog"
+
+// errorAction is an action offered by the error dialog.
+type errorAction struct {
+	key   rune
+	label string
+	run   func(g *gocui.Gui) error
+}
+
+// retryAction offers to run again the failed operation.
+func retryAction(run func(g *gocui.Gui) error) errorAction {
+	return errorAction{'R', "Retry", run}
+}
+
+// newMazeAction offers to play a new maze instead.
+func (gm *Game) newMazeAction() errorAction {
+	return errorAction{'N', "New maze", func(g *gocui.Gui) error {
+		if err := setFocusOnView(g, OUTPUTS); err != nil {
+			return err
+		}
+
+		ov, err := g.View(OUTPUTS)
+		if err != nil {
+			return err
+		}
+		return gm.displayNewMaze(g, ov)
+	}}
+}
+
+// quitAction offers to close the program.
+func (gm *Game) quitAction() errorAction {
+	return errorAction{'Q', "Quit", func(g *gocui.Gui) error {
+		return gm.quit(g, nil)
+	}}
+}
+
+// showErrorDialog describes <problem> caused by <err> and waits for the key
+// of one of <actions>. Escape and Ctrl+Q close the dialog. In all cases, the
+// focus goes back to the view named <back>.
+func showErrorDialog(g *gocui.Gui, problem string, err error, back string, actions ...errorAction) error {
+	labels := make([]string, 0, len(actions)+1)
+	for _, a := range actions {
+		labels = append(labels, fmt.Sprintf("[%c] %s", a.key, tr(a.label)))
+	}
+	labels = append(labels, "[Esc] "+tr("Close"))
+
+	lines := []string{problem, capitalize(err.Error()) + "."}
+	if hint := errorHint(err); hint != "" {
+		lines = append(lines, hint)
+	}
+	lines = append(lines, "", strings.Join(labels, "   "))
+	width := 0
+	for _, line := range lines {
+		if n := textWidth(line) + 3; n > width {
+			width = n
+		}
+	}
+
+	maxX, maxY := g.Size()
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+	height := len(lines) + 3
+
+	dialogView, verr := setView(g, ERROR_DIALOG, (maxX-width)/2, (maxY-height)/2, (maxX+width)/2, (maxY+height)/2)
+	if verr != nil && verr != gocui.ErrUnknownView {
+		logError("Failed to display error dialog", "err", verr)
+		return verr
+	}
+
+	dialogView.Title = tr(" Error ")
+	dialogView.Frame = true
+	dialogView.FgColor = scheme().alert
+	dialogView.Editable = false
+	dialogView.Wrap = true
+	clearView(dialogView)
+	fmt.Fprint(dialogView, "\n "+strings.Join(lines, "\n "))
+
+	if _, verr = g.SetCurrentView(ERROR_DIALOG); verr != nil {
+		logError("Failed to set focus on error dialog", "err", verr)
+		return verr
+	}
+	_, _ = g.SetViewOnTop(ERROR_DIALOG)
+	g.Cursor = false
+
+	closeDialog := func(g *gocui.Gui, v *gocui.View) error {
+		closeErrorDialog(g, back)
+		return nil
+	}
+
+	bindings := map[interface{}]func(g *gocui.Gui, v *gocui.View) error{
+		gocui.KeyEsc: closeDialog, gocui.KeyCtrlQ: closeDialog,
+	}
+	for _, a := range actions {
+		run := a.run
+		handler := func(g *gocui.Gui, v *gocui.View) error {
+			closeErrorDialog(g, back)
+			return run(g)
+		}
+		bindings[unicode.ToUpper(a.key)] = handler
+		bindings[unicode.ToLower(a.key)] = handler
+	}
+
+	for key, handler := range bindings {
+		if verr = g.SetKeybinding(ERROR_DIALOG, key, gocui.ModNone, handler); verr != nil {
+			logError("Failed to bind key to error dialog", "key", key, "err", verr)
+			return verr
+		}
+	}
+
+	return nil
+}
+
+// closeErrorDialog removes the error dialog and moves
+// the focus back to the view named <back> if it exists.
+func closeErrorDialog(g *gocui.Gui, back string) {
+	g.DeleteKeybindings(ERROR_DIALOG)
+	if err := g.DeleteView(ERROR_DIALOG); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete error dialog", "err", err)
+	}
+
+	if _, err := g.SetCurrentView(back); err != nil {
+		logError("Failed to set back focus", "view", back, "err", err)
+	}
+}
+
+// capitalize returns <s> with its first letter in upper case.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,59 @@
+package main
+
+// This file defines the errors the player can act upon. The failures are
+// wrapped with their context around one of the errors below so the gui
+// tells what happened and how to get over it, whatever the layer which
+// failed. The other errors are only described.
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSaveCorrupt flags a session file which cannot be trusted.
+	ErrSaveCorrupt = errors.New("session file is corrupt or truncated")
+	// ErrSaveVersion flags a session file saved by a newer version.
+	ErrSaveVersion = errors.New("session file format is newer than supported")
+	// ErrTerminalTooSmall flags a maze or a layout not fitting the terminal.
+	ErrTerminalTooSmall = errors.New("terminal is too small")
+	// ErrGenerationFailed flags a maze which cannot be generated from the settings.
+	ErrGenerationFailed = errors.New("maze generation failed")
+)
+
+// errTooSmallFor returns the error of the maze of <width> x <height>
+// cells not fitting the view which displays it.
+func errTooSmallFor(width, height int) error {
+	return fmt.Errorf("%w for a maze of %d x %d cells (%d x %d needed)", ErrTerminalTooSmall, width, height, 2*width+1, height+1)
+}
+
+// errorHint returns what the player can do about <err>
+// or an empty string when nothing specific is known.
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, ErrSaveCorrupt):
+		return tr("Delete this session from the saved sessions (CTRL+L) or start a new maze.")
+	case errors.Is(err, ErrSaveVersion):
+		return tr("Update gomazes to the version which saved this session to load it.")
+	case errors.Is(err, ErrTerminalTooSmall):
+		return trf("Enlarge the terminal (at least %d x %d) or pick a smaller maze size.", MIN_TERM_WIDTH, MIN_TERM_HEIGHT)
+	case errors.Is(err, ErrGenerationFailed):
+		return tr("Check the maze algorithm and the doors placement in the settings (CTRL+E).")
+	}
+	return ""
+}
+
+// errorReason returns a short reason of <err> fitting into the status view.
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrSaveCorrupt):
+		return "ERR SAVE"
+	case errors.Is(err, ErrSaveVersion):
+		return "ERR VERS"
+	case errors.Is(err, ErrTerminalTooSmall):
+		return "ERR SIZE"
+	case errors.Is(err, ErrGenerationFailed):
+		return "ERR MAZE"
+	}
+	return "ERROR"
+}
@@ -0,0 +1,104 @@
+package main
+
+// This file provides the bus carrying the events of the game. The handlers
+// publish what happens to the round and the loop refreshing the timer, the
+// status and the position views subscribes to the events they display.
+// Any other feature may subscribe the same way without touching the handlers.
+
+import (
+	"sync"
+	"time"
+)
+
+// kinds of game events.
+type eventKind int
+
+const (
+	// the player moved to the cursor position carried.
+	EVENT_MOVE eventKind = iota
+	// a round starts or restarts with the time already played carried.
+	EVENT_START
+	EVENT_PAUSE
+	EVENT_RESUME
+	EVENT_WIN
+	EVENT_LOSE
+	// the session of the current maze was saved.
+	EVENT_SAVE
+	// something failed and the game should be restarted.
+	EVENT_ERROR
+	// the current maze was closed.
+	EVENT_CLEAR
+)
+
+// gameEvent is an event published on the bus. Only the fields
+// related to its kind are set.
+type gameEvent struct {
+	kind    eventKind
+	x, y    int
+	elapsed time.Duration
+	err     error
+	// maze of the round started.
+	maze *Grid
+}
+
+// eventBus delivers the published events to the subscribers
+// of their kind, in the order they were published.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers []subscriber
+}
+
+type subscriber struct {
+	kinds map[eventKind]bool
+	ch    chan gameEvent
+}
+
+// events is the bus of the game.
+var events = &eventBus{}
+
+// subscribe returns a channel receiving the events of <kinds>.
+// It receives all of them when no kind is given.
+func (b *eventBus) subscribe(kinds ...eventKind) <-chan gameEvent {
+	s := subscriber{ch: make(chan gameEvent, 16)}
+	if len(kinds) > 0 {
+		s.kinds = make(map[eventKind]bool, len(kinds))
+		for _, k := range kinds {
+			s.kinds[k] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, s)
+	b.mu.Unlock()
+	return s.ch
+}
+
+// publish sends <e> to its subscribers. It gives up on
+// the subscribers once the program is exiting.
+func (b *eventBus) publish(e gameEvent) {
+	b.mu.Lock()
+	subscribers := b.subscribers
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		if s.kinds != nil && !s.kinds[e.kind] {
+			continue
+		}
+
+		select {
+		case s.ch <- e:
+		case <-exit:
+			return
+		}
+	}
+}
+
+// publishMove publishes the move of the player to (cx, cy).
+func publishMove(cx, cy int) {
+	events.publish(gameEvent{kind: EVENT_MOVE, x: cx, y: cy})
+}
+
+// publishError publishes <err> which broke the game.
+func publishError(err error) {
+	events.publish(gameEvent{kind: EVENT_ERROR, err: err})
+}
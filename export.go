@@ -0,0 +1,117 @@
+package main
+
+// This file exports runs of the player as animated gif images. The first frame
+// is the whole maze and each next frame only redraws around the moving marker.
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	// folder of the data directory holding the exported images.
+	EXPORTS_FOLDER = "exports"
+
+	// bounds of each frame delay in 100ths of a second.
+	GIF_MIN_DELAY  = 4
+	GIF_MAX_DELAY  = 50
+	GIF_LAST_DELAY = 300
+)
+
+// exportRunGIF renders the recorded steps of a run over the maze drawn
+// with the theme <t> and writes the resulting animation at <path>.
+func exportRunGIF(maze *Grid, t wallTheme, steps []replayStep, path string) error {
+	if len(steps) == 0 {
+		return errors.New("no steps to export")
+	}
+
+	base := renderMazeImage(maze, t)
+	anim := &gif.GIF{}
+
+	first := image.NewPaletted(base.Bounds(), base.Palette)
+	draw.Draw(first, first.Bounds(), base, image.Point{}, draw.Src)
+	drawMarker(first, steps[0].X, steps[0].Y)
+	anim.Image = append(anim.Image, first)
+	anim.Delay = append(anim.Delay, GIF_MIN_DELAY)
+	anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+
+	for i := 1; i < len(steps); i++ {
+		prev, cur := steps[i-1], steps[i]
+		// erase previous marker and draw the new one.
+		area := markerRect(prev.X, prev.Y).Union(markerRect(cur.X, cur.Y)).Intersect(base.Bounds())
+		frame := image.NewPaletted(area, base.Palette)
+		draw.Draw(frame, area, base, area.Min, draw.Src)
+		drawMarker(frame, cur.X, cur.Y)
+
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, gifDelay(cur.At-prev.At))
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+
+	anim.Delay[len(anim.Delay)-1] = GIF_LAST_DELAY
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return gif.EncodeAll(file, anim)
+}
+
+// gifDelay converts the time between two steps into a bounded frame delay.
+func gifDelay(d time.Duration) int {
+	delay := int(d / (10 * time.Millisecond))
+	if delay < GIF_MIN_DELAY {
+		return GIF_MIN_DELAY
+	}
+
+	if delay > GIF_MAX_DELAY {
+		return GIF_MAX_DELAY
+	}
+
+	return delay
+}
+
+// exportPath returns the path of the file <name> into the exports folder
+// of the data directory, created when missing.
+func exportPath(name string) (string, error) {
+	if err := os.MkdirAll(dataPath(EXPORTS_FOLDER), 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dataPath(EXPORTS_FOLDER), name), nil
+}
+
+// exportRun saves the replay log of the current run as a gif
+// file named with the maze session id inside exports folder.
+func (gm *Game) exportRun(g *gocui.Gui, mv *gocui.View) error {
+	if gm.maze == nil || len(gm.run.log) == 0 {
+		logWarn("There is no run to export as gif")
+		notify("There is no run to export as gif")
+		return nil
+	}
+
+	fpath, err := exportPath(gm.id + ".gif")
+	if err != nil {
+		logError("Failed to create exports folder", "err", err)
+		notify("Failed to create exports folder: %v", err)
+		return nil
+	}
+
+	if err := exportRunGIF(gm.maze, gm.theme, gm.run.log, fpath); err != nil {
+		logError("Failed to export run as gif", "err", err)
+		notify("Failed to export run as gif: %v", err)
+		return nil
+	}
+
+	logInfo("Exported run as gif", "file", fpath)
+	notify("Run exported into %s", fpath)
+	return nil
+}
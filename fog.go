@@ -0,0 +1,23 @@
+package main
+
+// This file redraws the maze view content through the renderer. When fog is
+// active only the area around the player is displayed and the view is redrawn
+// after each move.
+
+import (
+	"github.com/awesome-gocui/gocui"
+)
+
+// drawMaze draws the current maze lines around the cursor of the maze view.
+func (gm *Game) drawMaze(mv *gocui.View) {
+	cx, cy := gm.mazeCursor(mv)
+	if err := gm.renderer.DrawMaze(gm.display, cx, cy); err != nil {
+		logError("Failed to draw maze", "err", err)
+	}
+}
+
+// refreshFog redraws the maze view after a move so the fog follows
+// the player and the trail grows.
+func (gm *Game) refreshFog(g *gocui.Gui, mv *gocui.View) {
+	gm.drawMaze(mv)
+}
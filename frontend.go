@@ -0,0 +1,116 @@
+package main
+
+// This file defines what a frontend draws of the game. The game asks its
+// renderer to draw the maze, the player and the overlays at cursor positions
+// of the ascii maze, so the moves and the rules stay the same whatever draws
+// them. The terminal gui is the renderer used to play. Images, plain text or
+// web pages may be drawn the same way by other renderers.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Renderer draws the game for a frontend.
+type Renderer interface {
+	// DrawMaze draws the styled lines of the maze with
+	// the player at the cursor position (cx, cy).
+	DrawMaze(lines []string, cx, cy int) error
+	// Invalidate marks the cursor <positions> to redraw on the next
+	// DrawMaze, or the whole maze when none is given.
+	Invalidate(positions ...[2]int)
+	// DrawPlayer shows the player at the cursor position (cx, cy) or hides it.
+	DrawPlayer(cx, cy int, shown bool) error
+	// DrawOverlay draws the marker <m> over the maze at the cursor position (cx, cy).
+	DrawOverlay(m *overlayMarker, cx, cy int) error
+}
+
+// guiRenderer draws the game into the views of the terminal gui.
+type guiRenderer struct {
+	g  *gocui.Gui
+	gm *Game
+	// what the maze view shows.
+	frame mazeFrame
+}
+
+// newGuiRenderer returns the renderer drawing the game <gm> into the views of <g>.
+func newGuiRenderer(g *gocui.Gui, gm *Game) *guiRenderer {
+	return &guiRenderer{g: g, gm: gm}
+}
+
+// DrawMaze writes the lines into the maze view with the cells painted and
+// the fog applied if any. A scrolled maze view only gets the window of the
+// maze it shows, formatted on its own. Once drawn, the next frames of the
+// same lines and window only rewrite the dirty positions. The zoomed view
+// is drawn the same way when displayed.
+func (r *guiRenderer) DrawMaze(lines []string, cx, cy int) error {
+	mv, err := r.g.View(MAZE)
+	if err != nil {
+		return err
+	}
+	defer traceRegion("DrawMaze")()
+	defer r.gm.applyZoom(r.g, mv)
+
+	ox, oy := r.gm.scrollX, r.gm.scrollY
+	if !r.frame.needsFull(mv, lines, ox, oy, r.gm.fog) {
+		r.frame.moveFog(cx, cy)
+		r.frame.redrawDirty(r.gm, mv)
+		return nil
+	}
+
+	window := lines
+	if r.gm.maze != nil && r.gm.isMazeScrolled(mv) {
+		vx, vy := mv.Size()
+		window = r.gm.mazeWindowLines(ox, oy, ox+vx, oy+vy)
+	}
+
+	clearView(mv)
+	var drawn strings.Builder
+	for y, line := range window {
+		if y > 0 {
+			drawn.WriteString("\n")
+		}
+
+		// the styled lines may hold multi-byte glyphs.
+		for x, glyph := range []rune(line) {
+			r.gm.drawGlyph(&drawn, glyph, ox+x, oy+y, cx, cy)
+		}
+	}
+
+	fmt.Fprint(mv, drawn.String())
+	if r.frame.view == mv && (r.frame.ox != ox || r.frame.oy != oy) {
+		// the markers follow the window scrolled.
+		defer redrawMarkers(r.g)
+	}
+	r.frame.reset(mv, lines, window, ox, oy, cx, cy, r.gm.fog)
+	return nil
+}
+
+// Invalidate marks the cursor <positions> of the maze view dirty.
+func (r *guiRenderer) Invalidate(positions ...[2]int) {
+	r.frame.invalidate(positions...)
+}
+
+// DrawPlayer moves the maze view cursor to (cx, cy) and shows or hides the
+// player there with its glyph or with the terminal cursor.
+func (r *guiRenderer) DrawPlayer(cx, cy int, shown bool) error {
+	if mv, err := r.g.View(MAZE); err == nil && shown {
+		if x, y := r.gm.mazeCursor(mv); x != cx || y != cy {
+			if err := r.gm.setMazeCursor(mv, cx, cy); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.gm.playerShown = shown
+	r.g.Cursor = shown
+	r.gm.followPlayer(r.g)
+	return nil
+}
+
+// DrawOverlay draws the marker <m> as a one character view over the maze view.
+func (r *guiRenderer) DrawOverlay(m *overlayMarker, cx, cy int) error {
+	return m.draw(r.g, cx, cy)
+}
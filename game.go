@@ -0,0 +1,282 @@
+package main
+
+// This file defines the state of the maze being played. The grid, its
+// rendering, the session it belongs to and the progress of the round are
+// kept together into the Game so the handlers share a single value and the
+// pause, the reset, the save and the load of a round are its methods. The
+// play command creates it and binds the keys and the settings to it, while
+// the goroutines only run its clock or reach it through the main loop.
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// Game holds the maze played and the progress of the player on it.
+type Game struct {
+	// size in cells of the maze played or of the next one.
+	width, height int
+	// grid of the current maze.
+	maze *Grid
+	// ascii format of the current maze.
+	data strings.Builder
+	// themed lines of the current maze used to draw it
+	// since the maze view may not display all of them.
+	lines []string
+	// saved session of the current maze and its label if any.
+	id    string
+	label string
+	// seed of the current maze used to pick its theme.
+	seed int64
+	// difficulty of the current maze.
+	score mazeScore
+	// latest coordinates of the cursor in maze.
+	cursorX, cursorY int
+	// set while paused and once the exit is reached or no more moves left.
+	paused bool
+	over   bool
+	// time played on the current maze shown by the timer view.
+	clock *Stopwatch
+	// draws the maze, the player and the overlays.
+	renderer Renderer
+	// used to throttle saving actions.
+	lastSave time.Time
+	// time of the next automatic save of the played maze.
+	autosaveDue time.Time
+
+	// rules of the current maze. the time limit is read by the timer.
+	fog       int
+	timeLimit int64
+	// walls theme of the current maze.
+	theme wallTheme
+	// lines of the current maze displayed into the maze view.
+	display []string
+	// cursor position of the maze shown at the top left corner of the
+	// maze view scrolled along the axes where the maze is larger than it.
+	scrollX, scrollY int
+	// set while the daily maze is played with its date.
+	isDaily   bool
+	dailyDate string
+
+	// progress of the current run.
+	run runState
+	// moves budget of the limited moves mode.
+	moves movesBudget
+	// checkpoints and ice cells of the current maze.
+	checkpoints checkpointsState
+	iceCells    map[[2]int]bool
+	// cursor positions of the solution once revealed and
+	// set once it was revealed during the maze.
+	solution         map[[2]int]bool
+	solutionRevealed bool
+	// score of the last won run.
+	lastScore int
+	// identifies the latest collision flash so an older one does not remove it.
+	flashID int
+
+	// markers drawn over the maze and set while the player
+	// position must be displayed.
+	player, ghost, opponent *overlayMarker
+	minotaur                minotaurState
+	playerShown             bool
+	// confetti markers of the victory animation and the channel
+	// stopping the goroutine animating it.
+	confetti    []*overlayMarker
+	stopVictory chan struct{}
+
+	// saved sessions listed by the listview.
+	sessions sessionsList
+}
+
+// newGame returns a game without maze whose next maze has
+// <width> x <height> cells.
+func newGame(width, height int) *Game {
+	gm := &Game{
+		width:       width,
+		height:      height,
+		clock:       NewStopwatch(nil),
+		theme:       classicTheme,
+		run:         runState{clock: NewStopwatch(nil), trail: make(map[[2]int]bool), accounted: true},
+		checkpoints: checkpointsState{last: -1},
+	}
+
+	gm.player = gm.newMarker(PLAYER, 0, gocui.ColorGreen)
+	gm.ghost = gm.newMarker(GHOST, '@', gocui.ColorCyan|gocui.AttrBold)
+	gm.opponent = gm.newMarker(OPPONENT, '&', gocui.ColorRed|gocui.AttrBold)
+	gm.minotaur.marker = gm.newMarker(MINOTAUR, 'M', gocui.ColorMagenta|gocui.AttrBold)
+	return gm
+}
+
+// setMaze makes <maze> the current maze whose size becomes the game one.
+// The session and the theme seed are left to the caller.
+func (gm *Game) setMaze(maze *Grid) {
+	gm.width, gm.height = gridSize(maze)
+	gm.data.Reset()
+	gm.data = formatMaze(maze, gm.width, gm.height)
+	gm.maze = maze
+	gm.score = scoreMaze(maze)
+	gm.id = ""
+}
+
+// clear forgets the current maze with its session, its rules
+// and the cells, moves budget and lines drawn of it.
+func (gm *Game) clear() {
+	gm.data.Reset()
+	gm.id = ""
+	gm.maze = nil
+	gm.lines = nil
+	gm.display = nil
+	gm.iceCells = nil
+	gm.moves = movesBudget{}
+	gm.clearSolution()
+	gm.deactivateRules()
+}
+
+// togglePause stops/resumes the timer and disable/enable navigation and reset keys.
+func (gm *Game) togglePause(g *gocui.Gui, mv *gocui.View) error {
+	var err error
+
+	// nothing to pause once the round is over.
+	if gm.over {
+		return nil
+	}
+
+	// inverse the game status.
+	gm.paused = !gm.paused
+
+	if gm.paused {
+		gm.pauseRunLog()
+		events.publish(gameEvent{kind: EVENT_PAUSE})
+		gm.showPlayer(g, false)
+		// game paused so disable controls keys bindings.
+		if err = unbindActions(g, mv.Name(), true); err != nil {
+			logError("Failed to pause the game. error disabling keys on maze view", "err", err)
+			return err
+		}
+
+		return nil
+	}
+
+	gm.resumeRunLog()
+	events.publish(gameEvent{kind: EVENT_RESUME})
+	gm.showPlayer(g, true)
+	// game resumed so enable controls keys bindings.
+	if err = bindActions(g, mv.Name(), true); err != nil {
+		logError("Failed to resume the game. error enabling keys on maze view", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// reset reinitializes the timer and move to the last reached
+// checkpoint if any or to the entrance position.
+func (gm *Game) reset(g *gocui.Gui, mv *gocui.View) error {
+	// the timer restarts even if halted at the end of the round.
+	gm.over = false
+	events.publish(gameEvent{kind: EVENT_START, maze: gm.maze})
+	gm.closeVictory(g)
+	gm.moves.left = gm.moves.budget
+	gm.updateMovesView(g)
+	gm.showPlayer(g, true)
+	cx, cy := gm.checkpointCursor(mv)
+	if err := gm.setMazeCursor(mv, cx, cy); err != nil {
+		logError("Failed to set cursor at middle of maze view", "err", err)
+		return err
+	}
+
+	publishMove(cx, cy)
+	gm.startRun(g, mv)
+	gm.refreshFog(g, mv)
+	return nil
+}
+
+// save saves current maze on file disk inside savedsessions folder.
+// It generates (if not already created) a dedicated file named with the
+// current maze session id which holds the maze grid and the
+// progress of the player. A label may be typed to name the session. See
+// savedSession for the content of the file.
+func (gm *Game) save(g *gocui.Gui, mv *gocui.View) error {
+
+	// throttle saving action. could be done each <SAVING_INTERVAL_SECS>.
+	if wait := SAVING_INTERVAL_SECS - time.Since(gm.lastSave).Seconds(); wait > 0 {
+		notify("Save throttled, wait %ds", int(math.Ceil(wait)))
+		return nil
+	}
+
+	// pause while typing the label and resume once done.
+	pausedHere := !gm.paused && !gm.over
+	if pausedHere {
+		if err := gm.togglePause(g, mv); err != nil {
+			return err
+		}
+	}
+
+	resume := func(g *gocui.Gui) error {
+		if pausedHere {
+			return gm.togglePause(g, mv)
+		}
+		return nil
+	}
+
+	return askInput(g, " Session Label (optional) ", gm.label, MAZE, func(g *gocui.Gui, label string) error {
+		gm.label = label
+		return gm.saveOrRecover(g, mv, MAZE, resume)
+	}, resume)
+}
+
+// load loads the saved session named <session> and displays its
+// maze. A failure is described with the actions to recover from it.
+func (gm *Game) load(g *gocui.Gui, session string) error {
+	saved, err := loadSession(session)
+	if err != nil {
+		logError("Failed to load existing maze data", "err", err)
+		problem := trf("The session %s cannot be loaded.", strings.ReplaceAll(session, ".", ":"))
+		actions := []errorAction{gm.newMazeAction(), gm.quitAction()}
+		// a corrupted session stays corrupted so retrying is useless.
+		if !errors.Is(err, ErrSaveCorrupt) {
+			actions = append([]errorAction{retryAction(func(g *gocui.Gui) error {
+				return gm.load(g, session)
+			})}, actions...)
+		}
+		return showErrorDialog(g, problem, err, OUTPUTS, actions...)
+	}
+
+	// movement bounds and maze view follow the dimensions of the session.
+	gm.setMaze(GridFromRows(saved.Grid))
+	gm.seed = saved.Seed
+	gm.displayMazeSize(g)
+	gm.cursorX, gm.cursorY = saved.CursorX, saved.CursorY
+
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		return err
+	}
+	clearView(ov)
+
+	if err := gm.createMazeView(g, ov); err != nil {
+		logError("Failed to load & display existing maze", "err", err)
+		return err
+	}
+
+	if mv := g.CurrentView(); mv != nil {
+		gm.setMazeCursor(mv, gm.cursorX, gm.cursorY)
+		publishMove(gm.cursorX, gm.cursorY)
+		// session saved on a checkpoint keeps it as fall back.
+		gm.reachCheckpoint(g, mv)
+		gm.startRun(g, mv)
+		gm.restoreMoves(g, saved.Moves)
+		gm.refreshFog(g, mv)
+	}
+
+	gm.id = session
+	gm.label = saved.Label
+
+	// restore and start timer.
+	events.publish(gameEvent{kind: EVENT_START, elapsed: time.Duration(saved.ElapsedMs) * time.Millisecond, maze: gm.maze})
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+// This file animates the generation of new mazes. The passages of the
+// generated maze are carved again cell by cell from the entrance into a
+// view placed where the maze is displayed, then the game starts. Enter,
+// Space or Escape skip the animation.
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	GENERATION = "generation"
+	// delay between two frames of the animation.
+	GENERATION_FRAME = 30 * time.Millisecond
+)
+
+// generationSpeed is a speed of the animation.
+type generationSpeed struct {
+	name string
+	// cells carved on each frame.
+	cellsPerFrame int
+}
+
+var (
+	generationSpeeds = []generationSpeed{
+		{"off", 0},
+		{"slow", 1},
+		{"normal", 4},
+		{"fast", 16},
+	}
+
+	// index of the current speed. 0 means no animation.
+	currentGenerationSpeed = 0
+
+	// set while a generation is animated.
+	isGenerating = false
+	// stops the goroutine animating the generation.
+	stopGeneration chan struct{}
+)
+
+// cycleGenerationSpeed switches to the next animation speed.
+// It applies to the next maze generated.
+func cycleGenerationSpeed(g *gocui.Gui, v *gocui.View) error {
+	currentGenerationSpeed = (currentGenerationSpeed + 1) % len(generationSpeeds)
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// carvingSteps returns the cells of <maze> in the order of a walk from the
+// entrance cell <in> through the open passages. Each cell comes with the
+// passages it opens toward the cells already carved and the doors.
+func carvingSteps(maze *Grid, in [2]int) [][3]int {
+	height, width := maze.Height(), maze.Width()
+
+	visited := make([][]bool, height)
+	for y := range visited {
+		visited[y] = make([]bool, width)
+	}
+
+	var steps [][3]int
+	stack := [][2]int{in}
+	for len(stack) > 0 {
+		cell := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := cell[0], cell[1]
+		if visited[y][x] {
+			continue
+		}
+		visited[y][x] = true
+
+		opened := 0
+		for _, d := range [4]int{N, S, E, W} {
+			if maze.At(x, y)&d == 0 {
+				continue
+			}
+
+			nX, nY := moveTo(x, y, d)
+			if nY < 0 || nY >= height || nX < 0 || nX >= width {
+				// entrance or exit door.
+				opened |= d
+				continue
+			}
+
+			if visited[nY][nX] {
+				opened |= d
+			} else {
+				stack = append(stack, [2]int{nX, nY})
+			}
+		}
+		steps = append(steps, [3]int{x, y, opened})
+	}
+
+	return steps
+}
+
+// carveStep opens into <partial> the passages of the carving step <step>
+// and the opposite ones of its carved neighbors.
+func carveStep(partial *Grid, step [3]int) {
+	oppositeDirections := map[int]int{N: S, S: N, E: W, W: E}
+	x, y, opened := step[0], step[1], step[2]
+	partial.Open(x, y, opened)
+	for _, d := range [4]int{N, S, E, W} {
+		if opened&d == 0 {
+			continue
+		}
+
+		nX, nY := moveTo(x, y, d)
+		if partial.Inside(nX, nY) {
+			partial.Open(nX, nY, oppositeDirections[d])
+		}
+	}
+}
+
+// animateGeneration carves <maze> into the generation view then calls
+// <onDone> to start the game. It returns false when the animation is off
+// or cannot be displayed so the game starts right away.
+func (gm *Game) animateGeneration(g *gocui.Gui, ov *gocui.View, maze *Grid, onDone func(g *gocui.Gui) error) bool {
+	speed := generationSpeeds[currentGenerationSpeed]
+	// the fog hides the maze so nothing would be seen.
+	if speed.cellsPerFrame == 0 || mazeFog > 0 {
+		return false
+	}
+
+	mx1, my1, mx2, my2 := gm.mazeViewPosition(ov.Size())
+	genView, err := setView(g, GENERATION, mx1, my1, mx2, my2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display generation view", "err", err)
+		return false
+	}
+
+	genView.Frame = false
+	genView.FgColor = scheme().wall
+	if t := pickTheme(gm.seed); t.name != classicTheme.name {
+		genView.FgColor = t.color
+	}
+	genView.BgColor = scheme().path
+	if _, err := g.SetCurrentView(GENERATION); err != nil {
+		logError("Failed to set focus on generation view", "err", err)
+		return false
+	}
+	_, _ = g.SetViewOnTop(GENERATION)
+	g.Cursor = false
+
+	for _, k := range []gocui.Key{gocui.KeyEnter, gocui.KeySpace, gocui.KeyEsc} {
+		if err := g.SetKeybinding(GENERATION, k, gocui.ModNone, skipGeneration); err != nil {
+			logError("Failed to bind key to generation view", "key", k, "err", err)
+		}
+	}
+
+	in, _ := mazeDoors(maze)
+	partial := NewGrid(maze.Width(), maze.Height())
+
+	isGenerating = true
+	stopGeneration = make(chan struct{})
+	wg.Add(1)
+	go gm.carveGeneration(g, partial, carvingSteps(maze, in), speed.cellsPerFrame, stopGeneration, onDone)
+	return true
+}
+
+// carveGeneration draws a frame of the carving every GENERATION_FRAME.
+// Once all the cells are carved or the animation is skipped, the view is
+// removed and <onDone> is called. The game is only drawn from the main loop.
+func (gm *Game) carveGeneration(g *gocui.Gui, partial *Grid, steps [][3]int, cellsPerFrame int, stop chan struct{}, onDone func(g *gocui.Gui) error) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(GENERATION_FRAME)
+	defer ticker.Stop()
+
+	for i := 0; i < len(steps); {
+		select {
+		case <-exit:
+			return
+		case <-stop:
+			i = len(steps)
+			continue
+		case <-ticker.C:
+		}
+
+		for n := 0; n < cellsPerFrame && i < len(steps); n++ {
+			carveStep(partial, steps[i])
+			i++
+		}
+
+		frame := formatMaze(partial, partial.Width(), partial.Height())
+		g.Update(func(g *gocui.Gui) error {
+			gm.drawGenerationFrame(g, frame.String())
+			return nil
+		})
+	}
+
+	g.Update(func(g *gocui.Gui) error {
+		closeGenerationView(g)
+		return onDone(g)
+	})
+}
+
+// drawGenerationFrame displays the maze carved so far with the walls
+// style and the theme in use.
+func (gm *Game) drawGenerationFrame(g *gocui.Gui, ascii string) {
+	genView, err := g.View(GENERATION)
+	if err != nil {
+		return
+	}
+
+	lines := strings.Split(themeMaze(ascii, pickTheme(gm.seed)), "\n")
+	clearView(genView)
+	fmt.Fprint(genView, strings.Join(styleMazeLines(lines, ascii, wallStyles[currentWallStyle]), "\n"))
+}
+
+// skipGeneration stops the animation so the game starts right away.
+func skipGeneration(g *gocui.Gui, v *gocui.View) error {
+	if stopGeneration != nil {
+		close(stopGeneration)
+		stopGeneration = nil
+	}
+	return nil
+}
+
+// closeGenerationView removes the generation view and gives
+// the focus back to the outputs view.
+func closeGenerationView(g *gocui.Gui) {
+	isGenerating = false
+	stopGeneration = nil
+	g.DeleteKeybindings(GENERATION)
+	if err := g.DeleteView(GENERATION); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete generation view", "err", err)
+	}
+
+	if _, err := g.SetCurrentView(OUTPUTS); err != nil {
+		logError("Failed to set focus on outputs view", "err", err)
+	}
+}
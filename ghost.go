@@ -0,0 +1,114 @@
+package main
+
+// This file implements the ghost racing. The best run of each maze is saved
+// and, when the same maze is played again, a ghost marker replays that run
+// in real time so the player can race against it.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	GHOST         = "ghost"
+	GHOSTS_FOLDER = "ghosts"
+)
+
+// ghostPath returns the file path of the best run of the current maze.
+func (gm *Game) ghostPath() string {
+	return dataPath(GHOSTS_FOLDER) + string(os.PathSeparator) + fmt.Sprintf("%016x", uint64(hashSeed(gm.data.String())))
+}
+
+// saveBestRun saves the current run when the maze has
+// no recorded run yet or when it is faster than it.
+func (gm *Game) saveBestRun() {
+	if len(gm.run.log) == 0 {
+		return
+	}
+
+	if best, err := loadRun(gm.ghostPath()); err == nil && best[len(best)-1].At <= gm.run.log[len(gm.run.log)-1].At {
+		return
+	}
+
+	if _, err := os.Stat(dataPath(GHOSTS_FOLDER)); errors.Is(err, os.ErrNotExist) {
+		// folder does not exist. we create it.
+		if err := os.Mkdir(dataPath(GHOSTS_FOLDER), 0755); err != nil {
+			logError("Failed to create ghosts folder", "err", err)
+			return
+		}
+	}
+
+	file, err := os.Create(gm.ghostPath())
+	if err != nil {
+		logError("Failed to create ghost file", "err", err)
+		return
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, step := range gm.run.log {
+		fmt.Fprintln(w, step.X, step.Y, step.At.Milliseconds())
+	}
+
+	if err = w.Flush(); err != nil {
+		logError("Failed to save best run into ghost file", "err", err)
+	}
+}
+
+// loadRun reads a saved run. Each line is made of <x> <y> <milliseconds>.
+func loadRun(path string) ([]replayStep, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var steps []replayStep
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			return nil, errors.New("wrong ghost step format")
+		}
+
+		var values [3]int
+		for i, field := range fields {
+			if values[i], err = strconv.Atoi(field); err != nil {
+				return nil, errors.New("wrong ghost step value")
+			}
+		}
+		steps = append(steps, replayStep{X: values[0], Y: values[1], At: time.Duration(values[2]) * time.Millisecond})
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(steps) == 0 {
+		return nil, errors.New("empty ghost file")
+	}
+
+	return steps, nil
+}
+
+// startGhost replays the best run of the current maze if any.
+func (gm *Game) startGhost(g *gocui.Gui) {
+	gm.ghost.close(g)
+
+	steps, err := loadRun(gm.ghostPath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logError("Failed to load best run of the maze", "err", err)
+		}
+		return
+	}
+
+	gm.ghost.start(g, gm.run.clock, steps, nil)
+}
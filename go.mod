@@ -2,9 +2,21 @@ module github.com/jeamon/gomazes
 
 go 1.17
 
-require github.com/jroimartin/gocui v0.5.0
+require (
+	github.com/awesome-gocui/gocui v1.1.0
+	github.com/creack/pty v1.1.21
+	github.com/gliderlabs/ssh v0.3.8
+	github.com/mattn/go-runewidth v0.0.10
+	golang.org/x/crypto v0.31.0
+	golang.org/x/sys v0.28.0
+	golang.org/x/term v0.27.0
+)
 
 require (
-	github.com/mattn/go-runewidth v0.0.9 // indirect
-	github.com/nsf/termbox-go v1.1.1 // indirect
+	github.com/anmitsu/go-shlex v0.0.0-20200514113438-38f4b401e2be // indirect
+	github.com/gdamore/encoding v1.0.0 // indirect
+	github.com/gdamore/tcell/v2 v2.4.0 // indirect
+	github.com/lucasb-eyer/go-colorful v1.0.3 // indirect
+	github.com/rivo/uniseg v0.1.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
 )
@@ -0,0 +1,110 @@
+package main
+
+// This file answers the questions the moves ask about the maze from its
+// grid of cells rather than from the characters displayed. The cursor of
+// the maze view walks the ascii drawing where the cell (x, y) is at the
+// column 2x+1 of the line y+1, the columns in between hold the walls and
+// the openings between two cells, and the line 0 is the top border with
+// the entrance. The functions below map those positions on the grid.
+
+// cellCursor returns the cursor position of the cell (x, y).
+func cellCursor(x, y int) (int, int) {
+	return 2*x + 1, y + 1
+}
+
+// cursorCell returns the cell under the cursor position (cx, cy). On the
+// columns between two cells, it returns the cell on the left.
+func cursorCell(cx, cy int) (int, int) {
+	return (cx - 1) / 2, cy - 1
+}
+
+// gridSize returns the width and the height in cells of <maze>.
+func gridSize(maze *Grid) (int, int) {
+	if maze == nil {
+		return 0, 0
+	}
+	return maze.Width(), maze.Height()
+}
+
+// southWallAt tells if the cursor position (cx, cy) of <maze> shows an
+// horizontal wall, which is the south wall of the cells on the line cy
+// and the top border on the line 0.
+func southWallAt(maze *Grid, cx, cy int) bool {
+	width, height := gridSize(maze)
+	if cx <= 0 || cx >= 2*width || cy < 0 || cy > height {
+		return false
+	}
+
+	if cy == 0 {
+		// the entrance opens the top border over its cell and its corners.
+		for x := 0; x < width; x++ {
+			if (maze.At(x, 0)&N) != 0 && cx >= 2*x && cx <= 2*x+2 {
+				return false
+			}
+		}
+		return true
+	}
+
+	y := cy - 1
+	if cx%2 == 1 {
+		return (maze.At((cx-1)/2, y) & S) == 0
+	}
+
+	// between two cells, a vertical wall hides the south walls. Else the
+	// opening is closed below only when both cells are closed below.
+	x := cx/2 - 1
+	if (maze.At(x, y) & W) == 0 {
+		return false
+	}
+	return ((maze.At(x, y) | maze.At(x+1, y)) & S) == 0
+}
+
+// sideWallAt tells if the cursor position (cx, cy) of <maze> shows a
+// vertical wall, which is the borders of the rows and the walls between
+// two cells of a row not opened to each other.
+func sideWallAt(maze *Grid, cx, cy int) bool {
+	width, height := gridSize(maze)
+	if cy <= 0 || cy > height || cx < 0 || cx > 2*width || cx%2 == 1 {
+		return false
+	}
+
+	if cx == 0 || cx == 2*width {
+		return true
+	}
+	return (maze.At(cx/2-1, cy-1) & W) == 0
+}
+
+// wallAt tells if the cursor position (cx, cy) of <maze> shows a wall.
+func wallAt(maze *Grid, cx, cy int) bool {
+	return southWallAt(maze, cx, cy) || sideWallAt(maze, cx, cy)
+}
+
+// canGo tells if the cursor at (cx, cy) of <maze> can move by one step in
+// the direction (dx, dy). The south wall of a cell is on its own line so
+// going down checks the current line then the vertical walls below, while
+// the other directions only check the position reached.
+func canGo(maze *Grid, cx, cy, dx, dy int) bool {
+	width, height := gridSize(maze)
+	nx, ny := cx+dx, cy+dy
+	if nx < 0 || nx > 2*width-1 || ny < 0 || ny > height {
+		return false
+	}
+
+	switch {
+	case dy > 0:
+		return !southWallAt(maze, cx, cy) && !sideWallAt(maze, nx, ny)
+	case dy < 0:
+		return !wallAt(maze, nx, ny)
+	case dx != 0:
+		// on the top border, only the opening above the entrance is walkable.
+		return !(cy == 0 && southWallAt(maze, nx, ny)) && !sideWallAt(maze, nx, ny)
+	}
+	return false
+}
+
+// cursorPositions returns the number of cursor positions over <maze>, which
+// bounds any way walked in a single action.
+func cursorPositions(maze *Grid) int {
+	width, height := gridSize(maze)
+	return (2*width + 1) * (height + 1)
+}
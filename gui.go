@@ -8,20 +8,17 @@ package main
 // Created  : 22 November 2021
 
 import (
-	"bufio"
-	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"log"
 	"os"
-	"os/exec"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/jroimartin/gocui"
+	"github.com/awesome-gocui/gocui"
 )
 
 const (
@@ -31,154 +28,324 @@ const (
 	TIMER    = "timer"
 	STATUS   = "status"
 	SIZE     = "size"
+	SEED     = "seed"
 	HELP     = "help"
 	MAZE     = "maze"
 
 	TWIDTH  = 11
 	PWIDTH  = 30
 	SWIDTH  = 45
-	SZWIDTH = 58
+	SZWIDTH = 72
+	SDWIDTH = 96
 	HWIDTH  = 44
-	HHEIGHT = 28
+
+	INFOS_TEXT = "F1 or CTRL+D [Display Help] - CTRL+N [Play New Maze] - CTRL+C [Exit Game]"
 
 	SAVING_INTERVAL_SECS = 15
+	// refresh period of the timer view.
+	TIMER_REFRESH = 250 * time.Millisecond
 )
 
-const helpDetails = `
-
--------------+----------------------------
-    CTRL + D | close this help window
--------------+----------------------------
-    CTRL + E | edit maze width/height
--------------+----------------------------
-    CTRL + N | create a full new maze
--------------+----------------------------
-    CTRL + Q | quit existing challenge
--------------+----------------------------
-    CTRL + P | pause current challenge
--------------+----------------------------
-    CTRL + R | resume from paused game
--------------+----------------------------
-    CTRL + S | save current game state
--------------+----------------------------
-    CTRL + L | load a saved game state
--------------+----------------------------
-    CTRL + F | find & display solution
--------------+----------------------------
-    ↕ and ↔  | navigate into the maze
--------------+----------------------------
-    CTRL + C | close the whole program
--------------+----------------------------
-
-::::::: Craft with ♥ by Jerome Amon ::::::
-`
+// helpEntries are the rows of the help window: the keys of the left column
+// and the lines of their description, translated when displayed.
+var helpEntries = []struct {
+	keys  string
+	lines []string
+}{
+	{"    CTRL + D", []string{"close this help window"}},
+	{"    CTRL + E", []string{"edit settings (size, colors..)", "and rebind the keys (Bindings)", "moves with hjkl, wasd, esdf", "or the numpad (Keys)"}},
+	{"SHIFT+ARROWS", []string{"travel to the next junction", "also PGUP, PGDN, HOME, END"}},
+	{"    CTRL + N", []string{"create a full new maze"}},
+	{"    CTRL + Q", []string{"quit existing challenge"}},
+	{"    CTRL + P", []string{"pause current challenge"}},
+	{"    CTRL + R", []string{"resume from paused game"}},
+	{"    CTRL + S", []string{"save current game state"}},
+	{"    CTRL + L", []string{"load a saved game state"}},
+	{"    CTRL + F", []string{"find & display solution"}},
+	{"    CTRL + G", []string{"export current run as gif"}},
+	{"    CTRL + O", []string{"export current maze as png"}},
+	{"    CTRL + B", []string{"display best times board"}},
+	{"    CTRL + K", []string{"show share code of the maze"}},
+	{"    CTRL + A", []string{"display the maze structure"}},
+	{"    M", []string{"toggle limited moves mode"}},
+	{"    T", []string{"play the maze of the day"}},
+	{"    W", []string{"toggle decorative walls"}},
+	{"    G", []string{"switch generation animation"}},
+	{"    O", []string{"switch computer opponent"}},
+	{"    A", []string{"toggle auto-run corridors"}},
+	{"    P", []string{"switch doors placement"}},
+	{"    K", []string{"toggle checkpoint cells"}},
+	{"    B", []string{"toggle bell audio cues"}},
+	{"    I", []string{"toggle sliding ice tiles"}},
+	{"    E", []string{"switch minotaur enemy"}},
+	{"    S", []string{"display lifetime stats"}},
+	{"    C", []string{"play maze from share code"}},
+	{"    F", []string{"play maze from a file"}},
+	{"    Z", []string{"zoom in & out maze cells"}},
+	{"  U / CTRL+U", []string{"switch walls drawing style"}},
+	{"  V / CTRL+V", []string{"switch gui color scheme"}},
+	{"    ↕ & ↔", []string{"navigate into the maze"}},
+	{"    CTRL + C", []string{"close the whole program"}},
+}
 
 var (
-	// default maze size.
-	MAZEHEIGHT int = 10
-	MAZEWIDTH  int = 15
-
-	// control timer in updateTimerView.
-	stopTimer  = make(chan struct{})
-	resetTimer = make(chan struct{})
-	// control game status. 1 means paused.
-	// 0 means ready to play, 2 means empty.
-	// 3 means error so need to restart game.
-	statusGame   = make(chan uint8, 3)
-	isGamePaused = false
-
-	cursorPosition = make(chan string, 10)
+	// titles of the views of the bottom bar.
+	barTitles = map[string]string{
+		TIMER:    " Timer ",
+		POSITION: " Position ",
+		STATUS:   " Status ",
+		SIZE:     " Size | Score | Fit ",
+		SEED:     " Seed ",
+	}
 
 	// control goroutines.
 	exit = make(chan struct{})
 	wg   sync.WaitGroup
 
-	// keep latest coordinates of the cursor in maze.
-	latestMazeCursorX, latestMazeCursorY int
+	// last event shown into the status view.
+	shownStatus gameEvent
 
-	// store formatted current maze infos.
-	currentMazeData strings.Builder
-	currentMazeID   string
-	// used to throttle saving actions.
-	lastestSavingTime time.Time
+	// seed given on the command line for the first new maze.
+	startSeed   int64
+	isStartSeed = false
 )
 
 func main() {
 
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
-	// on windows only change terminal title.
-	if runtime.GOOS == "windows" {
-		exec.Command("cmd", "/c", "title [ GoMazes By Jerome Amon ]").Run()
+	restore := setupConsole()
+	code := runCLI(os.Args[1:])
+	restore()
+	os.Exit(code)
+}
+
+// play runs the game into the terminal. This is the default command.
+func play(args []string) error {
+
+	setConsoleTitle(CONSOLE_TITLE)
+
+	// the game played and the actions and settings acting on it.
+	gm := newGame(15, 10)
+	setupKeyActions(gm)
+	setupSettings(gm)
+
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "play [flags] [width height]", "play the mazes into the terminal")
+	width := fs.Int("width", gm.width, "default maze width in cells")
+	height := fs.Int("height", gm.height, "default maze height in cells")
+	doors := fs.String("doors", "center", "entrance & exit placement: center, random, corners or <entrance,exit> columns")
+	dir := fs.String("dir", "", "directory to keep saves, records, logs and config (default per-user directories)")
+	store := fs.String("store", "", "WebDAV url (http[s]://user:password@host/path) to sync saved sessions (default data folder)")
+	saves := fs.String("saves", "", "folder of the saved sessions files (default data folder or the config one)")
+	seed := fs.Int64("seed", 0, "seed of the first new maze to play it again (default random)")
+	keepSaves := fs.Int("keep-saves", 0, "maximum number of saved sessions kept, oldest pruned first (0 for no limit)")
+	keepDays := fs.Int("keep-days", 0, "maximum age in days of saved sessions kept (0 for no limit)")
+	fit := fs.String("fit", "ask", "mazes larger than the screen: "+strings.Join(mazeFitModes, "/")+" to pick when it happens")
+	fs.StringVar(&sessionFormat, "save-format", "auto", "format of the saved sessions: "+strings.Join(sessionFormatNames, "/")+", auto saving the huge mazes in binary")
+	walls := fs.String("walls", "ascii", "walls drawing style: "+wallStyleNames())
+	glyph := fs.String("player", "", "character or emoji drawing the player instead of the terminal cursor")
+	colors := fs.String("colors", "classic", "color scheme: "+colorSchemeNames()+" or one of the colors file")
+	lang := fs.String("lang", "auto", "language of the texts: auto for the one of LC_ALL, LC_MESSAGES or LANG, or one of "+strings.Join(localeNames(), "/")+" or of the locales folder")
+	level := fs.String("log-level", "info", "lowest level of the logs kept: "+strings.Join(logLevelNames, "/"))
+	fs.StringVar(&startMazeFile, "maze", "", "maze file (text, # blocks, JSON or saved session) to play first instead of a new maze")
+	profiles := fs.String("pprof", "", "address like :6060 serving the profiles and traces while playing")
+	spectate := fs.String("spectate", "", "address like :7777 streaming the game to the spectators running gomazes watch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// flags given on the command line prevail over the config file.
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+		if f.Name == "seed" {
+			startSeed, isStartSeed = *seed, true
+		}
+	})
+
+	if _, found := findWallStyle(*walls); !found {
+		return fmt.Errorf("unknown walls style %q. expected one of %s", *walls, wallStyleNames())
 	}
 
-	f, err := os.OpenFile("logs.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	minLevel, err := parseLogLevel(*level)
 	if err != nil {
-		log.Println("failed to create logs file.")
+		return err
 	}
-	defer f.Close()
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.SetOutput(f)
+	setLogLevel(minLevel)
 
-	// setup default minimum maze size.
-	if len(os.Args) == 3 {
-		if w, err := strconv.Atoi(os.Args[1]); err == nil {
-			if w > MAZEWIDTH {
-				MAZEWIDTH = w
-			}
+	if !isSessionFormat(sessionFormat) {
+		return fmt.Errorf("unknown save format %q. expected one of %s", sessionFormat, strings.Join(sessionFormatNames, "/"))
+	}
+
+	if !isMazeFitMode(*fit) {
+		return fmt.Errorf("unknown fit mode %q. expected one of %s", *fit, strings.Join(mazeFitModes, "/"))
+	}
+
+	maxSessions = *keepSaves
+	maxSessionAge = time.Duration(*keepDays) * 24 * time.Hour
+
+	dirErr := setupDirs(*dir)
+
+	// the logs are kept into the file while the gui owns the terminal.
+	if err := setupLogs(cachePath(LOG_FILE)); err != nil {
+		logError("Failed to create logs file", "err", err)
+	}
+	defer closeLogs()
+
+	if *profiles != "" {
+		stop, err := startProfiling(*profiles)
+		if err != nil {
+			return fmt.Errorf("cannot serve profiles: %w", err)
+		}
+		defer stop()
+	}
+
+	if *spectate != "" {
+		stop, err := startSpectating(*spectate)
+		if err != nil {
+			return fmt.Errorf("cannot stream to spectators: %w", err)
 		}
+		defer stop()
+	}
 
-		if h, err := strconv.Atoi(os.Args[2]); err == nil {
-			if h > MAZEHEIGHT {
-				MAZEHEIGHT = h
-			}
+	if dirErr != nil {
+		logError("Failed to setup user directories", "err", dirErr)
+	}
+
+	if err := loadLocaleFiles(); err != nil {
+		logError("Failed to load translations files", "err", err)
+		notify("Failed to load translations: %v", err)
+	}
+	setLocale("auto")
+
+	if err := loadColorSchemes(); err != nil {
+		logError("Failed to load colors file", "err", err)
+		notify("Failed to load colors file: %v", err)
+	}
+
+	if err := gm.loadSettings(); err != nil {
+		logError("Failed to load settings file", "err", err)
+		notify("Failed to load settings: %v", err)
+	}
+
+	if given["saves"] {
+		savesDir = *saves
+	}
+
+	if given["fit"] {
+		setMazeFit(*fit)
+	}
+
+	if given["lang"] && !setLocale(strings.ToLower(*lang)) {
+		return fmt.Errorf("unknown language %q. expected auto or one of %s", *lang, strings.Join(localeNames(), "/"))
+	}
+
+	if err := loadKeybindings(); err != nil {
+		logError("Failed to load keys file", "err", err)
+		notify("Failed to load keys: %v", err)
+	}
+	if k, other := keySchemeConflict(currentKeyScheme); other != nil {
+		logWarn("Switched back to the arrows keys", "scheme", keySchemes[currentKeyScheme].name, "key", keyName(k), "action", other.id())
+		notify("Cannot use the %s keys: %s already bound to %s", keySchemes[currentKeyScheme].name, keyName(k), other.id())
+		currentKeyScheme = 0
+	}
+	checkConsoleKeys()
+
+	if given["walls"] {
+		currentWallStyle, _ = findWallStyle(*walls)
+	}
+
+	if s, found := findColorScheme(*colors); !found {
+		return fmt.Errorf("unknown color scheme %q. expected one of %s", *colors, colorSchemeNames())
+	} else if given["colors"] {
+		currentColorScheme = s
+	}
+
+	if err := gm.setupPlayerGlyph(*glyph); err != nil {
+		return err
+	}
+
+	if err := setupSessionStore(*store); err != nil {
+		logError("Failed to setup sessions store. Using data folder", "err", err)
+		notify("Sessions store unavailable, saving into data folder")
+	}
+
+	if _, _, err := resolveDoors(*doors, gm.width, seededRand(0)); err != nil {
+		logError("Failed to setup doors placement", "err", err)
+	} else {
+		doorsPlacement = *doors
+	}
+
+	// setup default minimum maze size. the width and height may
+	// still be given as arguments like in the older versions.
+	if args := fs.Args(); len(args) == 2 {
+		if w, err := strconv.Atoi(args[0]); err == nil {
+			*width, given["width"] = w, true
+		}
+
+		if h, err := strconv.Atoi(args[1]); err == nil {
+			*height, given["height"] = h, true
 		}
 	}
 
-	g, err := gocui.NewGui(gocui.OutputNormal)
+	// smaller sizes than the defaults are ignored.
+	if given["width"] && *width >= 15 {
+		gm.width = *width
+	}
+
+	if given["height"] && *height >= 10 {
+		gm.height = *height
+	}
+
+	g, err := newGui()
 	if err != nil {
-		log.Panicln(err)
+		logError("Failed to initialize the terminal", "err", err)
+		return err
 	}
 	defer g.Close()
+	gm.renderer = newGuiRenderer(g, gm)
 
 	g.Highlight = true
 	g.SelFgColor = gocui.ColorRed
+	g.SelFrameColor = gocui.ColorRed
 	g.BgColor = gocui.ColorBlack
 	g.FgColor = gocui.ColorWhite
 	g.Cursor = false
 	g.InputEsc = true
 	g.Mouse = false
 
-	g.SetManagerFunc(layout)
+	g.SetManagerFunc(gm.layout)
 
-	err = g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit)
+	err = g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, gm.quit)
 	if err != nil {
-		log.Println("Could not set key binding:", err)
-		return
+		logError("Could not set key binding", "err", err)
+		return err
 	}
 
-	maxX, maxY := g.Size()
+	maxX, maxY := layoutSize(g)
+	layoutWidth, layoutHeight = maxX, maxY
 
 	// Outputs view.
-	outputsView, err := g.SetView(OUTPUTS, 0, 0, maxX-1, maxY-4)
+	outputsView, err := setView(g, OUTPUTS, 0, 0, maxX-1, maxY-4)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create outputs view:", err)
-		return
+		logError("Failed to create outputs view", "err", err)
+		return err
 	}
-	outputsView.Title = " The Maze "
 	outputsView.FgColor = gocui.ColorWhite
 	outputsView.SelBgColor = gocui.ColorGreen
 	outputsView.SelFgColor = gocui.ColorBlack
 	outputsView.Editable = false
 	outputsView.Wrap = false
+	refreshOutputsTitle(g)
 
 	// Timer view.
-	timerView, err := g.SetView(TIMER, 0, maxY-3, TWIDTH, maxY-1)
+	timerView, err := setView(g, TIMER, 0, maxY-3, TWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create timer view:", err)
-		return
+		logError("Failed to create timer view", "err", err)
+		return err
 	}
-	timerView.Title = " Timer "
+	timerView.Title = tr(barTitles[TIMER])
 	timerView.FgColor = gocui.ColorGreen
 	timerView.SelBgColor = gocui.ColorBlack
 	timerView.SelFgColor = gocui.ColorYellow
@@ -187,12 +354,12 @@ func main() {
 	fmt.Fprint(timerView, " 00:00:00 ")
 
 	// Position view.
-	positionView, err := g.SetView(POSITION, TWIDTH+1, maxY-3, PWIDTH, maxY-1)
+	positionView, err := setView(g, POSITION, TWIDTH+1, maxY-3, PWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create position view:", err)
-		return
+		logError("Failed to create position view", "err", err)
+		return err
 	}
-	positionView.Title = " Position "
+	positionView.Title = tr(barTitles[POSITION])
 	positionView.FgColor = gocui.ColorGreen
 	positionView.SelBgColor = gocui.ColorBlack
 	positionView.SelFgColor = gocui.ColorYellow
@@ -200,233 +367,310 @@ func main() {
 	positionView.Wrap = false
 
 	// Status view.
-	statusView, err := g.SetView(STATUS, PWIDTH+1, maxY-3, SWIDTH, maxY-1)
+	statusView, err := setView(g, STATUS, PWIDTH+1, maxY-3, SWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create status view:", err)
-		return
+		logError("Failed to create status view", "err", err)
+		return err
 	}
-	statusView.Title = " Status "
-	statusView.FgColor = gocui.ColorRed
+	statusView.Title = tr(barTitles[STATUS])
+	statusView.FgColor = scheme().alert
 	statusView.SelBgColor = gocui.ColorBlack
 	statusView.SelFgColor = gocui.ColorRed
 	statusView.Editable = false
 	statusView.Wrap = false
 
 	// Size view.
-	sizeView, err := g.SetView(SIZE, SWIDTH+1, maxY-3, SZWIDTH, maxY-1)
+	sizeView, err := setView(g, SIZE, SWIDTH+1, maxY-3, SZWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create maze size view:", err)
-		return
+		logError("Failed to create maze size view", "err", err)
+		return err
 	}
-	sizeView.Title = " Size "
+	sizeView.Title = tr(barTitles[SIZE])
 	sizeView.FgColor = gocui.ColorGreen
 	sizeView.SelBgColor = gocui.ColorBlack
 	sizeView.SelFgColor = gocui.ColorYellow
 	sizeView.Editable = false
 	sizeView.Wrap = false
-	fmt.Fprintf(sizeView, center(fmt.Sprintf("%d x %d", MAZEWIDTH, MAZEHEIGHT), SZWIDTH-SWIDTH-1, " "))
+	fmt.Fprint(sizeView, center(gm.mazeSizeText(), SZWIDTH-SWIDTH-1, " "))
+
+	// Seed view.
+	seedView, err := setView(g, SEED, SZWIDTH+1, maxY-3, SDWIDTH, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to create maze seed view", "err", err)
+		return err
+	}
+	seedView.Title = tr(barTitles[SEED])
+	seedView.FgColor = gocui.ColorGreen
+	seedView.SelBgColor = gocui.ColorBlack
+	seedView.SelFgColor = gocui.ColorYellow
+	seedView.Editable = false
+	seedView.Wrap = false
+	fmt.Fprint(seedView, center("--", SDWIDTH-SZWIDTH-1, " "))
 
 	// Infos view.
-	infosView, err := g.SetView(INFOS, SZWIDTH+1, maxY-3, maxX-1, maxY-1)
+	infosView, err := setView(g, INFOS, SDWIDTH+1, maxY-3, maxX-1, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create help view:", err)
-		return
+		logError("Failed to create help view", "err", err)
+		return err
 	}
 	infosView.FgColor = gocui.ColorWhite
 	infosView.SelBgColor = gocui.ColorBlack
 	infosView.SelFgColor = gocui.ColorYellow
 	infosView.Editable = false
 	infosView.Wrap = false
-	fmt.Fprint(infosView, center("F1 or CTRL+D [Display Help] - CTRL+N [Play New Maze] - CTRL+C [Exit Game]", maxX-SZWIDTH-2, " "))
+	fmt.Fprint(infosView, center(tr(INFOS_TEXT), maxX-SDWIDTH-2, " "))
+	gm.applyColorScheme(g)
 
 	// Apply keybindings to program.
 	if err = keybindings(g); err != nil {
-		log.Println("Failed to setup keybindings:", err)
-		return
+		logError("Failed to setup keybindings", "err", err)
+		return err
 	}
 
 	// move the focus on the jobs list box.
 	if _, err = g.SetCurrentView(OUTPUTS); err != nil {
-		log.Println("Failed to set focus on outputs view:", err)
-		return
+		logError("Failed to set focus on outputs view", "err", err)
+		return err
 	}
 
 	// adjust maze default size based on outputs view.
-	x, y := outputsView.Size()
-	if 2*MAZEWIDTH >= x {
-		MAZEWIDTH = (x - 2) / 2
-	}
-
-	if MAZEHEIGHT >= y {
-		MAZEHEIGHT = y - 2
-	}
-
-	wg.Add(1)
-	go updateTimerView(g)
+	gm.limitMazeSize(outputsView.Size())
 
+	// subscribe before the first events are published.
 	wg.Add(1)
-	go updatePositionView(g, PWIDTH-TWIDTH-1)
+	go updateInfoViews(g, gm, events.subscribe(EVENT_MOVE, EVENT_START, EVENT_PAUSE, EVENT_RESUME, EVENT_WIN, EVENT_LOSE, EVENT_ERROR, EVENT_CLEAR))
 
 	wg.Add(1)
-	go updateStatusView(g)
+	go updateToastView(g)
 
 	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
 		close(exit)
-		log.Println("Exited from the main loop:", err)
+		logError("Exited from the main loop", "err", err)
 	}
 
 	wg.Wait()
+	return nil
 }
 
-func layout(g *gocui.Gui) error {
+func (gm *Game) layout(g *gocui.Gui) error {
 	maxX, maxY := g.Size()
+	if gm.guardTermSize(g, maxX, maxY) {
+		return nil
+	}
 
 	// Outputs view.
-	_, err := g.SetView(OUTPUTS, 0, 0, maxX-1, maxY-4)
+	_, err := setView(g, OUTPUTS, 0, 0, maxX-1, maxY-4)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create outputs view:", err)
+		logError("Failed to create outputs view", "err", err)
 		return err
 	}
 
 	// Timer view.
-	_, err = g.SetView(TIMER, 0, maxY-3, TWIDTH, maxY-1)
+	_, err = setView(g, TIMER, 0, maxY-3, TWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create timer view:", err)
+		logError("Failed to create timer view", "err", err)
 		return err
 	}
 
 	// Position view.
-	_, err = g.SetView(POSITION, TWIDTH+1, maxY-3, PWIDTH, maxY-1)
+	_, err = setView(g, POSITION, TWIDTH+1, maxY-3, PWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create position view:", err)
+		logError("Failed to create position view", "err", err)
 		return err
 	}
 
 	// Status view.
-	_, err = g.SetView(STATUS, PWIDTH+1, maxY-3, SWIDTH, maxY-1)
+	_, err = setView(g, STATUS, PWIDTH+1, maxY-3, SWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create status view:", err)
+		logError("Failed to create status view", "err", err)
 		return err
 	}
 
 	// Maze Size view.
-	_, err = g.SetView(SIZE, SWIDTH+1, maxY-3, SZWIDTH, maxY-1)
+	_, err = setView(g, SIZE, SWIDTH+1, maxY-3, SZWIDTH, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create maze size view:", err)
+		logError("Failed to create maze size view", "err", err)
+		return err
+	}
+
+	// Maze Seed view.
+	_, err = setView(g, SEED, SZWIDTH+1, maxY-3, SDWIDTH, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to create maze seed view", "err", err)
 		return err
 	}
 
 	// Help view.
-	_, err = g.SetView(INFOS, SZWIDTH+1, maxY-3, maxX-1, maxY-1)
+	_, err = setView(g, INFOS, SDWIDTH+1, maxY-3, maxX-1, maxY-1)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to create infos view:", err)
+		logError("Failed to create infos view", "err", err)
 		return err
 	}
 
+	gm.followResize(g, maxX, maxY)
+	gm.followPlayer(g)
 	return nil
 }
 
-func quit(g *gocui.Gui, v *gocui.View) error {
-	close(exit)
-	return gocui.ErrQuit
-}
+// refreshOutputsTitle displays the modes enabled for next mazes
+// into the outputs view title.
+func refreshOutputsTitle(g *gocui.Gui) {
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		logError("Failed to get outputs view", "err", err)
+		return
+	}
 
-// keybindings binds multiple keys to views.
-func keybindings(g *gocui.Gui) error {
+	title := tr(" The Maze ")
+	if isMovesLimited {
+		title += tr("[Limited Moves] ")
+	}
 
-	// keys binding on global terminal itself.
-	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
-		return err
+	if isThemedWalls {
+		title += tr("[Themed Walls] ")
 	}
 
-	// navigate between views.
-	if err := g.SetKeybinding("", gocui.KeyTab, gocui.ModNone, nextView); err != nil {
-		return err
+	if isAutoRun {
+		title += tr("[Auto-Run] ")
 	}
 
-	// to display help details. We use Ctrl+D or F1.
-	// On unix-like platforms, we can also use Ctrl+H.
-	if runtime.GOOS != "windows" {
-		if err := g.SetKeybinding("", gocui.KeyCtrlH, gocui.ModNone, displayHelpView); err != nil {
-			return err
-		}
+	if isCheckpoints {
+		title += tr("[Checkpoints] ")
 	}
 
-	if err := g.SetKeybinding("", gocui.KeyF1, gocui.ModNone, displayHelpView); err != nil {
-		return err
+	if isBell {
+		title += tr("[Bell] ")
 	}
 
-	if err := g.SetKeybinding("", gocui.KeyCtrlD, gocui.ModNone, displayHelpView); err != nil {
-		return err
+	if isIceMode {
+		title += tr("[Ice] ")
 	}
 
-	// generate & display new maze when focus on outputs (maze zone).
-	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlN, gocui.ModNone, displayNewMaze); err != nil {
-		return err
+	if currentMinotaurMode != 0 {
+		title += trf("[Minotaur: %s] ", minotaurModes[currentMinotaurMode])
 	}
 
-	// edit current default maze settings (width and height).
-	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlE, gocui.ModNone, editMazeSize); err != nil {
-		return err
+	if isZoomed {
+		title += tr("[Zoom] ")
 	}
 
-	// when focused on outputs view, display help at H or h key press.
-	if err := g.SetKeybinding(OUTPUTS, 'H', gocui.ModNone, displayHelpView); err != nil {
-		return err
+	if currentWallStyle != 0 {
+		title += trf("[Walls: %s] ", wallStyles[currentWallStyle].name)
 	}
 
-	if err := g.SetKeybinding(OUTPUTS, 'h', gocui.ModNone, displayHelpView); err != nil {
-		return err
+	if currentColorScheme != 0 {
+		title += trf("[Colors: %s] ", scheme().name)
 	}
 
-	// display all previous saved sessions to load one of them as new maze game.
-	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlL, gocui.ModNone, displayExistingMaze); err != nil {
-		return err
+	if doorsPlacement != "center" {
+		title += trf("[Doors: %s] ", doorsPlacement)
 	}
 
-	return nil
+	if currentGenerationSpeed != 0 {
+		title += trf("[Generation: %s] ", generationSpeeds[currentGenerationSpeed].name)
+	}
+
+	if currentOpponentLevel != 0 {
+		title += trf("[Opponent: %s] ", opponentLevels[currentOpponentLevel].name)
+	}
+
+	ov.Title = title
 }
 
-// displayExistingMaze displays all saved maze sessions as a list
-// and allows to choose one to be loaded for replaying.
-func displayExistingMaze(g *gocui.Gui, v *gocui.View) error {
+// refreshTexts writes again the titles and the texts of the views
+// shown all along the game once the language changed.
+func (gm *Game) refreshTexts(g *gocui.Gui) {
+	for name, title := range barTitles {
+		if v, err := g.View(name); err == nil {
+			v.Title = tr(title)
+		}
+	}
 
-	if _, err := os.Stat("savedsessions"); errors.Is(err, os.ErrNotExist) {
-		log.Println("There is no saved maze sessions. No folder <savedsessions>")
-		return nil
+	if iv, err := g.View(INFOS); err == nil {
+		maxX, _ := g.Size()
+		clearView(iv)
+		fmt.Fprint(iv, center(tr(INFOS_TEXT), maxX-SDWIDTH-2, " "))
 	}
 
-	folder, err := os.Open("savedsessions")
-	if err != nil {
+	refreshOutputsTitle(g)
+	gm.displayStatus(g, shownStatus)
+}
+
+// quit closes the whole program. With an unfinished maze, it first
+// offers to save the session unless a question is already asked.
+func (gm *Game) quit(g *gocui.Gui, v *gocui.View) error {
+	if gm.isUnfinished() && !isConfirming(g) {
+		mv, err := g.View(MAZE)
+		if err != nil {
+			return err
+		}
+
+		back := MAZE
+		if v != nil {
+			back = v.Name()
+		}
+
+		return askConfirm(g, tr("Save this unfinished maze before quitting?"), back, func(g *gocui.Gui, yes bool) error {
+			if yes {
+				return gm.saveOrRecover(g, mv, back, gm.exitProgram)
+			}
+			return gm.exitProgram(g)
+		}, nil)
+	}
+
+	return gm.exitProgram(g)
+}
+
+// exitProgram stops all goroutines and ends the main loop.
+func (gm *Game) exitProgram(g *gocui.Gui) error {
+	gm.accountRun(false)
+	close(exit)
+	return gocui.ErrQuit
+}
+
+// keybindings binds the keys of the global and outputs view actions.
+// See keyActions for the default keys of each action.
+func keybindings(g *gocui.Gui) error {
+	if err := bindActions(g, "", false); err != nil {
 		return err
 	}
-	defer folder.Close()
-	filenames, err := folder.Readdirnames(0)
+
+	return bindActions(g, OUTPUTS, false)
+}
+
+// displayExistingMaze displays all saved maze sessions as a list
+// and allows to choose one to be loaded for replaying.
+func (gm *Game) displayExistingMaze(g *gocui.Gui, v *gocui.View) error {
+
+	entries, err := loadSessionEntries()
 	if err != nil {
-		return err
+		logError("Failed to list saved sessions", "err", err)
+		return nil
 	}
 
-	if len(filenames) == 0 {
+	if len(entries) == 0 {
+		logInfo("There is no saved maze sessions")
 		return nil
 	}
 
-	H := len(filenames) + 1
+	H := len(entries) + 1
 
-	// constructs the listview.
-	const name = "listview"
+	// constructs the listview with no search.
+	const name = SESSIONS_LIST
+	gm.sessions.query = ""
 	maxX, maxY := g.Size()
 
 	if (H + 4) >= maxY {
 		H = maxY - 4
 	}
 
-	listView, err := g.SetView(name, (maxX-21)/2, (maxY-H)/2, maxX/2+21, (maxY+H)/2)
+	listView, err := setView(g, name, (maxX-LISTWIDTH)/2, (maxY-H)/2, (maxX+LISTWIDTH)/2, (maxY+H)/2)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to display saved sessions listview:", err)
+		logError("Failed to display saved sessions listview", "err", err)
 		return err
 	}
 
-	listView.Title = " Select A Session To Replay "
+	listView.Title = tr(" Select A Session To Replay ")
 	listView.Frame = true
 	listView.FgColor = gocui.ColorYellow
 	listView.SelBgColor = gocui.ColorGreen
@@ -434,78 +678,116 @@ func displayExistingMaze(g *gocui.Gui, v *gocui.View) error {
 	listView.Editable = false
 
 	if _, err = g.SetCurrentView(name); err != nil {
-		log.Println("Failed to set focus on maze sessions listview:", err)
+		logError("Failed to set focus on maze sessions listview", "err", err)
 		return err
 	}
 
 	g.Cursor = true
 	listView.Highlight = true
 
-	if err = g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, sessionCursorUp); err != nil {
-		log.Println("Failed to bind Arrow Up key to sessions listview:", err)
+	if err = g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, gm.sessionCursorUp); err != nil {
+		logError("Failed to bind Arrow Up key to sessions listview", "err", err)
 		return err
 	}
 
-	if err = g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, sessionCursorDown); err != nil {
-		log.Println("Failed to bind Arrow Down key to sessions listview:", err)
+	if err = g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, gm.sessionCursorDown); err != nil {
+		logError("Failed to bind Arrow Down key to sessions listview", "err", err)
 		return err
 	}
 
-	if err = g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processEnterOnListView); err != nil {
-		log.Println("Failed to bind Enter key to sessions listview:", err)
+	// page and home/end keys to scroll through many sessions.
+	scrolls := map[gocui.Key]func(*gocui.Gui, *gocui.View) error{
+		gocui.KeyPgdn: gm.sessionPageDown,
+		gocui.KeyPgup: gm.sessionPageUp,
+		gocui.KeyHome: gm.sessionHome,
+		gocui.KeyEnd:  gm.sessionEnd,
+	}
+	for key, handler := range scrolls {
+		if err = g.SetKeybinding(name, key, gocui.ModNone, handler); err != nil {
+			logError("Failed to bind scroll keys to sessions listview", "err", err)
+			return err
+		}
+	}
+
+	if err = g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, gm.processEnterOnListView); err != nil {
+		logError("Failed to bind Enter key to sessions listview", "err", err)
 		return err
 	}
 
 	// Ctrl+Q and Escape keys to close the input box.
-	if err = g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeListView); err != nil {
-		log.Println("Failed to bind CtrlQ key to maze sessions listview:", err)
+	if err = g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, gm.closeListView); err != nil {
+		logError("Failed to bind CtrlQ key to maze sessions listview", "err", err)
 		return err
 	}
 
-	if err = g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeListView); err != nil {
-		log.Println("Failed to bind Esc key to maze sessions listview:", err)
+	if err = g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, gm.closeListView); err != nil {
+		logError("Failed to bind Esc key to maze sessions listview", "err", err)
 		return err
 	}
 
-	_, _ = g.SetViewOnTop(name)
-	listView.SetCursor(0, 0)
-	g.Cursor = false
+	// slash key to search the sessions as typed.
+	if err = g.SetKeybinding(name, '/', gocui.ModNone, gm.startSessionsSearch); err != nil {
+		logError("Failed to bind search key to maze sessions listview", "err", err)
+		return err
+	}
 
-	for i, filename := range filenames {
-		fmt.Fprint(listView, fmt.Sprintf(" [%02d] %s \n", i+1, strings.ReplaceAll(filename, ".", ":")))
+	// s and f keys to switch the order and the filter of the sessions.
+	for _, key := range []interface{}{'s', 'S'} {
+		if err = g.SetKeybinding(name, key, gocui.ModNone, gm.cycleSessionsSort); err != nil {
+			logError("Failed to bind sort keys to maze sessions listview", "err", err)
+			return err
+		}
 	}
 
-	return nil
-}
+	for _, key := range []interface{}{'f', 'F'} {
+		if err = g.SetKeybinding(name, key, gocui.ModNone, gm.cycleSessionsFilter); err != nil {
+			logError("Failed to bind filter keys to maze sessions listview", "err", err)
+			return err
+		}
+	}
 
-// sessionCursorDown moves cursor to (currentY + 1) position if there is data there.
-func sessionCursorDown(g *gocui.Gui, lv *gocui.View) error {
-	_, cy := lv.Cursor()
-	if l, _ := lv.Line(cy + 1); l != "" {
-		lv.MoveCursor(0, 1, false)
+	// r key to label the highlighted session.
+	for _, key := range []interface{}{'r', 'R'} {
+		if err = g.SetKeybinding(name, key, gocui.ModNone, gm.labelSelectedSession); err != nil {
+			logError("Failed to bind label keys to maze sessions listview", "err", err)
+			return err
+		}
 	}
-	return nil
-}
 
-// sessionCursorUp moves cursor to (currentY - 1) position if it is valid position.
-func sessionCursorUp(g *gocui.Gui, lv *gocui.View) error {
-	_, cy := lv.Cursor()
-	if cy-1 < 0 {
-		return nil
+	// d and Delete keys to remove the highlighted session.
+	for _, key := range []interface{}{'d', 'D', gocui.KeyDelete} {
+		if err = g.SetKeybinding(name, key, gocui.ModNone, gm.deleteSelectedSession); err != nil {
+			logError("Failed to bind delete keys to maze sessions listview", "err", err)
+			return err
+		}
+	}
+
+	// c key to clean up the sessions beyond the retention limits.
+	for _, key := range []interface{}{'c', 'C'} {
+		if err = g.SetKeybinding(name, key, gocui.ModNone, gm.cleanupSessions); err != nil {
+			logError("Failed to bind cleanup keys to maze sessions listview", "err", err)
+			return err
+		}
 	}
 
-	lv.MoveCursor(0, -1, false)
+	_, _ = g.SetViewOnTop(name)
+	setCursor(listView, 0, 0)
+	g.Cursor = false
+
+	gm.fillSessionsList(listView, entries)
+
 	return nil
 }
 
 // closeListView closes temporary maze sessions listview.
-func closeListView(g *gocui.Gui, lv *gocui.View) error {
+func (gm *Game) closeListView(g *gocui.Gui, lv *gocui.View) error {
 
-	lv.Clear()
+	clearView(lv)
+	_ = gm.closeSessionsSearch(g, lv, true)
 	g.Cursor = false
 	g.DeleteKeybindings(lv.Name())
 	if err := g.DeleteView(lv.Name()); err != nil {
-		log.Println("Failed to delete maze sessions listview:", err)
+		logError("Failed to delete maze sessions listview", "err", err)
 		return err
 	}
 
@@ -514,153 +796,112 @@ func closeListView(g *gocui.Gui, lv *gocui.View) error {
 	return nil
 }
 
-// loadMazeData reads the backup maze file content then
-// extracts the saved cursor position followed by the
-// maze data.
-func loadMazeData(path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	reader := bufio.NewReader(file)
-	data, err := reader.ReadString('\n')
-	if err != nil {
-		return err
-	}
-
-	data = strings.TrimSpace(data)
-	xy := strings.Fields(data)
-	if len(xy) != 2 {
-		return errors.New("wrong coordinates values")
-	}
-
-	if x, err := strconv.Atoi(xy[0]); err != nil {
-		return errors.New("wrong X coordinates value")
-	} else {
-		latestMazeCursorX = x
-	}
-
-	if y, err := strconv.Atoi(xy[1]); err != nil {
-		return errors.New("wrong Y coordinates value")
-	} else {
-		latestMazeCursorY = y
-	}
-
-	for {
-		data, err = reader.ReadString('\n')
-		currentMazeData.WriteString(data)
-		if err == io.EOF {
-			return nil
-		} else if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 // processEnterOnListView allows to choose an existing saved maze for playing.
-func processEnterOnListView(g *gocui.Gui, lv *gocui.View) error {
+func (gm *Game) processEnterOnListView(g *gocui.Gui, lv *gocui.View) error {
 
-	_, cy := lv.Cursor()
-	session, err := lv.Line(cy)
+	session, err := gm.selectedSession(lv)
 	if err != nil {
-		log.Println("Failed to read current focused session name:", err)
-		return nil
-	}
-	session = strings.ReplaceAll(strings.TrimSpace(session), ":", ".")
-	if len(strings.SplitN(session, " ", 2)) != 2 {
-		log.Println("Cannot accept current focused session name since invalid.")
-		return nil
-	}
-	session = strings.SplitN(session, " ", 2)[1]
-	// should not happen but for safety.
-	if len(session) == 0 {
-		log.Println("Cannot accept current focused session name since empty.")
+		logError("Cannot accept current focused session", "err", err)
 		return nil
 	}
 
-	if err := closeListView(g, lv); err != nil {
-		log.Println("Failed to close sessions listview:", err)
+	if err := gm.closeListView(g, lv); err != nil {
+		logError("Failed to close sessions listview", "err", err)
 		return err
 	}
 
-	currentMazeData.Reset()
-	currentMazeID = ""
+	return gm.load(g, session)
+}
 
-	if err := loadMazeData("savedsessions" + string(os.PathSeparator) + session); err != nil {
-		log.Println("Failed to load existing maze data:", err)
-		// we dont want to close the program because of an inexistent session file.
+// displayNewMaze triggers generation of new maze and display it.
+func (gm *Game) displayNewMaze(g *gocui.Gui, v *gocui.View) error {
+	if isGenerating {
 		return nil
 	}
 
-	// expected to be OUTPTUS view.
-	ov := g.CurrentView()
-	ov.Clear()
-
-	if err := createMazeView(g, ov); err != nil {
-		log.Println("Failed to load & display existing maze:", err)
-		return err
+	if startMazeFile != "" {
+		// the maze file given on the command line is played once.
+		path := startMazeFile
+		startMazeFile = ""
+		return gm.playMazeFile(g, path)
 	}
 
-	if mv := g.CurrentView(); mv != nil {
-		mv.SetCursor(latestMazeCursorX, latestMazeCursorY)
-		cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", latestMazeCursorX, latestMazeCursorY)
+	// the player picks how to play a maze larger than the screen.
+	if !gm.mazeFits(v.Size()) && activeMazeFit() == "ask" {
+		return gm.askMazeFit(g, v)
 	}
+	gm.limitMazeSize(v.Size())
 
-	currentMazeID = session
-
-	// reset and start timer.
-	resetTimer <- struct{}{}
-	stopTimer <- struct{}{}
-	return nil
-}
-
-// displayNewMaze triggers generation of new maze and display it.
-func displayNewMaze(g *gocui.Gui, v *gocui.View) error {
-
-	xLines, yLines := v.Size()
-
-	if 2*MAZEWIDTH >= xLines {
-		MAZEWIDTH = (xLines - 2) / 2
+	gm.clear()
+	gm.lastSave = time.Time{}
+	gm.seed = time.Now().UnixNano()
+	if isStartSeed {
+		// the seed given on the command line is used once.
+		gm.seed, isStartSeed = startSeed, false
+	}
+	maze, err := generateMaze(gm.width, gm.height, gm.seed)
+	if err != nil {
+		logError("Failed to generate new maze", "err", err)
+		return showErrorDialog(g, tr("The new maze cannot be generated."), err, OUTPUTS, retryAction(func(g *gocui.Gui) error {
+			return gm.displayNewMaze(g, v)
+		}), gm.quitAction())
 	}
+	gm.setMaze(maze)
 
-	if MAZEHEIGHT >= yLines {
-		MAZEHEIGHT = yLines - 2
+	clearView(v)
+	startNewMaze := func(g *gocui.Gui) error {
+		return gm.startGeneratedMaze(g, v)
+	}
+	if gm.animateGeneration(g, v, maze, startNewMaze) {
+		return nil
 	}
 
-	currentMazeData.Reset()
-	currentMazeID = ""
-	lastestSavingTime = time.Time{}
-	maze := createMaze(MAZEWIDTH, MAZEHEIGHT)
-	currentMazeData = formatMaze(maze, MAZEWIDTH, MAZEHEIGHT)
-	maze = nil
+	return startNewMaze(g)
+}
 
-	v.Clear()
+// startGeneratedMaze displays the maze just generated and starts the game.
+func (gm *Game) startGeneratedMaze(g *gocui.Gui, v *gocui.View) error {
+	gm.activateRules()
 
-	if err := createMazeView(g, v); err != nil {
-		log.Println("Failed to create & display new maze:", err)
+	if err := gm.createMazeView(g, v); err != nil {
+		logError("Failed to create & display new maze", "err", err)
 		return err
 	}
+	countGeneratedMaze()
 
 	// reset and start timer.
-	resetTimer <- struct{}{}
-	stopTimer <- struct{}{}
+	events.publish(gameEvent{kind: EVENT_START, maze: gm.maze})
 	return nil
 }
 
-// updateTimerView tracks elapsed time since maze is displayed.
-func updateTimerView(g *gocui.Gui) {
+// updateInfoViews displays the time played, the cursor position and the
+// status of the game <gm> following the events received from <ch>. A single
+// ticker refreshes the clock only while it runs so the goroutine sleeps when
+// idle. The views only show the latest position and status so the fast moves
+// never queue refreshes behind the inputs. The goroutine only runs the clock
+// of the game, which is safe to share, and leaves the rest to the main loop.
+func updateInfoViews(g *gocui.Gui, gm *Game, ch <-chan gameEvent) {
 	defer wg.Done()
-	stop, reset := true, false
-	secsElapsed, hrs, mins, secs := 0, 0, 0, 0
+	ticker := time.NewTicker(TIMER_REFRESH)
+	ticker.Stop()
+	ticking := false
+	refresh := &infoRefresh{gm: gm}
+
+	stop := func() {
+		gm.clock.Pause()
+		if ticking {
+			ticker.Stop()
+			ticking = false
+		}
+	}
 
-	timerView, err := g.View(TIMER)
-	if err != nil {
-		log.Println("Failed to get timer view for updating:", err)
-		return
+	start := func() {
+		if ticking {
+			return
+		}
+		gm.clock.Start()
+		ticker.Reset(TIMER_REFRESH)
+		ticking = true
 	}
 
 	for {
@@ -668,272 +909,286 @@ func updateTimerView(g *gocui.Gui) {
 		select {
 
 		case <-exit:
+			stop()
 			return
 
-		case <-stopTimer:
-			stop = !stop
-
-		case <-resetTimer:
-			secsElapsed = 0
-			reset = !reset
-			g.Update(func(g *gocui.Gui) error {
-				timerView.Clear()
-				fmt.Fprintf(timerView, " 00:00:00 ")
-				return nil
-			})
-
-		case <-time.After(1 * time.Second):
-			if stop {
+		case e := <-ch:
+			switch e.kind {
+			case EVENT_MOVE:
+				refresh.post(g, func() {
+					refresh.moved, refresh.x, refresh.y = true, e.x, e.y
+				})
 				continue
+			case EVENT_START:
+				// restored sessions carry the time already played.
+				gm.clock.Set(e.elapsed)
+				start()
+			case EVENT_RESUME:
+				start()
+			default:
+				stop()
 			}
 
-			g.Update(func(g *gocui.Gui) error {
-				secsElapsed++
-				hrs = int(secsElapsed / 3600)
-				mins = int(secsElapsed / 60)
-				secs = int(secsElapsed % 60)
-				timerView.Clear()
-				fmt.Fprintf(timerView, " %02d:%02d:%02d ", hrs, mins, secs)
-				return nil
+			refresh.post(g, func() {
+				refresh.status = &e
+				refresh.timer = refresh.timer || e.kind == EVENT_START
+			})
+
+		case <-ticker.C:
+			refresh.post(g, func() {
+				refresh.timer = true
 			})
 		}
 	}
 }
 
-// centers a given string within a width by padding.
-func center(s string, width int, fill string) string {
-	return strings.Repeat(fill, (width-len(s))/2) + s + strings.Repeat(fill, (width-len(s))/2)
+// infoRefresh holds the changes of the timer, the position and the status
+// views waiting for the main loop. The changes posted before the refresh
+// runs are merged so a burst of events costs a single refresh.
+type infoRefresh struct {
+	gm     *Game
+	mu     sync.Mutex
+	queued bool
+	timer  bool
+	moved  bool
+	x, y   int
+	status *gameEvent
 }
 
-// updatePositionView displays current cursor coordinates.
-func updatePositionView(g *gocui.Gui, pwidth int) {
-	defer wg.Done()
-	var pos string
-	positionView, err := g.View(POSITION)
-	if err != nil {
-		log.Println("Failed to get position view for updating:", err)
-		return
-	}
-
-	for {
+// post records the changes made by <apply> then queues a refresh
+// unless one is already waiting.
+func (r *infoRefresh) post(g *gocui.Gui, apply func()) {
+	r.mu.Lock()
+	apply()
+	queued := r.queued
+	r.queued = true
+	r.mu.Unlock()
 
-		select {
+	if !queued {
+		g.Update(r.flush)
+	}
+}
 
-		case <-exit:
-			return
+// flush displays the latest changes into the views.
+func (r *infoRefresh) flush(g *gocui.Gui) error {
+	r.mu.Lock()
+	timer, moved, x, y, status := r.timer, r.moved, r.x, r.y, r.status
+	r.timer, r.moved, r.status, r.queued = false, false, nil, false
+	r.mu.Unlock()
 
-		case pos = <-cursorPosition:
-
-			g.Update(func(g *gocui.Gui) error {
-				positionView.Clear()
-				fmt.Fprint(positionView, center(pos, pwidth, " "))
-				return nil
-			})
-		}
-
-		time.Sleep(10 * time.Millisecond)
+	if status != nil {
+		r.gm.displayStatus(g, *status)
+	}
+	if moved {
+		displayPosition(g, x, y)
 	}
+	if timer {
+		r.gm.displayTimer(g)
+	}
+	return nil
 }
 
-// updateStatusView displays current game status.
-func updateStatusView(g *gocui.Gui) {
-	defer wg.Done()
-	var sval uint8
-
-	statusView, err := g.View(STATUS)
+// displayTimer shows the time played on the game clock, or the remaining
+// time with a time limit which ends the round once over.
+func (gm *Game) displayTimer(g *gocui.Gui) {
+	timerView, err := g.View(TIMER)
 	if err != nil {
-		log.Println("Failed to get status view for updating:", err)
 		return
 	}
 
-	for {
-
-		select {
+	secs := gm.clock.Seconds()
+	if limit := atomic.LoadInt64(&gm.timeLimit); limit > 0 {
+		secs = limit - secs
+		if secs <= 0 {
+			secs = 0
+			// time is over so the round is lost.
+			if !gm.over && gm.maze != nil {
+				gm.endRound(g, false)
+			}
+		}
+	}
 
-		case <-exit:
-			return
+	clearView(timerView)
+	fmt.Fprint(timerView, " "+formatSeconds(secs)+" ")
+}
 
-		case sval = <-statusGame:
+// displayPosition shows the cursor coordinates (cx, cy) of the player.
+func displayPosition(g *gocui.Gui, cx, cy int) {
+	positionView, err := g.View(POSITION)
+	if err != nil {
+		return
+	}
 
-			g.Update(func(g *gocui.Gui) error {
-				statusView.Clear()
-				if sval == 1 {
-					fmt.Fprintf(statusView, ":: PAUSE")
-				} else if sval == 0 {
-					fmt.Fprintf(statusView, ":: READY")
-				} else if sval == 3 {
-					fmt.Fprintf(statusView, ":: ERROR")
-				}
+	clearView(positionView)
+	fmt.Fprint(positionView, center(fmt.Sprintf("(X:%d | Y:%d)", cx, cy), PWIDTH-TWIDTH-1, " "))
+}
 
-				return nil
-			})
-		}
+// displayStatus shows the game status following the event <e>.
+func (gm *Game) displayStatus(g *gocui.Gui, e gameEvent) {
+	statusView, err := g.View(STATUS)
+	if err != nil {
+		return
+	}
 
-		time.Sleep(1 * time.Second)
+	shownStatus = e
+	clearView(statusView)
+	switch e.kind {
+	case EVENT_PAUSE:
+		fmt.Fprint(statusView, ":: "+tr("PAUSE"))
+	case EVENT_START, EVENT_RESUME:
+		fmt.Fprint(statusView, ":: "+tr("READY"))
+	case EVENT_ERROR:
+		fmt.Fprint(statusView, ":: "+tr(errorReason(e.err)))
+	case EVENT_WIN:
+		fmt.Fprint(statusView, ":: "+trf("WON | SCORE %d | BUMPS %d", gm.lastScore, gm.run.collisions))
+	case EVENT_LOSE:
+		fmt.Fprint(statusView, ":: "+trf("LOST | BUMPS %d", gm.run.collisions))
 	}
 }
 
 // createMazeView displays a temporary box to contain the new generated maze.
-func createMazeView(g *gocui.Gui, v *gocui.View) error {
+func (gm *Game) createMazeView(g *gocui.Gui, v *gocui.View) error {
 
-	vx, vy := v.Size()
-	// maze view starting coordinates.
-	mx1 := (vx - (2*MAZEWIDTH + 2)) / 2
-	my1 := (vy - (MAZEHEIGHT + 2)) / 2
-	// maze view ending coordinates.
-	mx2 := mx1 + (2*MAZEWIDTH + 2)
-	my2 := my1 + (MAZEHEIGHT + 2)
+	// maze view coordinates centered into the outputs view. a large
+	// maze starts from its top left corner then scrolls to the player.
+	gm.scrollX, gm.scrollY = 0, 0
+	mx1, my1, mx2, my2 := gm.mazeViewPosition(v.Size())
 
-	mazeView, err := g.SetView(MAZE, mx1, my1, mx2, my2)
+	mazeView, err := setView(g, MAZE, mx1, my1, mx2, my2)
 	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to display maze view:", err)
+		logError("Failed to display maze view", "err", err)
 		return err
 	}
 
 	mazeView.Frame = false
-	mazeView.FgColor = gocui.ColorYellow
-	mazeView.BgColor = gocui.ColorBlack
-	mazeView.SelBgColor = gocui.ColorBlack
-	mazeView.SelFgColor = gocui.ColorYellow
+	gm.displayMazeSize(g)
+	gm.displayMazeSeed(g)
+	gm.theme = pickTheme(gm.seed)
+	mazeView.FgColor = gm.wallColor()
+	mazeView.BgColor = scheme().path
+	mazeView.SelBgColor = scheme().path
+	mazeView.SelFgColor = scheme().player
 
 	if _, err = g.SetCurrentView(MAZE); err != nil {
-		log.Println("Failed to set focus on maze view:", err)
+		logError("Failed to set focus on maze view", "err", err)
 		return err
 	}
 
 	_, _ = g.SetViewOnTop(MAZE)
+	raiseInfoViews(g)
 
 	if err = mazeKeybindings(g, MAZE); err != nil {
-		log.Println("Failed to bind keys to maze view:", err)
+		logError("Failed to bind keys to maze view", "err", err)
 		return err
 	}
 
-	// draw maze.
-	fmt.Fprint(mazeView, currentMazeData.String())
+	gm.lines = strings.Split(themeMaze(gm.data.String(), gm.theme), "\n")
+	gm.display = styleMazeLines(gm.lines, gm.data.String(), wallStyles[currentWallStyle])
 
 	// move cursor to maze entrance.
-	x, _ := mazeView.Size()
-	if err = mazeView.SetCursor(x/2+1, 0); err != nil {
-		log.Println("Failed to set cursor at middle of maze view:", err)
+	ex, ey := gm.entranceCursor(mazeView)
+	if err = gm.setMazeCursor(mazeView, ex, ey); err != nil {
+		logError("Failed to set cursor at middle of maze view", "err", err)
 		// just alert for error during setup.
-		statusGame <- 3
+		publishError(err)
 	}
 
-	g.Cursor = true
-	v.Frame = false
-
-	// update status and position.
-	isGamePaused = false
-	statusGame <- 0
-	cx, cy := v.Cursor()
-	cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", cx, cy)
-
-	t := time.Now()
-	currentMazeID = fmt.Sprintf("%02d-%02d-%02d %02dH.%02dM.%02dS", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
-
-	return nil
-}
-
-// mazeKeybindings binds multiple keys to maze view.
-func mazeKeybindings(g *gocui.Gui, name string) error {
-	var err error
-
-	if err = g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeMazeView); err != nil {
-		return err
-	}
-
-	if err = g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeMazeView); err != nil {
-		return err
-	}
-
-	if err = g.SetKeybinding(name, gocui.KeyCtrlP, gocui.ModNone, pauseResumeGame); err != nil {
-		return err
-	}
+	// draw maze.
+	gm.setupIce()
+	gm.invalidateMaze()
+	gm.drawMaze(mazeView)
+	gm.setupCheckpoints(g)
 
-	if err = g.SetKeybinding(name, gocui.KeySpace, gocui.ModNone, pauseResumeGame); err != nil {
-		return err
-	}
+	gm.showPlayer(g, true)
+	v.Frame = false
 
-	if err = g.SetKeybinding(name, gocui.KeyCtrlR, gocui.ModNone, resetGame); err != nil {
-		return err
-	}
+	// update position. the status follows the start of the round.
+	gm.paused = false
+	cx, cy := gm.mazeCursor(mazeView)
+	publishMove(cx, cy)
 
-	if err = g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, moveUp); err != nil {
-		return err
-	}
+	gm.startRun(g, mazeView)
 
-	if err = g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, moveDown); err != nil {
+	gm.over = false
+	if err = gm.setupMovesBudget(g, mazeView); err != nil {
+		logError("Failed to setup moves budget", "err", err)
 		return err
 	}
 
-	if err = g.SetKeybinding(name, gocui.KeyArrowLeft, gocui.ModNone, moveLeft); err != nil {
-		return err
-	}
+	t := time.Now()
+	gm.id = fmt.Sprintf("%02d-%02d-%02d %02dH.%02dM.%02dS", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+	gm.label = ""
 
-	if err = g.SetKeybinding(name, gocui.KeyArrowRight, gocui.ModNone, moveRight); err != nil {
-		return err
-	}
+	return nil
+}
 
-	if err = g.SetKeybinding(name, gocui.KeyCtrlS, gocui.ModNone, saveGame); err != nil {
-		return err
-	}
+// mazeKeybindings binds the keys of the maze actions to maze view.
+func mazeKeybindings(g *gocui.Gui, name string) error {
+	return bindActions(g, name, false)
+}
 
-	return nil
+// saveOrRecover saves the session of the maze view then calls <next>. When
+// saving fails, the player may retry, go on without saving or quit.
+func (gm *Game) saveOrRecover(g *gocui.Gui, mv *gocui.View, back string, next func(g *gocui.Gui) error) error {
+	err := gm.saveSession(mv)
+	if err == nil {
+		return next(g)
+	}
+
+	return showErrorDialog(g, tr("The game cannot be saved."), err, back,
+		retryAction(func(g *gocui.Gui) error {
+			return gm.saveOrRecover(g, mv, back, next)
+		}),
+		errorAction{'C', "Continue without saving", next},
+		errorAction{'Q', "Quit", gm.exitProgram},
+	)
 }
 
-// saveGame saves current maze on file disk inside savedsessions folder.
-// It generates (if not already created) a dedicated file named with the
-// current maze session id <currentMazeID>. The first line inside the file
-// contains the latest cursor coordinates (x, y) followed by the maze data.
-func saveGame(g *gocui.Gui, mv *gocui.View) error {
+// isUnfinished tells if a maze is being played and not yet over.
+func (gm *Game) isUnfinished() bool {
+	return gm.maze != nil && !gm.over
+}
 
-	// throttle saving action. could be done each <SAVING_INTERVAL_SECS>.
-	if (time.Since(lastestSavingTime)).Seconds() < SAVING_INTERVAL_SECS {
-		return nil
+// quitMazeView closes the maze view. With an unfinished maze, the game is
+// paused and the player is asked to save the session before closing it.
+func (gm *Game) quitMazeView(g *gocui.Gui, mv *gocui.View) error {
+	if !gm.isUnfinished() {
+		return gm.closeMazeView(g, mv)
 	}
 
-	if _, err := os.Stat("savedsessions"); errors.Is(err, os.ErrNotExist) {
-		// folder does not exist. we create it.
-		if err := os.Mkdir("savedsessions", 0755); err != nil {
-			log.Println("Failed to create savedsessions folder:", err)
-			return nil
+	// pause while asking and resume if the question is canceled.
+	pausedHere := !gm.paused
+	if pausedHere {
+		if err := gm.togglePause(g, mv); err != nil {
+			return err
 		}
 	}
 
-	fpath := "savedsessions" + string(os.PathSeparator) + currentMazeID
-	file, err := os.OpenFile(fpath, os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		log.Println("Failed to create savedsessions file:", err)
-		return nil
+	closeMaze := func(g *gocui.Gui) error {
+		return gm.closeMazeView(g, mv)
 	}
-	defer file.Close()
 
-	cx, cy := mv.Cursor()
-
-	_, err = fmt.Fprintln(file, cx, cy)
-	if err != nil {
-		log.Println("Failed to save cursor position in session file:", err)
-		return nil
-	}
-	_, err = fmt.Fprint(file, currentMazeData.String())
-	if err != nil {
-		log.Println("Failed to save maze data in session file:", err)
+	return askConfirm(g, tr("Save this unfinished maze before closing?"), MAZE, func(g *gocui.Gui, yes bool) error {
+		if yes {
+			return gm.saveOrRecover(g, mv, MAZE, closeMaze)
+		}
+		return closeMaze(g)
+	}, func(g *gocui.Gui) error {
+		if pausedHere {
+			return gm.togglePause(g, mv)
+		}
 		return nil
-	}
-
-	lastestSavingTime = time.Now()
-
-	return nil
+	})
 }
 
 // closeMazeView closes current temporary maze view.
-func closeMazeView(g *gocui.Gui, mv *gocui.View) error {
+func (gm *Game) closeMazeView(g *gocui.Gui, mv *gocui.View) error {
 
-	mv.Clear()
-	g.Cursor = false
+	clearView(mv)
+	gm.showPlayer(g, false)
 	g.DeleteKeybindings(mv.Name())
 	if err := g.DeleteView(mv.Name()); err != nil {
-		log.Println("Failed to delete maze view:", err)
+		logError("Failed to delete maze view", "err", err)
 		return err
 	}
 
@@ -941,14 +1196,25 @@ func closeMazeView(g *gocui.Gui, mv *gocui.View) error {
 		return err
 	}
 
-	// stop timer and update game status.
-	stopTimer <- struct{}{}
-	isGamePaused = false
-	statusGame <- 2
+	// suspend timer and update game status.
+	gm.paused = false
+	events.publish(gameEvent{kind: EVENT_CLEAR})
+
+	closeMovesView(g)
+	gm.closeDailyView(g)
+	gm.ghost.close(g)
+	gm.opponent.close(g)
+	gm.minotaur.marker.close(g)
+	gm.closeCheckpoints(g)
+	closeAnalysisView(g)
+	gm.closeCollisionFlash(g)
+	gm.closeVictory(g)
+	closeZoomView(g)
+	gm.accountRun(false)
+	gm.over = false
 
 	// clean stored maze data.
-	currentMazeData.Reset()
-	currentMazeID = ""
+	gm.clear()
 
 	return nil
 }
@@ -959,12 +1225,12 @@ func setFocusOnView(g *gocui.Gui, name string) error {
 	// move back the focus on the jobs list box.
 	v, err := g.SetCurrentView(name)
 	if err != nil {
-		log.Printf("Failed to set focus on %s view:", name, err)
+		logError("Failed to set focus", "view", name, "err", err)
 		return err
 	}
 
 	v.Frame = true
-	v.SetCursor(0, 0)
+	setCursor(v, 0, 0)
 	return nil
 }
 
@@ -975,7 +1241,7 @@ func nextView(g *gocui.Gui, v *gocui.View) error {
 
 	if cv == nil {
 		if _, err := g.SetCurrentView(OUTPUTS); err != nil {
-			log.Printf("Failed to set focus on default (%v) view: %v", OUTPUTS, err)
+			logError("Failed to set focus on default view", "view", OUTPUTS, "err", err)
 			return err
 		}
 		return nil
@@ -986,35 +1252,35 @@ func nextView(g *gocui.Gui, v *gocui.View) error {
 	case OUTPUTS:
 		// move the focus on Timer view.
 		if _, err := g.SetCurrentView(TIMER); err != nil {
-			log.Println("Failed to set focus on timer view:", err)
+			logError("Failed to set focus on timer view", "err", err)
 			return err
 		}
 
 	case TIMER:
 		// move the focus on Position view.
 		if _, err := g.SetCurrentView(POSITION); err != nil {
-			log.Println("Failed to set focus on position view:", err)
+			logError("Failed to set focus on position view", "err", err)
 			return err
 		}
 
 	case POSITION:
 		// move the focus on Status view.
 		if _, err := g.SetCurrentView(STATUS); err != nil {
-			log.Println("Failed to set focus on status view:", err)
+			logError("Failed to set focus on status view", "err", err)
 			return err
 		}
 
 	case STATUS:
 		// move the focus on Help view.
 		if _, err := g.SetCurrentView(INFOS); err != nil {
-			log.Println("Failed to set focus on help view:", err)
+			logError("Failed to set focus on help view", "err", err)
 			return err
 		}
 
 	case INFOS:
 		// move the focus on Outputs view.
 		if _, err := g.SetCurrentView(OUTPUTS); err != nil {
-			log.Println("Failed to set focus on maze view:", err)
+			logError("Failed to set focus on maze view", "err", err)
 			return err
 		}
 	}
@@ -1022,225 +1288,184 @@ func nextView(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
-// pauseResumeGame stops/resumes the timer and disable/enable navigation and reset keys.
-func pauseResumeGame(g *gocui.Gui, mv *gocui.View) error {
-	var err error
-
-	stopTimer <- struct{}{}
-
-	// inverse the game status.
-	isGamePaused = !isGamePaused
-
-	if isGamePaused {
-		statusGame <- 1
-		g.Cursor = false
-		// game paused so disable controls keys bindings.
-		for _, key := range []gocui.Key{gocui.KeyCtrlR, gocui.KeyArrowUp, gocui.KeyArrowDown, gocui.KeyArrowLeft, gocui.KeyArrowRight} {
-			if err = g.DeleteKeybinding(mv.Name(), key, gocui.ModNone); err != nil {
-				log.Printf("Failed to pause the game. error disabling key %v on maze view: %v", key, err)
-				return err
-			}
-		}
-
-		return nil
-	}
+// noWallBelow returns true if there is only space at position (x,y+1).
+func (gm *Game) noWallBelow(v *gocui.View) bool {
+	cx, cy := gm.mazeCursor(v)
+	return canGo(gm.maze, cx, cy, 0, 1)
+}
 
-	statusGame <- 0
-	g.Cursor = true
-	// game resumed so enable controls keys bindings.
-	if err = g.SetKeybinding(mv.Name(), gocui.KeyCtrlR, gocui.ModNone, resetGame); err != nil {
-		log.Println("Failed to resume the game. error enabling keys on maze view:", err)
-		return err
-	}
+// startRun starts recording a new run from the cursor position of
+// the maze view along with the ghost and the opponent if any.
+func (gm *Game) startRun(g *gocui.Gui, mv *gocui.View) {
+	gm.accountRun(false)
+	closeAnalysisView(g)
+	gm.invalidateCells(gm.run.trail)
+	gm.run.restart()
+	gm.recordMove(mv)
+	gm.scheduleAutosave()
+	gm.startGhost(g)
+	gm.startOpponent(g, mv)
+	gm.startMinotaur(g)
+}
 
-	if err = g.SetKeybinding(mv.Name(), gocui.KeyArrowDown, gocui.ModNone, moveDown); err != nil {
-		log.Println("Failed to resume the game. error enabling keys on maze view:", err)
-		return err
-	}
+// playerMoved notifies the features tracking the player once the cursor
+// moved. Reaching the exit wins the round while running out of moves loses it.
+func (gm *Game) playerMoved(g *gocui.Gui, v *gocui.View) {
+	gm.recordMove(v)
+	gm.autosave(v)
+	gm.countMove(g)
+	gm.refreshFog(g, v)
 
-	if err = g.SetKeybinding(mv.Name(), gocui.KeyArrowUp, gocui.ModNone, moveUp); err != nil {
-		log.Println("Failed to resume the game. error enabling keys on maze view:", err)
-		return err
+	if gm.catchPlayer(g, v) {
+		return
 	}
 
-	if err = g.SetKeybinding(mv.Name(), gocui.KeyArrowRight, gocui.ModNone, moveRight); err != nil {
-		log.Println("Failed to resume the game. error enabling keys on maze view:", err)
-		return err
+	// reaching a new checkpoint saves the session at that position.
+	if gm.reachCheckpoint(g, v) {
+		bellPickup.ring(g)
+		gm.saveSession(v)
+	}
+
+	if gm.isAtExit(v) {
+		x, _ := gm.entranceCursor(v)
+		gm.lastScore = runScore(len(gm.run.log)-1, gm.run.collisions, optimalMoves(gm.maze, x))
+		gm.endRound(g, true)
+		gm.celebrateVictory(g)
+		gm.displayAnalysisView(g)
+		gm.completeSession(v)
+		// a run helped by the solution is not a record.
+		if !gm.solutionRevealed {
+			gm.recordLeaderboardTime()
+			gm.recordDailyTime(g)
+			gm.saveBestRun()
+		}
+		return
 	}
 
-	if err = g.SetKeybinding(mv.Name(), gocui.KeyArrowLeft, gocui.ModNone, moveLeft); err != nil {
-		log.Println("Failed to resume the game. error enabling keys on maze view:", err)
-		return err
+	if gm.isOutOfMoves() {
+		gm.endRound(g, false)
 	}
-
-	return nil
 }
 
-// resetGame reinitialize the timer and move to entrance position.
-func resetGame(g *gocui.Gui, mv *gocui.View) error {
-	resetTimer <- struct{}{}
-	statusGame <- 0
-	x, _ := mv.Size()
-	g.Cursor = true
-	if err := mv.SetCursor(x/2+1, 0); err != nil {
-		log.Println("Failed to set cursor at middle of maze view:", err)
-		return err
-	}
-
-	cx, cy := mv.Cursor()
-	cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", cx, cy)
-	return nil
+// canMove tells if the player is still allowed to move.
+func (gm *Game) canMove() bool {
+	return !gm.over
 }
 
-// noWallBelow returns true if there is only space at position (x,y+1).
-func noWallBelow(v *gocui.View) bool {
-	cx, cy := v.Cursor()
-
-	// check for underscore-based south wall at current position.
-	// if there is any error, we notify user to quit the program.
-	l, err := v.Line(cy)
-	if err != nil {
-		log.Printf("Failed to check maze bottom direction (%d,%d). err: %v", cx, cy, err)
-		statusGame <- 3
+// isAtExit tells if the cursor of the maze view stands on the exit cell.
+func (gm *Game) isAtExit(mv *gocui.View) bool {
+	if gm.maze == nil {
 		return false
 	}
 
-	if l[cx] == '_' {
-		return false
-	}
+	cx, cy := gm.mazeCursor(mv)
+	_, out := mazeDoors(gm.maze)
+	x, y := cellCursor(out[0], out[1])
+	return cx == x && cy == y
+}
 
-	// check if we are still into the grid.
-	if (cy + 1) > MAZEHEIGHT {
-		return false
+// entranceCursor returns the cursor position on top line above the entrance.
+func (gm *Game) entranceCursor(mv *gocui.View) (int, int) {
+	if gm.maze == nil {
+		x, _ := mv.Size()
+		return x/2 + 1, 0
 	}
 
-	// check for pipe-based south wall at next position.
-	l, err = v.Line(cy + 1)
-	if err != nil {
-		log.Printf("Failed to check maze bottom direction (%d,%d). err: %v", cx, cy+1, err)
-		statusGame <- 3
-		return false
-	}
+	in, _ := mazeDoors(gm.maze)
+	return 2*in[0] + 1, 0
+}
 
-	if l[cx] == '|' {
-		return false
+// endRound stops the timer and flags the round as won or lost.
+func (gm *Game) endRound(g *gocui.Gui, won bool) {
+	gm.accountRun(won)
+	gm.over = true
+	gm.showPlayer(g, false)
+	if won {
+		events.publish(gameEvent{kind: EVENT_WIN})
+		return
 	}
-
-	return true
+	events.publish(gameEvent{kind: EVENT_LOSE})
 }
 
 // moveDown moves cursor to currentX, (currentY + 1) position if there is no wall there.
-func moveDown(g *gocui.Gui, v *gocui.View) error {
-	if v != nil && noWallBelow(v) == true {
-		v.MoveCursor(0, 1, false)
-		cx, cy := v.Cursor()
-		cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", cx, cy)
+func (gm *Game) moveDown(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && gm.canMove() && gm.noWallBelow(v) == true {
+		gm.moveMazeCursor(v, 0, 1)
+		cx, cy := gm.mazeCursor(v)
+		publishMove(cx, cy)
+		gm.playerMoved(g, v)
+		gm.slide(g, v, 0, 1)
+		gm.autoRun(g, v, 0, 1)
+	} else if v != nil && gm.canMove() {
+		gm.bumpWall(g, v, 0, 1)
 	}
 
 	return nil
 }
 
 // noWallAbove returns true if there is only space at position (x,y-1).
-func noWallAbove(v *gocui.View) bool {
-
-	// check if we are still into the grid.
-	cx, cy := v.Cursor()
-	if (cy - 1) < 0 {
-		return false
-	}
-
-	l, err := v.Line(cy - 1)
-	if err != nil {
-		log.Printf("Failed to check maze up direction (%d,%d). err: %v", cx, cy-1, err)
-		// signal/status to quit the program.
-		statusGame <- 3
-		return false
-	}
-
-	if l[cx] == '_' || l[cx] == '|' {
-		return false
-	}
-
-	return true
+func (gm *Game) noWallAbove(v *gocui.View) bool {
+	cx, cy := gm.mazeCursor(v)
+	return canGo(gm.maze, cx, cy, 0, -1)
 }
 
 // moveUp moves cursor to currentX, (currentY - 1) position if there is no wall there.
-func moveUp(g *gocui.Gui, v *gocui.View) error {
-	if v != nil && noWallAbove(v) == true {
-		v.MoveCursor(0, -1, false)
-		cx, cy := v.Cursor()
-		cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", cx, cy)
+func (gm *Game) moveUp(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && gm.canMove() && gm.noWallAbove(v) == true {
+		gm.moveMazeCursor(v, 0, -1)
+		cx, cy := gm.mazeCursor(v)
+		publishMove(cx, cy)
+		gm.playerMoved(g, v)
+		gm.slide(g, v, 0, -1)
+		gm.autoRun(g, v, 0, -1)
+	} else if v != nil && gm.canMove() {
+		gm.bumpWall(g, v, 0, -1)
 	}
 
 	return nil
 }
 
 // noWallOnRight returns true if there is no wall at position (x+1,y).
-func noWallOnRight(v *gocui.View) bool {
-
-	// check if we are still into the grid.
-	cx, cy := v.Cursor()
-	if (cx + 1) > (2*MAZEWIDTH)-1 {
-		return false
-	}
-
-	l, err := v.Line(cy)
-	if err != nil {
-		log.Printf("Failed to check maze up direction (%d,%d). err: %v", cx, cy, err)
-		// signal/status to quit the program.
-		statusGame <- 3
-		return false
-	}
-
-	if (cy == 0 && l[cx+1] == '_') || l[cx+1] == '|' {
-		return false
-	}
-
-	return true
+func (gm *Game) noWallOnRight(v *gocui.View) bool {
+	cx, cy := gm.mazeCursor(v)
+	return canGo(gm.maze, cx, cy, 1, 0)
 }
 
 // moveRight moves cursor to (currentX+1, currentY) position if there is no wall there.
-func moveRight(g *gocui.Gui, v *gocui.View) error {
-	if v != nil && noWallOnRight(v) == true {
+func (gm *Game) moveRight(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && gm.canMove() && gm.noWallOnRight(v) == true {
 		// there is data to next line.
-		v.MoveCursor(1, 0, false)
-		cx, cy := v.Cursor()
-		cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", cx, cy)
+		gm.moveMazeCursor(v, 1, 0)
+		cx, cy := gm.mazeCursor(v)
+		publishMove(cx, cy)
+		gm.playerMoved(g, v)
+		gm.slide(g, v, 1, 0)
+		gm.autoRun(g, v, 1, 0)
+	} else if v != nil && gm.canMove() {
+		gm.bumpWall(g, v, 1, 0)
 	}
 
 	return nil
 }
 
 // noWallOnLeft returns true if there is no wall at position (x-1,y).
-func noWallOnLeft(v *gocui.View) bool {
-
-	// check if we are still into the grid.
-	cx, cy := v.Cursor()
-	if (cx - 1) < 0 {
-		return false
-	}
-
-	l, err := v.Line(cy)
-	if err != nil {
-		log.Printf("Failed to check maze up direction (%d,%d). err: %v", cx, cy, err)
-		statusGame <- 3
-		return false
-	}
-
-	if (cy == 0 && l[cx-1] == '_') || l[cx-1] == '|' {
-		return false
-	}
-
-	return true
+func (gm *Game) noWallOnLeft(v *gocui.View) bool {
+	cx, cy := gm.mazeCursor(v)
+	return canGo(gm.maze, cx, cy, -1, 0)
 }
 
 // moveLeft moves cursor to (currentX-1, currentY) position if there is no wall there.
-func moveLeft(g *gocui.Gui, v *gocui.View) error {
-	if v != nil && noWallOnLeft(v) == true {
+func (gm *Game) moveLeft(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && gm.canMove() && gm.noWallOnLeft(v) == true {
 		// there is data to next line.
-		v.MoveCursor(-1, 0, false)
-		cx, cy := v.Cursor()
-		cursorPosition <- fmt.Sprintf("(X:%d | Y:%d)", cx, cy)
+		gm.moveMazeCursor(v, -1, 0)
+		cx, cy := gm.mazeCursor(v)
+		publishMove(cx, cy)
+		gm.playerMoved(g, v)
+		gm.slide(g, v, -1, 0)
+		gm.autoRun(g, v, -1, 0)
+	} else if v != nil && gm.canMove() {
+		gm.bumpWall(g, v, -1, 0)
 	}
 
 	return nil
@@ -1248,28 +1473,26 @@ func moveLeft(g *gocui.Gui, v *gocui.View) error {
 
 // displayHelpView displays help details. But save the current cursor
 // position in case the maze is displayed before. Then pause the game.
-func displayHelpView(g *gocui.Gui, cv *gocui.View) error {
+func (gm *Game) displayHelpView(g *gocui.Gui, cv *gocui.View) error {
 
 	if cv.Name() == MAZE {
-		latestMazeCursorX, latestMazeCursorY = cv.Cursor()
+		gm.cursorX, gm.cursorY = gm.mazeCursor(cv)
 
 		// try to pause the game if not yet done. If failure
 		// abort the process and flag status with <ERROR>.
-		if !isGamePaused {
-			if err := pauseResumeGame(g, cv); err != nil {
-				log.Println("Failed to pause the game before displaying help view:", err)
-				statusGame <- 3
+		if !gm.paused {
+			if err := gm.togglePause(g, cv); err != nil {
+				logError("Failed to pause the game before displaying help view", "err", err)
+				publishError(err)
 				return err
 			}
 		}
 	}
 
-	maxX, maxY := g.Size()
-
-	// construct the input box and position at the center of the screen.
-	if helpView, err := g.SetView(HELP, (maxX-HWIDTH)/2, (maxY-HHEIGHT)/2, maxX/2+HWIDTH, (maxY+HHEIGHT)/2); err != nil {
+	// construct the help box at the center of the screen then size it to its text.
+	if helpView, err := setView(g, HELP, 0, 0, HWIDTH+1, 2); err != nil {
 		if err != gocui.ErrUnknownView {
-			log.Println("Failed to create help view:", err)
+			logError("Failed to create help view", "err", err)
 			return err
 		}
 
@@ -1277,233 +1500,270 @@ func displayHelpView(g *gocui.Gui, cv *gocui.View) error {
 		helpView.SelBgColor = gocui.ColorBlack
 		helpView.SelFgColor = gocui.ColorYellow
 		helpView.Editable = false
-		helpView.Autoscroll = true
-		helpView.Wrap = true
-		helpView.Frame = false
+		helpView.Autoscroll = false
+		helpView.Wrap = false
 
 		if _, err := g.SetCurrentView(HELP); err != nil {
-			log.Println("Failed to set focus on help view:", err)
+			logError("Failed to set focus on help view", "err", err)
 			return err
 		}
 		g.Cursor = false
 
 		// bind Ctrl+Q and Escape and Ctrl+H and F1 and Ctrl+D keys to close the input box.
-		if err := g.SetKeybinding(HELP, gocui.KeyCtrlQ, gocui.ModNone, closeHelpView); err != nil {
-			log.Println("Failed to bind keys (CtrlQ) to help view:", err)
+		if err := g.SetKeybinding(HELP, gocui.KeyCtrlQ, gocui.ModNone, gm.closeHelpView); err != nil {
+			logError("Failed to bind keys (CtrlQ) to help view", "err", err)
 			return err
 		}
 
-		if runtime.GOOS != "windows" {
-			if err := g.SetKeybinding(HELP, gocui.KeyCtrlH, gocui.ModNone, closeHelpView); err != nil {
-				log.Println("Failed to bind keys (CtrlH) to help view:", err)
+		if !isInterceptedKey(gocui.KeyCtrlH) {
+			if err := g.SetKeybinding(HELP, gocui.KeyCtrlH, gocui.ModNone, gm.closeHelpView); err != nil {
+				logError("Failed to bind keys (CtrlH) to help view", "err", err)
 				return err
 			}
 		}
 
-		if err := g.SetKeybinding(HELP, gocui.KeyCtrlD, gocui.ModNone, closeHelpView); err != nil {
-			log.Println("Failed to bind keys (CtrlD) to close help view:", err)
+		if err := g.SetKeybinding(HELP, gocui.KeyCtrlD, gocui.ModNone, gm.closeHelpView); err != nil {
+			logError("Failed to bind keys (CtrlD) to close help view", "err", err)
+			return err
+		}
+
+		if err := g.SetKeybinding(HELP, gocui.KeyF1, gocui.ModNone, gm.closeHelpView); err != nil {
+			logError("Failed to bind keys (F1) to help view", "err", err)
 			return err
 		}
 
-		if err := g.SetKeybinding(HELP, gocui.KeyF1, gocui.ModNone, closeHelpView); err != nil {
-			log.Println("Failed to bind keys (F1) to help view:", err)
+		if err := g.SetKeybinding(HELP, gocui.KeyEsc, gocui.ModNone, gm.closeHelpView); err != nil {
+			logError("Failed to bind keys (Esc) to help view", "err", err)
 			return err
 		}
 
-		if err := g.SetKeybinding(HELP, gocui.KeyEsc, gocui.ModNone, closeHelpView); err != nil {
-			log.Println("Failed to bind keys (Esc) to help view:", err)
+		if err := g.SetKeybinding(HELP, 'H', gocui.ModNone, gm.closeHelpView); err != nil {
+			logError("Failed to bind keys (H) to help view", "err", err)
 			return err
 		}
 
-		if err := g.SetKeybinding(HELP, 'H', gocui.ModNone, closeHelpView); err != nil {
-			log.Println("Failed to bind keys (H) to help view:", err)
+		if err := g.SetKeybinding(HELP, 'h', gocui.ModNone, gm.closeHelpView); err != nil {
+			logError("Failed to bind keys (H) to help view", "err", err)
 			return err
 		}
 
-		if err := g.SetKeybinding(HELP, 'h', gocui.ModNone, closeHelpView); err != nil {
-			log.Println("Failed to bind keys (H) to help view:", err)
+		// arrows and pages scroll the help when it is taller than the screen.
+		scrolls := map[interface{}]func(g *gocui.Gui, hv *gocui.View) error{
+			gocui.KeyArrowUp:   scrollHelpView(-1, false),
+			gocui.KeyArrowDown: scrollHelpView(1, false),
+			gocui.KeyPgup:      scrollHelpView(-1, true),
+			gocui.KeyPgdn:      scrollHelpView(1, true),
+		}
+		for k, handler := range scrolls {
+			if err := g.SetKeybinding(HELP, k, gocui.ModNone, handler); err != nil {
+				logError("Failed to bind scroll keys to help view", "key", keyName(k), "err", err)
+				return err
+			}
+		}
+
+		fmt.Fprint(helpView, helpText())
+		fmt.Fprintf(helpView, "%s\n", center(appVersion(), HWIDTH-2, " "))
+		fmt.Fprintf(helpView, "%s\n", center(fmt.Sprintf("%s | %s", commit, buildDate), HWIDTH-2, " "))
+
+		if err := placeHelpView(g, helpView); err != nil {
+			logError("Failed to place help view", "err", err)
 			return err
 		}
+	}
+	return nil
+}
+
+// placeHelpView centers the help view <hv> and fits it to its text, or to
+// the terminal height when shorter with a title telling how to scroll.
+func placeHelpView(g *gocui.Gui, hv *gocui.View) error {
+	maxX, maxY := g.Size()
+	width := HWIDTH
+	for _, line := range strings.Split(helpText(), "\n") {
+		if n := textWidth(line) + 1; n > width {
+			width = n
+		}
+	}
+	if width > maxX-2 {
+		width = maxX - 2
+	}
+
+	lines := len(hv.BufferLines())
+	height := lines
+	if height > maxY-2 {
+		height = maxY - 2
+	}
+
+	x0, y0 := (maxX-width-2)/2, (maxY-height-2)/2
+	if _, err := setView(g, HELP, x0, y0, x0+width+1, y0+height+1); err != nil {
+		return err
+	}
 
-		fmt.Fprintf(helpView, helpDetails)
+	hv.Title = tr(" Help ")
+	if height < lines {
+		hv.Title = tr(" Help - arrows, PgUp, PgDn to scroll ")
+	}
 
+	// the origin is kept so the last line stays at the bottom once enlarged.
+	if _, oy := hv.Origin(); oy > lines-height {
+		return hv.SetOrigin(0, lines-height)
 	}
 	return nil
 }
 
+// scrollHelpView returns the handler scrolling the help view by <step>
+// lines or pages with <page>, within the bounds of its text.
+func scrollHelpView(step int, page bool) func(g *gocui.Gui, hv *gocui.View) error {
+	return func(g *gocui.Gui, hv *gocui.View) error {
+		_, sy := hv.Size()
+		if page {
+			step *= sy
+		}
+
+		_, oy := hv.Origin()
+		last := len(hv.BufferLines()) - sy
+		if last < 0 {
+			last = 0
+		}
+		return hv.SetOrigin(0, clamp(oy+step, 0, last))
+	}
+}
+
+// helpText returns the rows of the help window into the language in use.
+func helpText() string {
+	const separator = "-------------+----------------------------\n"
+	var b strings.Builder
+	b.WriteString(separator)
+	for _, entry := range helpEntries {
+		for i, line := range entry.lines {
+			keys := ""
+			if i == 0 {
+				keys = entry.keys
+			}
+			fmt.Fprintf(&b, "%s | %s\n", padText(keys, 12), tr(line))
+		}
+		b.WriteString(separator)
+	}
+	fmt.Fprintf(&b, "\n%s\n\n", center(" "+tr("Craft with ♥ by Jerome Amon")+" ", HWIDTH-2, ":"))
+	return b.String()
+}
+
 // closeHelpView closes help view then move the focus on
 // maze view in case it exists otherwise set it to output view.
-func closeHelpView(g *gocui.Gui, hv *gocui.View) error {
+func (gm *Game) closeHelpView(g *gocui.Gui, hv *gocui.View) error {
 
-	hv.Clear()
+	clearView(hv)
 	g.Cursor = false
 	g.DeleteKeybindings(hv.Name())
 	if err := g.DeleteView(hv.Name()); err != nil {
-		log.Println("Failed to delete help view:", err)
+		logError("Failed to delete help view", "err", err)
 		return err
 	}
 
 	if _, err := g.View(MAZE); err != gocui.ErrUnknownView {
 		mv, err := g.SetCurrentView(MAZE)
 		if err != nil {
-			log.Printf("Failed to set back focus on maze view:", err)
-			statusGame <- 3
+			logError("Failed to set back focus on maze view", "err", err)
+			publishError(err)
 			return err
 		}
 
 		mv.Frame = false
-		mv.SetCursor(latestMazeCursorX, latestMazeCursorY)
+		gm.setMazeCursor(mv, gm.cursorX, gm.cursorY)
 		g.Cursor = false
 		return nil
 	}
 
 	if err := setFocusOnView(g, OUTPUTS); err != nil {
-		log.Println("Failed to set back focus on outputs view:", err)
+		logError("Failed to set back focus on outputs view", "err", err)
 		return err
 	}
 
 	return nil
 }
 
-// editMazeSize provides a temporary input box to type wanted maze size (width & height).
-func editMazeSize(g *gocui.Gui, cv *gocui.View) error {
-	maxX, maxY := g.Size()
-	const name = "MazeSizeView"
-
-	inputView, err := g.SetView(name, maxX/2-20, maxY/2, maxX/2+20, maxY/2+2)
-	if err != nil && err != gocui.ErrUnknownView {
-		log.Println("Failed to display maze size input view:", err)
-		return err
-	}
-
-	inputView.Title = " Edit Maze Size (width x height) "
-	inputView.Frame = true
-	inputView.FgColor = gocui.ColorYellow
-	inputView.SelBgColor = gocui.ColorBlack
-	inputView.SelFgColor = gocui.ColorYellow
-	inputView.Editable = true
-
-	if _, err = g.SetCurrentView(name); err != nil {
-		log.Println("Failed to set focus on maze size input view:", err)
-		return err
-	}
-
-	g.Cursor = true
-	inputView.Highlight = true
-
-	if err = g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, copyMazeSizeInput); err != nil {
-		log.Println("Failed to bind Enter key to maze size input view:", err)
-		return err
-	}
-
-	// Ctrl+Q and Escape keys to close the input box.
-	if err = g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeMazeSizeInputView); err != nil {
-		log.Println("Failed to bind CtrlQ key to maze size input view:", err)
-		return err
-	}
-
-	if err = g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeMazeSizeInputView); err != nil {
-		log.Println("Failed to bind Esc key to maze size input view:", err)
-		return err
+// displayMazeSize refreshes the size view with the default maze size.
+func (gm *Game) displayMazeSize(g *gocui.Gui) {
+	sizeView, err := g.View(SIZE)
+	if err != nil {
+		logError("Failed to get size view for updating", "err", err)
+		return
 	}
 
-	_, _ = g.SetViewOnTop(name)
-
-	fmt.Fprintf(inputView, "%d x %d", MAZEWIDTH, MAZEHEIGHT)
-	inputView.SetCursor(len(fmt.Sprintf("%d x %d", MAZEWIDTH, MAZEHEIGHT)), 0)
-
-	return nil
+	clearView(sizeView)
+	fmt.Fprint(sizeView, center(gm.mazeSizeText(), SZWIDTH-SWIDTH-1, " "))
 }
 
-// closeMazeSizeInputView closes current temporary maze view.
-func closeMazeSizeInputView(g *gocui.Gui, iv *gocui.View) error {
-
-	iv.Clear()
-	g.Cursor = false
-	g.DeleteKeybindings(iv.Name())
-	if err := g.DeleteView(iv.Name()); err != nil {
-		log.Println("Failed to delete maze size input view:", err)
-		return err
+// mazeSizeText returns the default maze size followed by the difficulty
+// score of the current maze when it has that size and the way the mazes
+// larger than the screen are handled.
+func (gm *Game) mazeSizeText() string {
+	text := fmt.Sprintf("%d x %d", gm.width, gm.height)
+	if gm.maze != nil && gm.maze.Width() == gm.width && gm.maze.Height() == gm.height {
+		text += fmt.Sprintf(" | %d", gm.score.value)
 	}
-
-	_ = setFocusOnView(g, OUTPUTS)
-
-	return nil
+	return text + " | " + activeMazeFit()
 }
 
-// copyMazeSizeInput processes the value entered and set default maze size.
-func copyMazeSizeInput(g *gocui.Gui, iv *gocui.View) error {
-	var err error
-	var ov *gocui.View
-
-	iv.Rewind()
-	ov, err = g.View(OUTPUTS)
-	if err == gocui.ErrUnknownView {
-		log.Println("Failed to get outputs view:", err)
-	}
-
-	input := strings.TrimSpace(iv.Buffer())
-
-	if input != "" {
-		// data typed, add it.
-		x, y := ov.Size()
-		setupMazeSize(input, x, y)
-		g.Update(func(g *gocui.Gui) error {
-			sizeView, _ := g.View(SIZE)
-			sizeView.Clear()
-			fmt.Fprintf(sizeView, center(fmt.Sprintf("%d x %d", MAZEWIDTH, MAZEHEIGHT), SZWIDTH-SWIDTH-1, " "))
-			return nil
-		})
-
-	} else {
-		// no data entered, so go back.
-		editMazeSize(g, ov)
-		return nil
+// displayMazeSeed updates the seed view with the seed of the current
+// maze so it can be generated again with the -seed flag.
+func (gm *Game) displayMazeSeed(g *gocui.Gui) {
+	seedView, err := g.View(SEED)
+	if err != nil {
+		logError("Failed to get seed view for updating", "err", err)
+		return
 	}
 
-	iv.Clear()
+	clearView(seedView)
+	fmt.Fprint(seedView, center(strconv.FormatInt(gm.seed, 10), SDWIDTH-SZWIDTH-1, " "))
+}
 
-	// must delete keybindings before the view, or fatal error.
-	g.DeleteKeybindings(iv.Name())
-	if err = g.DeleteView(iv.Name()); err != nil {
-		log.Println("Failed to delete maze size input view:", err)
-		return err
+// setupMazeSize configures default maze size. It expects
+// to receive <width x height> format or a difficulty name.
+func (gm *Game) setupMazeSize(size string, x, y int) {
+	if p, found := findPreset(size); found {
+		applyPreset(p)
+		gm.width, gm.height = p.width, p.height
+		gm.limitMazeSize(x, y)
+		return
 	}
 
-	_ = setFocusOnView(g, OUTPUTS)
-
-	return nil
-}
-
-// setupMazeSize configures default maze size.
-// expect to receive <width x height> format.
-func setupMazeSize(size string, x, y int) {
 	s := strings.Split(size, "x")
 	if len(s) != 2 {
-		log.Println("Failed to setup maze size because no valid input data")
+		logWarn("Failed to setup maze size because no valid input data", "expected", "<width x height> or one of "+presetNames())
 		return
 	}
 
+	clearPreset()
+
 	w, err := strconv.Atoi(strings.TrimSpace(s[0]))
 	if err != nil {
-		log.Println("Failed to setup maze width size because no valid input data")
+		logWarn("Failed to setup maze width size because no valid input data")
 	} else if w > 15 {
-		MAZEWIDTH = w
+		gm.width = w
 	}
 
 	h, err := strconv.Atoi(strings.TrimSpace(s[1]))
 	if err != nil {
-		log.Println("Failed to setup maze height size because no valid input data")
+		logWarn("Failed to setup maze height size because no valid input data")
 	} else if h > 10 {
-		MAZEHEIGHT = h
+		gm.height = h
+	}
+
+	gm.limitMazeSize(x, y)
+}
+
+// limitMazeSize adjusts default maze size to the outputs view size (x, y)
+// unless the large mazes scroll.
+func (gm *Game) limitMazeSize(x, y int) {
+	if activeMazeFit() != "shrink" {
+		return
 	}
 
-	// limit to the output size.
-	if 2*MAZEWIDTH >= x {
-		MAZEWIDTH = (x - 2) / 2
+	if 2*gm.width >= x {
+		gm.width = (x - 2) / 2
 	}
 
-	if MAZEHEIGHT >= y {
-		MAZEHEIGHT = y - 2
+	if gm.height >= y {
+		gm.height = y - 2
 	}
 }
@@ -0,0 +1,126 @@
+package main
+
+// This file implements the ice tiles. Once on ice, the player keeps sliding
+// in the same direction until a wall stops it or the ice ends. Ice is only
+// laid on cells without junction so no way out can be slid past.
+
+import (
+	"math/bits"
+	"math/rand"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	// share of cells turned into ice and cells per ice region.
+	ICE_SHARE       = 0.15
+	ICE_REGION_SIZE = 6
+	// background of the ice cells.
+	ICE_COLOR = gocui.ColorCyan
+)
+
+var isIceMode = false
+
+// toggleIceMode switches the ice tiles on/off.
+// It applies to the next maze displayed.
+func toggleIceMode(g *gocui.Gui, v *gocui.View) error {
+	isIceMode = !isIceMode
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// isIceable tells if a cell may be turned into ice. Junctions and
+// doors are kept as they are where the player must be able to stop.
+func isIceable(maze *Grid, cell [2]int) bool {
+	in, out := mazeDoors(maze)
+	if cell == in || cell == out {
+		return false
+	}
+
+	return bits.OnesCount(uint(maze.At(cell[0], cell[1]))) <= 2
+}
+
+// placeIce lays regions of connected ice cells over the maze. The same
+// seed always produces the same ice regions for a given maze.
+func placeIce(maze *Grid, seed int64) map[[2]int]bool {
+	r := rand.New(rand.NewSource(seed))
+	height := maze.Height()
+	width := maze.Width()
+	target := int(float64(width*height) * ICE_SHARE)
+	ice := make(map[[2]int]bool, target)
+
+	for attempt := 0; attempt < width*height && len(ice) < target; attempt++ {
+		start := [2]int{r.Intn(width), r.Intn(height)}
+		if ice[start] || !isIceable(maze, start) {
+			continue
+		}
+
+		// grow the region through opened walls.
+		region := [][2]int{start}
+		ice[start] = true
+		for i := 0; i < len(region) && len(region) < ICE_REGION_SIZE; i++ {
+			cell := region[i]
+			for _, d := range [4]int{N, S, E, W} {
+				if (maze.At(cell[0], cell[1]) & d) == 0 {
+					continue
+				}
+
+				nX, nY := moveTo(cell[0], cell[1], d)
+				next := [2]int{nX, nY}
+				if nY < 0 || nY >= height || nX < 0 || nX >= width || ice[next] || !isIceable(maze, next) {
+					continue
+				}
+
+				ice[next] = true
+				region = append(region, next)
+				if len(region) == ICE_REGION_SIZE {
+					break
+				}
+			}
+		}
+	}
+
+	return ice
+}
+
+// setupIce places the ice of the current maze when the mode is on.
+func (gm *Game) setupIce() {
+	gm.iceCells = nil
+	if isIceMode && gm.maze != nil {
+		gm.iceCells = placeIce(gm.maze, gm.seed)
+	}
+}
+
+// isOnIce tells if the cursor position (cx, cy) of the maze view is on ice.
+// A wall column is on ice when the cell on either side of it is.
+func (gm *Game) isOnIce(cx, cy int) bool {
+	if len(gm.iceCells) == 0 || cy < 1 || cx < 1 {
+		return false
+	}
+
+	if cx%2 == 1 {
+		return gm.iceCells[[2]int{(cx - 1) / 2, cy - 1}]
+	}
+
+	return gm.iceCells[[2]int{cx/2 - 1, cy - 1}] || gm.iceCells[[2]int{cx / 2, cy - 1}]
+}
+
+// slide keeps moving the cursor toward (dx, dy) while it stands on ice.
+func (gm *Game) slide(g *gocui.Gui, v *gocui.View, dx, dy int) {
+	if len(gm.iceCells) == 0 {
+		return
+	}
+
+	// a slide cannot be longer than the maze itself.
+	for limit := cursorPositions(gm.maze); limit > 0 && gm.canMove(); limit-- {
+		cx, cy := gm.mazeCursor(v)
+		if !gm.isOnIce(cx, cy) || !gm.isWayFree(v, dx, dy) {
+			return
+		}
+
+		gm.moveMazeCursor(v, dx, dy)
+		cx, cy = gm.mazeCursor(v)
+		publishMove(cx, cy)
+		gm.playerMoved(g, v)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+// This file loads mazes authored outside of the game so they can be played.
+// Besides the formats read by the commands, it parses the block mazes drawn
+// with # characters for walls and spaces for passages.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	IMPORT_ERROR = "importerror"
+)
+
+var (
+	// maze file given on the command line to be played first.
+	startMazeFile string
+)
+
+// isBlockWall tells if the character <c> is a wall of a block maze.
+func isBlockWall(c rune) bool {
+	return c == '#' || c == '█'
+}
+
+// parseBlockMaze rebuilds a maze drawn with blocks where each cell and each
+// wall between two cells takes one character. The borders are made of walls
+// but the entrance on the top line and the exit on the bottom line.
+func parseBlockMaze(data string) (*Grid, error) {
+	var lines [][]rune
+	columns := 0
+	for _, line := range strings.Split(data, "\n") {
+		runes := []rune(strings.TrimRight(line, " \t"))
+		if len(runes) > columns {
+			columns = len(runes)
+		}
+		lines = append(lines, runes)
+	}
+
+	// editors may remove the trailing spaces so the lines are padded.
+	for i, line := range lines {
+		for len(line) < columns {
+			line = append(line, ' ')
+		}
+		lines[i] = line
+	}
+
+	if len(lines) < 3 || len(lines)%2 == 0 || columns < 3 || columns%2 == 0 {
+		return nil, fmt.Errorf("block maze of %d x %d characters must have odd sizes of at least 3", columns, len(lines))
+	}
+
+	width, height := (columns-1)/2, (len(lines)-1)/2
+	maze := NewGrid(width, height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if isBlockWall(lines[2*y+1][2*x+1]) {
+				return nil, fmt.Errorf("cell (%d,%d) of block maze is a wall", x, y)
+			}
+
+			if x+1 < width && !isBlockWall(lines[2*y+1][2*x+2]) {
+				maze.Open(x, y, W)
+				maze.Open(x+1, y, E)
+			}
+
+			if y+1 < height && !isBlockWall(lines[2*y+2][2*x+1]) {
+				maze.Open(x, y, S)
+				maze.Open(x, y+1, N)
+			}
+		}
+	}
+
+	entrance, exit := false, false
+	for x := 0; x < width; x++ {
+		if !isBlockWall(lines[0][2*x+1]) {
+			maze.Open(x, 0, N)
+			entrance = true
+		}
+
+		if !isBlockWall(lines[2*height][2*x+1]) {
+			maze.Open(x, height-1, S)
+			exit = true
+		}
+	}
+
+	if !entrance || !exit {
+		return nil, errors.New("block maze needs an entrance on its top line and an exit on its bottom line")
+	}
+
+	return maze, nil
+}
+
+// parseTextMaze reads a maze either drawn with blocks or in ascii format.
+func parseTextMaze(text string) (*Grid, error) {
+	text = strings.Trim(text, "\n")
+	if strings.ContainsAny(text, "#█") {
+		return parseBlockMaze(text)
+	}
+	return parseMaze(text)
+}
+
+// importMazeFile asks the path of a maze file to play.
+func (gm *Game) importMazeFile(g *gocui.Gui, v *gocui.View) error {
+	return askInput(g, " Maze File To Play ", "", OUTPUTS, gm.playMazeFile, nil)
+}
+
+// playMazeFile reads the maze of the file at <path> and displays it as a
+// new maze. Any file read by the commands can be played.
+func (gm *Game) playMazeFile(g *gocui.Gui, path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		return err
+	}
+
+	maze, err := readMazeFile(path)
+	if err == nil {
+		in, out := mazeDoors(maze)
+		if solveMaze(maze, in, out) == nil {
+			err = errors.New("no path leads from its entrance to its exit")
+		}
+	}
+
+	if err != nil {
+		logError("Failed to import maze file", "file", path, "err", err)
+		message := fmt.Sprintf("\n %s\n %v.\n\n %s", tr("The maze file cannot be played."), err, tr("Press Esc to close."))
+		return gm.displayPopupView(g, ov, IMPORT_ERROR, " Maze File ", message, SEWIDTH)
+	}
+
+	width, height := maze.Width(), maze.Height()
+	xLines, yLines := ov.Size()
+	if !givenMazePlays(width, height, xLines, yLines) {
+		err = errTooSmallFor(width, height)
+		logWarn("Cannot display imported maze", "file", path, "err", err)
+		message := fmt.Sprintf("\n %s\n %s.\n %s\n\n %s", tr("The maze file cannot be played."), capitalize(err.Error()), errorHint(err), tr("Press Esc to close."))
+		return gm.displayPopupView(g, ov, IMPORT_ERROR, " Maze File ", message, SEWIDTH)
+	}
+
+	logInfo("Imported maze file", "file", path, "size", fmt.Sprintf("%dx%d", width, height))
+	return gm.displayGivenMaze(g, ov, maze, 0)
+}
+
+// displayGivenMaze displays <maze> built from <seed> or from elsewhere
+// as a new maze and restarts the timer.
+func (gm *Game) displayGivenMaze(g *gocui.Gui, ov *gocui.View, maze *Grid, seed int64) error {
+	gm.setMaze(maze)
+	gm.lastSave = time.Time{}
+	gm.seed = seed
+	gm.displayMazeSize(g)
+
+	clearView(ov)
+	gm.activateRules()
+
+	if err := gm.createMazeView(g, ov); err != nil {
+		logError("Failed to create & display given maze", "err", err)
+		return err
+	}
+	countGeneratedMaze()
+
+	// reset and start timer.
+	events.publish(gameEvent{kind: EVENT_START, maze: gm.maze})
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+// This file provides the input overlay. It asks for a single line of text
+// at the center of the screen and hands the text typed over to a callback.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	INPUT  = "input"
+	IWIDTH = 44
+)
+
+// askInput displays an editable line titled with the translation of <title>
+// and filled with <initial>.
+// Enter calls onSubmit with the text typed while Escape and Ctrl+Q cancel
+// and call onCancel if not nil. The focus then goes back to the view <back>.
+func askInput(g *gocui.Gui, title, initial, back string, onSubmit func(g *gocui.Gui, text string) error, onCancel func(g *gocui.Gui) error) error {
+	maxX, maxY := g.Size()
+	inputView, err := setView(g, INPUT, (maxX-IWIDTH)/2, maxY/2-1, (maxX+IWIDTH)/2, maxY/2+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display input view", "err", err)
+		return err
+	}
+
+	inputView.Title = tr(title)
+	inputView.Frame = true
+	inputView.FgColor = gocui.ColorYellow
+	inputView.Editable = true
+	clearView(inputView)
+	fmt.Fprint(inputView, initial)
+
+	if _, err = g.SetCurrentView(INPUT); err != nil {
+		logError("Failed to set focus on input view", "err", err)
+		return err
+	}
+	_, _ = g.SetViewOnTop(INPUT)
+	setCursor(inputView, len(initial), 0)
+	g.Cursor = true
+
+	submit := func(g *gocui.Gui, v *gocui.View) error {
+		v.Rewind()
+		text := strings.TrimSpace(v.Buffer())
+		closeInputView(g, back)
+		return onSubmit(g, text)
+	}
+
+	cancel := func(g *gocui.Gui, v *gocui.View) error {
+		closeInputView(g, back)
+		if onCancel != nil {
+			return onCancel(g)
+		}
+		return nil
+	}
+
+	bindings := map[gocui.Key]func(g *gocui.Gui, v *gocui.View) error{
+		gocui.KeyEnter: submit,
+		gocui.KeyEsc:   cancel,
+		gocui.KeyCtrlQ: cancel,
+	}
+	for key, handler := range bindings {
+		if err = g.SetKeybinding(INPUT, key, gocui.ModNone, handler); err != nil {
+			logError("Failed to bind key to input view", "key", key, "err", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeInputView removes the input view and moves
+// the focus back to the view named <back> if it exists.
+func closeInputView(g *gocui.Gui, back string) {
+	g.Cursor = false
+	g.DeleteKeybindings(INPUT)
+	if err := g.DeleteView(INPUT); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete input view", "err", err)
+	}
+
+	if _, err := g.SetCurrentView(back); err != nil {
+		logError("Failed to set back focus", "view", back, "err", err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+// This file implements the JSON interchange format of the mazes. It is meant
+// to exchange mazes with other tools so it describes the walls of each cell
+// with the usual compass sides instead of the codes used by the grid.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// name and version of the interchange format. The version is raised
+	// on any change which older readers could not understand.
+	MAZE_FORMAT         = "gomazes-maze"
+	MAZE_FORMAT_VERSION = 1
+)
+
+// interchangeSides maps the compass sides of the interchange format to the
+// grid codes. The grid names the left side E and the right side W.
+var interchangeSides = []struct {
+	letter byte
+	code   int
+}{{'N', N}, {'E', W}, {'S', S}, {'W', E}}
+
+// mazeMetadata describes where a maze comes from. All fields are optional.
+type mazeMetadata struct {
+	Generator string `json:"generator,omitempty"`
+	Seed      *int64 `json:"seed,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Created   string `json:"created,omitempty"`
+}
+
+// mazeDocument is a maze in the interchange format. Cells are listed row by
+// row from the top and each one holds the letters of its closed sides (N is
+// up, E is right, S is down and W is left). The start cell is on the top row
+// with its north side opened and the exit cell on the bottom row with its
+// south side opened.
+type mazeDocument struct {
+	Format   string       `json:"format"`
+	Version  int          `json:"version"`
+	Width    int          `json:"width"`
+	Height   int          `json:"height"`
+	Start    cellReport   `json:"start"`
+	Exit     cellReport   `json:"exit"`
+	Cells    [][]string   `json:"cells"`
+	Metadata mazeMetadata `json:"metadata"`
+}
+
+// newMazeDocument describes <maze> in the interchange format.
+func newMazeDocument(maze *Grid, seed *int64, algorithm string) *mazeDocument {
+	in, out := mazeDoors(maze)
+	doc := &mazeDocument{
+		Format:  MAZE_FORMAT,
+		Version: MAZE_FORMAT_VERSION,
+		Width:   maze.Width(),
+		Height:  maze.Height(),
+		Start:   cellReport{in[0], in[1]},
+		Exit:    cellReport{out[0], out[1]},
+		Cells:   make([][]string, maze.Height()),
+		Metadata: mazeMetadata{
+			Generator: APP_NAME,
+			Seed:      seed,
+			Algorithm: algorithm,
+			Created:   time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	for y := range doc.Cells {
+		doc.Cells[y] = make([]string, maze.Width())
+		for x := range doc.Cells[y] {
+			cell := maze.At(x, y)
+			var walls []byte
+			for _, side := range interchangeSides {
+				if (cell & side.code) == 0 {
+					walls = append(walls, side.letter)
+				}
+			}
+			doc.Cells[y][x] = string(walls)
+		}
+	}
+
+	return doc
+}
+
+// grid rebuilds the maze of the document. It fails when the document is of
+// another format or newer version, when its size does not match its cells
+// or when two neighbor cells disagree about the wall between them.
+func (d *mazeDocument) grid() (*Grid, error) {
+	if d.Format != MAZE_FORMAT {
+		return nil, fmt.Errorf("unknown maze format %q", d.Format)
+	}
+
+	if d.Version < 1 || d.Version > MAZE_FORMAT_VERSION {
+		return nil, fmt.Errorf("unsupported maze format version %d", d.Version)
+	}
+
+	if d.Width <= 0 || d.Height <= 0 || len(d.Cells) != d.Height {
+		return nil, fmt.Errorf("maze size %d x %d does not match its %d rows", d.Width, d.Height, len(d.Cells))
+	}
+
+	maze := NewGrid(d.Width, d.Height)
+	for y, row := range d.Cells {
+		if len(row) != d.Width {
+			return nil, fmt.Errorf("wrong length of maze row %d", y)
+		}
+
+		for x, walls := range row {
+			cell := N | S | E | W
+			for _, letter := range strings.ToUpper(walls) {
+				found := false
+				for _, side := range interchangeSides {
+					if byte(letter) == side.letter {
+						cell &^= side.code
+						found = true
+					}
+				}
+				if !found {
+					return nil, fmt.Errorf("wrong side %q of maze cell (%d,%d)", letter, x, y)
+				}
+			}
+			maze.Set(x, y, cell)
+		}
+	}
+
+	// the sides on the border are all closed but the doors.
+	for y := 0; y < d.Height; y++ {
+		for x := 0; x < d.Width; x++ {
+			if y > 0 && ((maze.At(x, y)&N) != 0) != ((maze.At(x, y-1)&S) != 0) {
+				return nil, fmt.Errorf("maze cells (%d,%d) and (%d,%d) disagree about their wall", x, y, x, y-1)
+			}
+			if x > 0 && ((maze.At(x, y)&E) != 0) != ((maze.At(x-1, y)&W) != 0) {
+				return nil, fmt.Errorf("maze cells (%d,%d) and (%d,%d) disagree about their wall", x, y, x-1, y)
+			}
+		}
+	}
+
+	if d.Start.Y != 0 || d.Start.X < 0 || d.Start.X >= d.Width || (maze.At(d.Start.X, 0)&N) == 0 {
+		return nil, errors.New("maze start must be a top row cell with its north side opened")
+	}
+
+	if d.Exit.Y != d.Height-1 || d.Exit.X < 0 || d.Exit.X >= d.Width || (maze.At(d.Exit.X, d.Height-1)&S) == 0 {
+		return nil, errors.New("maze exit must be a bottom row cell with its south side opened")
+	}
+
+	return maze, nil
+}
+
+// decodeMazeDocument reads a maze from its interchange format <data>.
+func decodeMazeDocument(data []byte) (*Grid, error) {
+	var doc mazeDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc.grid()
+}
@@ -0,0 +1,830 @@
+package main
+
+// This file provides the configurable keybindings. Each action of the game
+// has default keys which the keys file of the config directory may replace,
+// so terminals where some Ctrl combinations clash (tmux, windows consoles)
+// stay usable. The defaults caught by the console get fallbacks (see
+// console.go). The keymap screen rebinds any action from inside the game.
+// The movement keys schemes add their keys to the moves besides the arrows.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	KEYMAP = "keymap"
+	// name of the file keeping the keybindings into the config directory.
+	KEYS_FILE = "keys"
+	KMWIDTH   = 60
+)
+
+// keyAction is an action bound to keys of the view named <view>. An
+// empty view means all views. The controls are disabled while paused.
+type keyAction struct {
+	view     string
+	name     string
+	label    string
+	defaults []interface{}
+	keys     []interface{}
+	handler  func(g *gocui.Gui, v *gocui.View) error
+	control  bool
+}
+
+// modKey is a key pressed with a modifier like Shift+Up or Alt+x.
+type modKey struct {
+	key interface{}
+	mod gocui.Modifier
+}
+
+// modifierNames are the names of the modifiers into the keys file.
+var modifierNames = []struct {
+	name string
+	mod  gocui.Modifier
+}{{"Shift", gocui.ModShift}, {"Alt", gocui.ModAlt}}
+
+// keyScheme holds the extra keys of the moves and travels, indexed like
+// the directions. The travels use the Shift letters of the moves. The keys
+// of the diagonal moves are reserved for the hex mazes to come: no action
+// moves that way yet but the maze actions cannot take them either.
+type keyScheme struct {
+	name           string
+	moves, travels [8][]interface{}
+}
+
+// namedKey is a special key with its name into the keys file.
+type namedKey struct {
+	name string
+	key  gocui.Key
+}
+
+var (
+	// actions of the game. set by setupKeyActions since
+	// their handlers act on the game played.
+	keyActions []*keyAction
+
+	// names of the special keys. the first name of a key is the one
+	// displayed, the others are aliases accepted into the keys file.
+	namedKeys = specialKeys()
+
+	// movement keys schemes.
+	keySchemes = []keyScheme{
+		{name: "arrows"},
+		{name: "hjkl", moves: [8][]interface{}{keyList('k'), keyList('j'), keyList('h'), keyList('l')},
+			travels: [8][]interface{}{keyList('K'), keyList('J'), keyList('H'), keyList('L')}},
+		{name: "wasd", moves: [8][]interface{}{keyList('w'), keyList('s'), keyList('a'), keyList('d')},
+			travels: [8][]interface{}{keyList('W'), keyList('S'), keyList('A'), keyList('D')}},
+		{name: "numpad", moves: [8][]interface{}{keyList('8'), keyList('2'), keyList('4'), keyList('6'),
+			keyList('7'), keyList('9'), keyList('1'), keyList('3')}},
+		// the left hand moves on esdf and reaches Space, Esc and the Ctrl
+		// keys of the game while the other hand stays free.
+		{name: "onehand", moves: [8][]interface{}{keyList('e'), keyList('d'), keyList('s'), keyList('f')},
+			travels: [8][]interface{}{keyList('E'), keyList('D'), keyList('S'), keyList('F')}},
+	}
+	currentKeyScheme = 0
+
+	// directions of the moves and travels of the schemes.
+	directions = []string{"up", "down", "left", "right", "up-left", "up-right", "down-left", "down-right"}
+
+	// set while the keymap screen waits for a key to bind
+	// and when that key is added to the keys of the action.
+	isCapturingKey = false
+	isAddingKey    = false
+)
+
+// setupKeyActions sets the actions of the game with the handlers acting
+// on the game <gm>.
+func setupKeyActions(gm *Game) {
+	help := keyList(gocui.KeyF1, gocui.KeyCtrlD, gocui.KeyCtrlH)
+
+	keyActions = []*keyAction{
+		{view: "", name: "quit", label: "Quit the game", defaults: keyList(gocui.KeyCtrlC), handler: gm.quit},
+		{view: "", name: "next-view", label: "Navigate between views", defaults: keyList(gocui.KeyTab), handler: nextView},
+		{view: "", name: "help", label: "Display the help", defaults: help, handler: gm.displayHelpView},
+
+		{view: OUTPUTS, name: "new-maze", label: "Play a new maze", defaults: keyList(gocui.KeyCtrlN), handler: gm.displayNewMaze},
+		{view: OUTPUTS, name: "settings", label: "Edit the settings", defaults: keyList(gocui.KeyCtrlE), handler: gm.displaySettingsView},
+		{view: OUTPUTS, name: "help", label: "Display the help", defaults: keyList('H', 'h'), handler: gm.displayHelpView},
+		{view: OUTPUTS, name: "daily", label: "Play the daily maze", defaults: keyList('T', 't'), handler: gm.displayDailyMaze},
+		{view: OUTPUTS, name: "generation", label: "Switch the generation speed", defaults: keyList('G', 'g'), handler: cycleGenerationSpeed},
+		{view: OUTPUTS, name: "themed-walls", label: "Toggle the themed walls", defaults: keyList('W', 'w'), handler: gm.toggleThemedWalls},
+		{view: OUTPUTS, name: "doors", label: "Switch the doors placement", defaults: keyList('P', 'p'), handler: cycleDoorsPlacement},
+		{view: OUTPUTS, name: "auto-run", label: "Toggle the auto-run", defaults: keyList('A', 'a'), handler: toggleAutoRun},
+		{view: OUTPUTS, name: "minotaur", label: "Switch the minotaur mode", defaults: keyList('E', 'e'), handler: cycleMinotaur},
+		{view: OUTPUTS, name: "ice", label: "Toggle the ice tiles", defaults: keyList('I', 'i'), handler: toggleIceMode},
+		{view: OUTPUTS, name: "bell", label: "Toggle the bell", defaults: keyList('B', 'b'), handler: toggleBell},
+		{view: OUTPUTS, name: "checkpoints", label: "Toggle the checkpoints", defaults: keyList('K', 'k'), handler: toggleCheckpoints},
+		{view: OUTPUTS, name: "opponent", label: "Switch the opponent level", defaults: keyList('O', 'o'), handler: cycleOpponent},
+		{view: OUTPUTS, name: "moves-limit", label: "Toggle the moves limit", defaults: keyList('M', 'm'), handler: toggleMovesLimit},
+		{view: OUTPUTS, name: "stats", label: "Display the statistics", defaults: keyList('S', 's'), handler: gm.displayStatsView},
+		{view: OUTPUTS, name: "share-code", label: "Play a share code", defaults: keyList('C', 'c'), handler: gm.importShareCode},
+		{view: OUTPUTS, name: "zoom", label: "Zoom in & out", defaults: keyList('Z', 'z'), handler: gm.toggleZoom},
+		{view: OUTPUTS, name: "walls", label: "Switch the walls style", defaults: keyList('U', 'u'), handler: gm.cycleWallStyle},
+		{view: OUTPUTS, name: "colors", label: "Switch the colors", defaults: keyList('V', 'v'), handler: gm.cycleColorScheme},
+		{view: OUTPUTS, name: "import", label: "Play a maze file", defaults: keyList('F', 'f'), handler: gm.importMazeFile},
+		{view: OUTPUTS, name: "leaderboard", label: "Display the best times", defaults: keyList(gocui.KeyCtrlB), handler: gm.displayLeaderboardView},
+		{view: OUTPUTS, name: "sessions", label: "Load a saved session", defaults: keyList(gocui.KeyCtrlL), handler: gm.displayExistingMaze},
+
+		{view: MAZE, name: "quit", label: "Leave the maze", defaults: keyList(gocui.KeyCtrlQ, gocui.KeyEsc), handler: gm.quitMazeView},
+		{view: MAZE, name: "pause", label: "Pause & resume", defaults: keyList(gocui.KeyCtrlP, gocui.KeySpace), handler: gm.togglePause},
+		{view: MAZE, name: "reset", label: "Reset the run", defaults: keyList(gocui.KeyCtrlR), handler: gm.reset, control: true},
+		{view: MAZE, name: "up", label: "Move up", defaults: keyList(gocui.KeyArrowUp), handler: gm.moveUp, control: true},
+		{view: MAZE, name: "down", label: "Move down", defaults: keyList(gocui.KeyArrowDown), handler: gm.moveDown, control: true},
+		{view: MAZE, name: "left", label: "Move left", defaults: keyList(gocui.KeyArrowLeft), handler: gm.moveLeft, control: true},
+		{view: MAZE, name: "right", label: "Move right", defaults: keyList(gocui.KeyArrowRight), handler: gm.moveRight, control: true},
+		{view: MAZE, name: "travel-up", label: "Travel up", defaults: keyList(modKey{gocui.KeyArrowUp, gocui.ModShift}, gocui.KeyPgup), handler: gm.travelUp, control: true},
+		{view: MAZE, name: "travel-down", label: "Travel down", defaults: keyList(modKey{gocui.KeyArrowDown, gocui.ModShift}, gocui.KeyPgdn), handler: gm.travelDown, control: true},
+		{view: MAZE, name: "travel-left", label: "Travel left", defaults: keyList(modKey{gocui.KeyArrowLeft, gocui.ModShift}, gocui.KeyHome), handler: gm.travelLeft, control: true},
+		{view: MAZE, name: "travel-right", label: "Travel right", defaults: keyList(modKey{gocui.KeyArrowRight, gocui.ModShift}, gocui.KeyEnd), handler: gm.travelRight, control: true},
+		{view: MAZE, name: "save", label: "Save the game", defaults: keyList(gocui.KeyCtrlS), handler: gm.save},
+		{view: MAZE, name: "export-run", label: "Export the run", defaults: keyList(gocui.KeyCtrlG), handler: gm.exportRun},
+		{view: MAZE, name: "export-png", label: "Export the maze as png", defaults: keyList(gocui.KeyCtrlO), handler: gm.exportMazePNG},
+		{view: MAZE, name: "leaderboard", label: "Display the best times", defaults: keyList(gocui.KeyCtrlB), handler: gm.displayLeaderboardView},
+		{view: MAZE, name: "share-code", label: "Display the share code", defaults: keyList(gocui.KeyCtrlK), handler: gm.displayShareCode},
+		{view: MAZE, name: "walls", label: "Switch the walls style", defaults: keyList(gocui.KeyCtrlU), handler: gm.cycleWallStyle},
+		{view: MAZE, name: "colors", label: "Switch the colors", defaults: keyList(gocui.KeyCtrlV), handler: gm.cycleColorScheme},
+		{view: MAZE, name: "structure", label: "Display the maze structure", defaults: keyList(gocui.KeyCtrlA), handler: gm.displayStructureView},
+		{view: MAZE, name: "solution", label: "Reveal the solution", defaults: keyList(gocui.KeyCtrlF), handler: gm.toggleSolution},
+		{view: MAZE, name: "zoom", label: "Zoom in & out", defaults: keyList('Z', 'z'), handler: gm.toggleZoom},
+	}
+
+	// the keys caught by the console get their fallbacks.
+	for _, a := range keyActions {
+		a.defaults = consoleKeys(a.defaults)
+		a.keys = a.defaults
+	}
+}
+
+// keyList returns its arguments as a list of keys.
+func keyList(k ...interface{}) []interface{} {
+	return k
+}
+
+// specialKeys returns the names of the special keys.
+func specialKeys() []namedKey {
+	names := []namedKey{
+		{"Tab", gocui.KeyTab}, {"Enter", gocui.KeyEnter}, {"Esc", gocui.KeyEsc},
+		{"Space", gocui.KeySpace}, {"Backspace", gocui.KeyBackspace2},
+		{"Up", gocui.KeyArrowUp}, {"Down", gocui.KeyArrowDown},
+		{"Left", gocui.KeyArrowLeft}, {"Right", gocui.KeyArrowRight},
+		{"Insert", gocui.KeyInsert}, {"Delete", gocui.KeyDelete},
+		{"Home", gocui.KeyHome}, {"End", gocui.KeyEnd},
+		{"PgUp", gocui.KeyPgup}, {"PgDn", gocui.KeyPgdn},
+	}
+
+	fkeys := []gocui.Key{gocui.KeyF1, gocui.KeyF2, gocui.KeyF3, gocui.KeyF4, gocui.KeyF5, gocui.KeyF6,
+		gocui.KeyF7, gocui.KeyF8, gocui.KeyF9, gocui.KeyF10, gocui.KeyF11, gocui.KeyF12}
+	for i, k := range fkeys {
+		names = append(names, namedKey{fmt.Sprintf("F%d", i+1), k})
+	}
+
+	// Ctrl+A to Ctrl+Z follow each other. Ctrl+I and Ctrl+M
+	// are the same keys as Tab and Enter so come as aliases.
+	for c := 'A'; c <= 'Z'; c++ {
+		names = append(names, namedKey{"Ctrl+" + string(c), gocui.KeyCtrlA + gocui.Key(c-'A')})
+	}
+	return names
+}
+
+// splitKey returns the key <k> and its modifier to bind.
+func splitKey(k interface{}) (interface{}, gocui.Modifier) {
+	if mk, ok := k.(modKey); ok {
+		return mk.key, mk.mod
+	}
+	return k, gocui.ModNone
+}
+
+// keyName returns the name of the key <k> into the keys file.
+func keyName(k interface{}) string {
+	switch k := k.(type) {
+	case modKey:
+		for _, m := range modifierNames {
+			if m.mod == k.mod {
+				return m.name + "+" + keyName(k.key)
+			}
+		}
+		return keyName(k.key)
+	case rune:
+		// the comma separates the keys of the file.
+		if k == ',' {
+			return "Comma"
+		}
+		return string(k)
+	case gocui.Key:
+		for _, n := range namedKeys {
+			if n.key == k {
+				return n.name
+			}
+		}
+		return fmt.Sprintf("Key(%d)", k)
+	}
+	return fmt.Sprint(k)
+}
+
+// keysNames returns the names of <keys> separated by commas.
+func keysNames(keys []interface{}) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, keyName(k))
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseKey reads a key of the keys file. A single character is
+// itself, the special keys and the modifiers are named without
+// regard to the case.
+func parseKey(s string) (interface{}, error) {
+	for _, m := range modifierNames {
+		if prefix := m.name + "+"; len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			k, err := parseKey(s[len(prefix):])
+			if err != nil {
+				return nil, err
+			}
+			return modKey{k, m.mod}, nil
+		}
+	}
+
+	if strings.EqualFold(s, "Comma") {
+		return ',', nil
+	}
+
+	if utf8.RuneCountInString(s) == 1 {
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, nil
+	}
+
+	for _, n := range namedKeys {
+		if strings.EqualFold(n.name, s) {
+			return n.key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", s)
+}
+
+// findKeyScheme returns the index of the movement keys scheme named <name>.
+func findKeyScheme(name string) (int, bool) {
+	for i, ks := range keySchemes {
+		if ks.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// schemeKeys returns the keys the movement keys scheme in use adds to <a>.
+func (a *keyAction) schemeKeys() []interface{} {
+	if a.view != MAZE {
+		return nil
+	}
+
+	ks := keySchemes[currentKeyScheme]
+	for i, d := range directions {
+		switch a.name {
+		case d:
+			return ks.moves[i]
+		case "travel-" + d:
+			return ks.travels[i]
+		}
+	}
+	return nil
+}
+
+// reservedDirection returns the direction without action the movement keys
+// scheme in use keeps the key <k> for when <a> applies into the maze view,
+// or an empty string when the key is free.
+func reservedDirection(a *keyAction, k interface{}) string {
+	if a.view != MAZE && a.view != "" {
+		return ""
+	}
+
+	for i, keys := range keySchemes[currentKeyScheme].moves {
+		if findAction(MAZE, directions[i]) != nil {
+			continue
+		}
+
+		for _, reserved := range keys {
+			if reserved == k {
+				return directions[i]
+			}
+		}
+	}
+	return ""
+}
+
+// boundKeys returns the keys of <a> with the ones of the movement keys scheme.
+func (a *keyAction) boundKeys() []interface{} {
+	return append(append([]interface{}{}, a.keys...), a.schemeKeys()...)
+}
+
+// keySchemeConflict returns a key of the movement keys scheme at index <i>
+// that another action already uses, with that action. The keys the scheme
+// reserves for the diagonal moves count as well.
+func keySchemeConflict(i int) (interface{}, *keyAction) {
+	previous := currentKeyScheme
+	currentKeyScheme = i
+	defer func() { currentKeyScheme = previous }()
+
+	for _, a := range keyActions {
+		for _, k := range a.schemeKeys() {
+			if other := conflictingAction(a, k); other != nil {
+				return k, other
+			}
+		}
+
+		for _, k := range a.keys {
+			if reservedDirection(a, k) != "" {
+				return k, a
+			}
+		}
+	}
+	return nil, nil
+}
+
+// switchKeyScheme uses the movement keys scheme at index <i>. The moves
+// are bound again when the maze is played. It refuses a scheme needing a
+// key already bound and tells whether the switch is done.
+func (gm *Game) switchKeyScheme(g *gocui.Gui, i int) bool {
+	if k, other := keySchemeConflict(i); other != nil {
+		notify("Cannot use the %s keys: %s already bound to %s", keySchemes[i].name, keyName(k), other.id())
+		return false
+	}
+
+	active := findAction(MAZE, "up").isActive(g, gm)
+	if active {
+		if err := unbindActions(g, MAZE, true); err != nil {
+			logError("Failed to unbind the moves keys", "err", err)
+		}
+	}
+
+	currentKeyScheme = i
+	if active {
+		if err := bindActions(g, MAZE, true); err != nil {
+			logError("Failed to bind the moves keys", "err", err)
+		}
+	}
+	return true
+}
+
+// scopeName returns the name of the view <view> into the keys file.
+func scopeName(view string) string {
+	if view == "" {
+		return "global"
+	}
+	return view
+}
+
+// id returns the name of the action into the keys file.
+func (a *keyAction) id() string {
+	return scopeName(a.view) + "." + a.name
+}
+
+// isActive tells whether the keys of the action are currently bound. The
+// maze actions only exist with the maze view and its controls when playing
+// the game <gm>.
+func (a *keyAction) isActive(g *gocui.Gui, gm *Game) bool {
+	if a.view != MAZE {
+		return true
+	}
+
+	if _, err := g.View(MAZE); err != nil {
+		return false
+	}
+	return !a.control || !gm.paused
+}
+
+// findAction returns the action named <name> of the view <view> if any.
+func findAction(view, name string) *keyAction {
+	for _, a := range keyActions {
+		if a.view == view && a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// actionKeys returns the keys of the action named <name> of the view <view>.
+func actionKeys(view, name string) []interface{} {
+	if a := findAction(view, name); a != nil {
+		return a.keys
+	}
+	return nil
+}
+
+// bindActions binds the keys of the actions of the view <view>. With
+// <controls> only, the others are skipped.
+func bindActions(g *gocui.Gui, view string, controls bool) error {
+	for _, a := range keyActions {
+		if a.view != view || (controls && !a.control) {
+			continue
+		}
+
+		for _, k := range a.boundKeys() {
+			key, mod := splitKey(k)
+			if err := g.SetKeybinding(view, key, mod, a.handler); err != nil {
+				logError("Failed to bind key to action", "key", keyName(k), "action", a.id(), "err", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unbindActions removes the keys of the actions of the view <view>. With
+// <controls> only, the others are kept.
+func unbindActions(g *gocui.Gui, view string, controls bool) error {
+	for _, a := range keyActions {
+		if a.view != view || (controls && !a.control) {
+			continue
+		}
+
+		for _, k := range a.boundKeys() {
+			key, mod := splitKey(k)
+			if err := g.DeleteKeybinding(view, key, mod); err != nil {
+				logError("Failed to unbind key of action", "key", keyName(k), "action", a.id(), "err", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// conflictingAction returns the action other than <a> which already
+// uses the key <k> where <a> applies. Global keys apply everywhere.
+func conflictingAction(a *keyAction, k interface{}) *keyAction {
+	for _, other := range keyActions {
+		if other == a || (other.view != a.view && other.view != "" && a.view != "") {
+			continue
+		}
+
+		for _, used := range other.boundKeys() {
+			if used == k {
+				return other
+			}
+		}
+	}
+	return nil
+}
+
+// rebindAction replaces the keys of the action <a> by <keys>.
+func (gm *Game) rebindAction(g *gocui.Gui, a *keyAction, keys []interface{}) error {
+	if a.isActive(g, gm) {
+		for _, k := range a.boundKeys() {
+			key, mod := splitKey(k)
+			if err := g.DeleteKeybinding(a.view, key, mod); err != nil {
+				logError("Failed to unbind key of action", "key", keyName(k), "action", a.id(), "err", err)
+			}
+		}
+	}
+
+	a.keys = keys
+	if !a.isActive(g, gm) {
+		return nil
+	}
+
+	for _, k := range a.boundKeys() {
+		key, mod := splitKey(k)
+		if err := g.SetKeybinding(a.view, key, mod, a.handler); err != nil {
+			logError("Failed to bind key to action", "key", keyName(k), "action", a.id(), "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// bindingsSetting returns the count of actions with custom keys.
+func bindingsSetting() string {
+	custom := 0
+	for _, a := range keyActions {
+		if keysNames(a.keys) != keysNames(a.defaults) {
+			custom++
+		}
+	}
+
+	if custom == 0 {
+		return "default"
+	}
+	return fmt.Sprintf("%d custom", custom)
+}
+
+// displayKeymapView opens the keymap screen over the settings screen.
+func (gm *Game) displayKeymapView(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	height := len(keyActions) + 1
+	if height > maxY-4 {
+		height = maxY - 4
+	}
+
+	keymapView, err := setView(g, KEYMAP, (maxX-KMWIDTH)/2, (maxY-height)/2, (maxX+KMWIDTH)/2, (maxY+height)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display keymap view", "err", err)
+		return err
+	}
+
+	keymapView.Frame = true
+	keymapView.FgColor = gocui.ColorYellow
+	keymapView.SelBgColor = gocui.ColorGreen
+	keymapView.SelFgColor = gocui.ColorBlack
+	keymapView.Highlight = true
+	keymapView.Editable = false
+	keymapView.Editor = gocui.EditorFunc(func(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gm.captureKey(g, v, key, ch, mod)
+	})
+
+	if _, err = g.SetCurrentView(KEYMAP); err != nil {
+		logError("Failed to set focus on keymap view", "err", err)
+		return err
+	}
+	_, _ = g.SetViewOnTop(KEYMAP)
+	g.Cursor = false
+	drawKeymap(g)
+
+	return gm.bindKeymapKeys(g)
+}
+
+// bindKeymapKeys binds the keys browsing the keymap screen.
+func (gm *Game) bindKeymapKeys(g *gocui.Gui) error {
+	bindings := map[gocui.Key]func(g *gocui.Gui, v *gocui.View) error{
+		gocui.KeyArrowUp:   moveKeymapCursor(-1),
+		gocui.KeyArrowDown: moveKeymapCursor(1),
+		gocui.KeyEnter:     startKeyCapture(false),
+		gocui.KeySpace:     startKeyCapture(true),
+		gocui.KeyDelete:    gm.resetActionKeys,
+		gocui.KeyEsc:       closeKeymapView,
+		gocui.KeyCtrlQ:     closeKeymapView,
+	}
+	for key, handler := range bindings {
+		if err := g.SetKeybinding(KEYMAP, key, gocui.ModNone, handler); err != nil {
+			logError("Failed to bind key to keymap view", "key", key, "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// drawKeymap writes the actions with their keys.
+func drawKeymap(g *gocui.Gui) {
+	keymapView, err := g.View(KEYMAP)
+	if err != nil {
+		return
+	}
+
+	keymapView.Title = tr(" Keys [Enter replace - Space add - Del reset - Esc close] ")
+	clearView(keymapView)
+	for _, a := range keyActions {
+		fmt.Fprintf(keymapView, " %s %s %s\n", padText(scopeName(a.view), 8), padText(tr(a.label), 28), keysNames(a.keys))
+	}
+}
+
+// selectedAction returns the action under the cursor of the keymap screen.
+func selectedAction(v *gocui.View) *keyAction {
+	_, oy := v.Origin()
+	_, cy := v.Cursor()
+	if i := oy + cy; i >= 0 && i < len(keyActions) {
+		return keyActions[i]
+	}
+	return nil
+}
+
+// moveKeymapCursor returns the handler selecting the action <step> lines
+// away. The view scrolls when the actions do not fit into the screen.
+func moveKeymapCursor(step int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		_, oy := v.Origin()
+		_, cy := v.Cursor()
+		_, sy := v.Size()
+
+		i := stepIndex(oy+cy, len(keyActions), step)
+		switch {
+		case i < oy:
+			oy = i
+		case i >= oy+sy:
+			oy = i - sy + 1
+		}
+
+		if err := v.SetOrigin(0, oy); err != nil {
+			return err
+		}
+		return setCursor(v, 0, i-oy)
+	}
+}
+
+// startKeyCapture returns the handler waiting for the key to bind to the
+// selected action. It replaces its keys or is added to them with <add>.
+// The keys of the screen and the global ones are released meanwhile.
+func startKeyCapture(add bool) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		a := selectedAction(v)
+		if a == nil {
+			return nil
+		}
+
+		g.DeleteKeybindings(KEYMAP)
+		if err := unbindActions(g, "", false); err != nil {
+			return err
+		}
+
+		isCapturingKey = true
+		isAddingKey = add
+		v.Editable = true
+		v.Title = trf(" Press the key to %s - Esc cancel ", strings.ToLower(tr(a.label)))
+		return nil
+	}
+}
+
+// captureKey receives the key pressed while capturing and binds
+// it to the selected action unless another one already uses it.
+func (gm *Game) captureKey(g *gocui.Gui, v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	if !isCapturingKey {
+		return
+	}
+
+	var k interface{} = key
+	if ch != 0 {
+		k = ch
+	}
+	if mod != gocui.ModNone {
+		k = modKey{k, mod}
+	}
+
+	isCapturingKey = false
+	v.Editable = false
+
+	if err := bindActions(g, "", false); err != nil {
+		logError("Failed to restore global keys after capture", "err", err)
+	}
+	if err := gm.bindKeymapKeys(g); err != nil {
+		logError("Failed to restore keymap keys after capture", "err", err)
+	}
+
+	a := selectedAction(v)
+	if a == nil || k == gocui.KeyEsc {
+		drawKeymap(g)
+		return
+	}
+
+	if other := conflictingAction(a, k); other != nil {
+		notify("%s already bound to %s", keyName(k), other.id())
+		drawKeymap(g)
+		return
+	}
+
+	if d := reservedDirection(a, k); d != "" {
+		notify("%s is kept for the %s moves", keyName(k), tr(d))
+		drawKeymap(g)
+		return
+	}
+
+	keys := []interface{}{k}
+	if isAddingKey {
+		keys = append(append([]interface{}{}, a.keys...), k)
+	}
+
+	if err := gm.rebindAction(g, a, keys); err != nil {
+		notify("Failed to bind %s: %v", keyName(k), err)
+	}
+	drawKeymap(g)
+}
+
+// resetActionKeys binds back the default keys of the selected action.
+func (gm *Game) resetActionKeys(g *gocui.Gui, v *gocui.View) error {
+	a := selectedAction(v)
+	if a == nil {
+		return nil
+	}
+
+	for _, k := range a.defaults {
+		if other := conflictingAction(a, k); other != nil {
+			notify("%s already bound to %s", keyName(k), other.id())
+			return nil
+		}
+	}
+
+	if err := gm.rebindAction(g, a, a.defaults); err != nil {
+		return err
+	}
+	drawKeymap(g)
+	return nil
+}
+
+// closeKeymapView closes the keymap screen, saves the
+// keybindings and moves back to the settings screen.
+func closeKeymapView(g *gocui.Gui, v *gocui.View) error {
+	g.DeleteKeybindings(KEYMAP)
+	if err := g.DeleteView(KEYMAP); err != nil {
+		logError("Failed to delete keymap view", "err", err)
+		return err
+	}
+
+	if err := saveKeybindings(); err != nil {
+		logError("Failed to save keys file", "err", err)
+		notify("Failed to save keys: %v", err)
+	}
+
+	drawSettings(g)
+	if _, err := g.SetCurrentView(SETTINGS); err != nil {
+		logError("Failed to set back focus on settings view", "err", err)
+		return err
+	}
+	return nil
+}
+
+// saveKeybindings writes the keys of each action as <action = keys> lines.
+func saveKeybindings() error {
+	var b strings.Builder
+	b.WriteString("# gomazes keybindings. keys are separated by commas, the comma key is named Comma.\n")
+	for _, a := range keyActions {
+		fmt.Fprintf(&b, "%s = %s\n", a.id(), keysNames(a.keys))
+	}
+	return os.WriteFile(configPath(KEYS_FILE), []byte(b.String()), 0644)
+}
+
+// loadKeybindings applies the keys file if any. Unknown actions
+// and keys are skipped so the actions keep their default keys, like
+// the entries binding a key another action uses.
+func loadKeybindings() error {
+	file, err := os.Open(configPath(KEYS_FILE))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	actions := make(map[string]*keyAction, len(keyActions))
+	for _, a := range keyActions {
+		actions[a.id()] = a
+	}
+
+	var loaded []*keyAction
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		a, found := actions[strings.TrimSpace(parts[0])]
+		if len(parts) != 2 || !found {
+			logWarn("Skipped line of keys file", "line", line)
+			continue
+		}
+
+		var keys []interface{}
+		for _, name := range strings.Split(parts[1], ",") {
+			k, err := parseKey(strings.TrimSpace(name))
+			if err != nil {
+				logWarn("Skipped key of keys file", "line", line, "err", err)
+				continue
+			}
+			keys = append(keys, k)
+		}
+
+		if len(keys) > 0 {
+			a.keys = keys
+			loaded = append(loaded, a)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	skipConflictingBindings(loaded)
+	return nil
+}
+
+// skipConflictingBindings gives back their default keys to the actions of
+// <loaded> bound to a key another action uses, like the keymap screen
+// refuses it. The entries are checked from the last one of the file so
+// the first entry binding a key keeps it. The defaults given back may
+// clash in turn so the check runs again until nothing changes.
+func skipConflictingBindings(loaded []*keyAction) {
+	for changed := true; changed; {
+		changed = false
+		for i := len(loaded) - 1; i >= 0; i-- {
+			a := loaded[i]
+			for _, k := range a.keys {
+				reason := reservedDirection(a, k)
+				if other := conflictingAction(a, k); other != nil {
+					reason = other.id()
+				}
+				if reason == "" {
+					continue
+				}
+
+				logWarn("Skipped conflicting entry of keys file", "action", a.id(), "key", keyName(k), "with", reason)
+				notify("Skipped %s of keys file: %s already bound to %s", a.id(), keyName(k), tr(reason))
+				a.keys = a.defaults
+				loaded = append(loaded[:i], loaded[i+1:]...)
+				changed = true
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+// This file maintains the local leaderboard. The best completion times are
+// kept per maze size (and difficulty preset if any) with the date of the run.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	LEADERBOARD         = "leaderboard"
+	LEADERBOARD_RECORDS = "leaderboard.txt"
+	// number of best times kept for each maze size.
+	LEADERBOARD_TOP = 5
+	LBWIDTH         = 40
+)
+
+// leaderboardEntry is a completion time with the day it was done.
+type leaderboardEntry struct {
+	date    string
+	elapsed time.Duration
+}
+
+// leaderboardKey identifies the current maze size and difficulty.
+func (gm *Game) leaderboardKey() string {
+	key := fmt.Sprintf("%dx%d", gm.width, gm.height)
+	if currentPreset != "" {
+		key += ":" + currentPreset
+	}
+	return key
+}
+
+// loadLeaderboard reads the best times of each maze size.
+// Each line of the file is made of <key> <date> <milliseconds>.
+func loadLeaderboard() (map[string][]leaderboardEntry, error) {
+	board := make(map[string][]leaderboardEntry)
+
+	file, err := os.Open(dataPath(LEADERBOARD_RECORDS))
+	if os.IsNotExist(err) {
+		return board, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+
+		ms, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		board[fields[0]] = append(board[fields[0]], leaderboardEntry{fields[1], time.Duration(ms) * time.Millisecond})
+	}
+
+	return board, scanner.Err()
+}
+
+// saveLeaderboard writes the best times of each maze size into its file.
+func saveLeaderboard(board map[string][]leaderboardEntry) error {
+	file, err := os.Create(dataPath(LEADERBOARD_RECORDS))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, key := range leaderboardKeys(board) {
+		for _, entry := range board[key] {
+			if _, err = fmt.Fprintln(file, key, entry.date, entry.elapsed.Milliseconds()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// leaderboardKeys returns the maze sizes of the leaderboard sorted.
+func leaderboardKeys(board map[string][]leaderboardEntry) []string {
+	keys := make([]string, 0, len(board))
+	for key := range board {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// recordLeaderboardTime adds the time played on the completed maze into
+// the leaderboard when it belongs to the best times of its maze size.
+func (gm *Game) recordLeaderboardTime() {
+	board, err := loadLeaderboard()
+	if err != nil {
+		logError("Failed to load leaderboard", "err", err)
+		return
+	}
+
+	key := gm.leaderboardKey()
+	entries := append(board[key], leaderboardEntry{time.Now().Format("2006-01-02"), gm.clock.Elapsed()})
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].elapsed < entries[j].elapsed
+	})
+	if len(entries) > LEADERBOARD_TOP {
+		entries = entries[:LEADERBOARD_TOP]
+	}
+	board[key] = entries
+
+	if err = saveLeaderboard(board); err != nil {
+		logError("Failed to save leaderboard", "err", err)
+	}
+}
+
+// formatLeaderboard returns the leaderboard lines to display.
+func formatLeaderboard(board map[string][]leaderboardEntry) string {
+	if len(board) == 0 {
+		return "\n " + tr("No completed maze yet.")
+	}
+
+	var lines strings.Builder
+	for _, key := range leaderboardKeys(board) {
+		fmt.Fprintf(&lines, "\n %s\n", key)
+		for i, entry := range board[key] {
+			tenths := (entry.elapsed % time.Second) / (100 * time.Millisecond)
+			fmt.Fprintf(&lines, "   %d. %s.%d   %s\n", i+1, formatSeconds(int64(entry.elapsed/time.Second)), tenths, entry.date)
+		}
+	}
+
+	return lines.String()
+}
+
+// displayLeaderboardView displays the best times of each maze size
+// at the center of the screen. The game is paused while displayed.
+func (gm *Game) displayLeaderboardView(g *gocui.Gui, cv *gocui.View) error {
+	board, err := loadLeaderboard()
+	if err != nil {
+		logError("Failed to load leaderboard", "err", err)
+		return nil
+	}
+
+	return gm.displayPopupView(g, cv, LEADERBOARD, " Best Times ", formatLeaderboard(board), LBWIDTH, actionKeys(cv.Name(), "leaderboard")...)
+}
@@ -0,0 +1,184 @@
+package main
+
+// This file translates the texts of the game screens. The texts are written
+// in english into the code and looked up into the catalog of the language in
+// use, so a missing translation shows the english text. The catalogs come
+// with the program (see locale_fr.go) and from the <lang>.json files of the
+// locales folder of the config directory, which complete or replace them so
+// anyone can translate the game without building it. The language is picked
+// with the -lang flag or the settings, else from the locale environment.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	// language of the texts written into the code.
+	DEFAULT_LOCALE = "en"
+	// folder of the config directory holding the translations files.
+	LOCALES_FOLDER = "locales"
+)
+
+// messageCatalog maps the english texts to their translation.
+type messageCatalog map[string]string
+
+var (
+	// catalogs of each language by their ISO 639-1 code.
+	locales = map[string]messageCatalog{DEFAULT_LOCALE: {}}
+	// language picked: auto for the one of the environment.
+	localeChoice = "auto"
+	// language in use and its catalog.
+	currentLocale = DEFAULT_LOCALE
+	catalog       messageCatalog
+)
+
+// registerLocale adds the texts of <messages> to the catalog of the language <name>.
+func registerLocale(name string, messages messageCatalog) {
+	c, found := locales[name]
+	if !found {
+		c = make(messageCatalog, len(messages))
+		locales[name] = c
+	}
+	for text, translation := range messages {
+		c[text] = translation
+	}
+}
+
+// localeNames returns the sorted names of the languages available.
+func localeNames() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isLocale tells if <name> is auto or an available language.
+func isLocale(name string) bool {
+	_, found := locales[name]
+	return found || name == "auto"
+}
+
+// envLocale returns the language of the locale environment variables
+// like fr_FR.UTF-8, or the default one when it is not available.
+func envLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+
+		name := strings.ToLower(value)
+		if i := strings.IndexAny(name, "_.@-"); i >= 0 {
+			name = name[:i]
+		}
+		if _, found := locales[name]; found {
+			return name
+		}
+		// the first variable set decides like for the libc.
+		break
+	}
+	return DEFAULT_LOCALE
+}
+
+// setLocale switches to the language <name>, auto for the one of the
+// environment. It tells if the language is available.
+func setLocale(name string) bool {
+	if !isLocale(name) {
+		return false
+	}
+
+	localeChoice, currentLocale = name, name
+	if name == "auto" {
+		currentLocale = envLocale()
+	}
+	catalog = locales[currentLocale]
+	logDebug("Switched language", "choice", localeChoice, "locale", currentLocale)
+	return true
+}
+
+// tr returns the translation of the english <text> into the language in use.
+func tr(text string) string {
+	if translation, found := catalog[text]; found && translation != "" {
+		return translation
+	}
+	return text
+}
+
+// trf formats <args> with the translation of the english <format>.
+func trf(format string, args ...interface{}) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// loadLocaleFiles reads the translations files of the locales folder.
+// Each <lang>.json file maps the english texts to their translation.
+func loadLocaleFiles() error {
+	paths, err := filepath.Glob(filepath.Join(configPath(LOCALES_FOLDER), "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		messages := make(messageCatalog)
+		if err = json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".json"))
+		registerLocale(name, messages)
+		logInfo("Loaded translations file", "locale", name, "texts", len(messages))
+	}
+	return nil
+}
+
+// formatFields returns the lines of the <fields> made of a label and its
+// value. The labels are translated and padded so the values line up.
+func formatFields(fields ...[2]string) []string {
+	width := 0
+	for _, f := range fields {
+		if n := textWidth(tr(f[0])); n > width {
+			width = n
+		}
+	}
+
+	lines := make([]string, len(fields))
+	for i, f := range fields {
+		lines[i] = " " + padText(tr(f[0]), width) + " : " + f[1]
+	}
+	return lines
+}
+
+// changeLocale switches the language from the settings.
+func (gm *Game) changeLocale(g *gocui.Gui, step int) {
+	names := append([]string{"auto"}, localeNames()...)
+	i := 0
+	for j, name := range names {
+		if name == localeChoice {
+			i = j
+		}
+	}
+
+	setLocale(names[stepIndex(i, len(names), step)])
+	gm.refreshTexts(g)
+}
+
+// localeSetting returns the language picked as shown into the settings.
+func localeSetting() string {
+	if localeChoice == "auto" {
+		return "auto (" + currentLocale + ")"
+	}
+	return localeChoice
+}
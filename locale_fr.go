@@ -0,0 +1,262 @@
+package main
+
+// This file holds the french translations of the game screens. It is the
+// reference for the translators: a <lang>.json file of the locales folder
+// mapping the same english texts adds a language without building the game.
+
+func init() {
+	registerLocale("fr", messageCatalog{
+		// views and bottom bar.
+		" The Maze ":           " Le Labyrinthe ",
+		" Timer ":              " Chrono ",
+		" Position ":           " Position ",
+		" Status ":             " Statut ",
+		" Size | Score | Fit ": " Taille | Score | Ajust. ",
+		" Seed ":               " Graine ",
+		"F1 or CTRL+D [Display Help] - CTRL+N [Play New Maze] - CTRL+C [Exit Game]": "F1 ou CTRL+D [Aide] - CTRL+N [Nouveau Labyrinthe] - CTRL+C [Quitter]",
+		"[Limited Moves] ":  "[Coups Limités] ",
+		"[Themed Walls] ":   "[Murs Décorés] ",
+		"[Auto-Run] ":       "[Course Auto] ",
+		"[Checkpoints] ":    "[Étapes] ",
+		"[Bell] ":           "[Bip] ",
+		"[Ice] ":            "[Glace] ",
+		"[Minotaur: %s] ":   "[Minotaure : %s] ",
+		"[Zoom] ":           "[Zoom] ",
+		"[Walls: %s] ":      "[Murs : %s] ",
+		"[Colors: %s] ":     "[Couleurs : %s] ",
+		"[Doors: %s] ":      "[Portes : %s] ",
+		"[Generation: %s] ": "[Génération : %s] ",
+		"[Opponent: %s] ":   "[Adversaire : %s] ",
+		"MOVES LEFT: %d/%d": "COUPS RESTANTS : %d/%d",
+		"%s BEST %s %s":     "%s RECORD %s %s",
+		"NEW!":              "NOUVEAU !",
+
+		// status.
+		"PAUSE":                     "PAUSE",
+		"READY":                     "PRÊT",
+		"WON | SCORE %d | BUMPS %d": "GAGNÉ | SCORE %d | CHOCS %d",
+		"LOST | BUMPS %d":           "PERDU | CHOCS %d",
+		"ERR SAVE":                  "ERR SAUV",
+		"ERR VERS":                  "ERR VERS",
+		"ERR SIZE":                  "ERR TAIL",
+		"ERR MAZE":                  "ERR LABY",
+		"ERROR":                     "ERREUR",
+
+		// help.
+		" Help ":                                " Aide ",
+		" Help - arrows, PgUp, PgDn to scroll ": " Aide - haut, bas, PgUp, PgDn ",
+
+		"close this help window":         "fermer cette aide",
+		"edit settings (size, colors..)": "régler taille, couleurs..",
+		"and rebind the keys (Bindings)": "et touches (Raccourcis)",
+		"moves with hjkl, wasd, esdf":    "touches hjkl, wasd, esdf",
+		"or the numpad (Keys)":           "ou pavé numérique (Touches)",
+		"travel to the next junction":    "aller au prochain carrefour",
+		"also PGUP, PGDN, HOME, END":     "aussi PGUP, PGDN, HOME, END",
+		"create a full new maze":         "créer un nouveau labyrinthe",
+		"quit existing challenge":        "quitter le défi en cours",
+		"pause current challenge":        "mettre le défi en pause",
+		"resume from paused game":        "reprendre la partie",
+		"save current game state":        "sauvegarder la partie",
+		"load a saved game state":        "charger une partie",
+		"find & display solution":        "afficher la solution",
+		"export current run as gif":      "exporter la course en gif",
+		"export current maze as png":     "exporter le labyrinthe png",
+		"display best times board":       "afficher les meilleurs temps",
+		"show share code of the maze":    "afficher le code de partage",
+		"display the maze structure":     "afficher la structure",
+		"toggle limited moves mode":      "activer les coups limités",
+		"play the maze of the day":       "jouer le labyrinthe du jour",
+		"toggle decorative walls":        "activer les murs décorés",
+		"switch generation animation":    "changer l'animation",
+		"switch computer opponent":       "changer l'adversaire",
+		"toggle auto-run corridors":      "activer la course auto",
+		"switch doors placement":         "changer la place des portes",
+		"toggle checkpoint cells":        "activer les étapes",
+		"toggle bell audio cues":         "activer les signaux sonores",
+		"toggle sliding ice tiles":       "activer les cases de glace",
+		"switch minotaur enemy":          "changer le minotaure",
+		"display lifetime stats":         "afficher les statistiques",
+		"play maze from share code":      "jouer un code de partage",
+		"play maze from a file":          "jouer un fichier labyrinthe",
+		"zoom in & out maze cells":       "zoomer sur les cases",
+		"switch walls drawing style":     "changer le style des murs",
+		"switch gui color scheme":        "changer les couleurs",
+		"navigate into the maze":         "avancer dans le labyrinthe",
+		"close the whole program":        "fermer le programme",
+		"Craft with ♥ by Jerome Amon":    "Créé avec ♥ par Jerome Amon",
+
+		// settings and keys.
+		" Settings [↕ select - ↔ change - Esc close] ": " Réglages [↕ choisir - ↔ changer - Esc fermer] ",
+		"Size":                        "Taille",
+		"Difficulty":                  "Difficulté",
+		"Algorithm":                   "Algorithme",
+		"Colors":                      "Couleurs",
+		"Walls":                       "Murs",
+		"Fit":                         "Ajustement",
+		"Autosave":                    "Sauv. auto",
+		"Keys":                        "Touches",
+		"Bell":                        "Bip",
+		"Bell bump":                   "Bip choc",
+		"Bell item":                   "Bip objet",
+		"Bell hint":                   "Bip indice",
+		"Bell victory":                "Bip victoire",
+		" Pattern (* beep, - pause) ": " Motif (* bip, - pause) ",
+		"Wrong bell pattern %q":       "Motif de bip incorrect %q",
+		"Language":                    "Langue",
+		"Bindings":                    "Raccourcis",
+		" Size (width x height) ":     " Taille (largeur x hauteur) ",
+		" Keys [Enter replace - Space add - Del reset - Esc close] ": " Touches [Entrée remplacer - Espace ajouter - Suppr défaut - Esc fermer] ",
+		" Press the key to %s - Esc cancel ":                         " Appuyez sur la touche pour %s - Esc annuler ",
+		"Quit the game":                                              "Quitter le jeu",
+		"Navigate between views":                                     "Passer d'une vue à l'autre",
+		"Display the help":                                           "Afficher l'aide",
+		"Play a new maze":                                            "Jouer un nouveau labyrinthe",
+		"Edit the settings":                                          "Modifier les réglages",
+		"Play the daily maze":                                        "Jouer le labyrinthe du jour",
+		"Switch the generation speed":                                "Changer la vitesse",
+		"Toggle the themed walls":                                    "Activer les murs décorés",
+		"Switch the doors placement":                                 "Changer la place des portes",
+		"Toggle the auto-run":                                        "Activer la course auto",
+		"Switch the minotaur mode":                                   "Changer le mode du minotaure",
+		"Toggle the ice tiles":                                       "Activer les cases de glace",
+		"Toggle the bell":                                            "Activer le bip",
+		"Toggle the checkpoints":                                     "Activer les étapes",
+		"Switch the opponent level":                                  "Changer le niveau adverse",
+		"Toggle the moves limit":                                     "Activer les coups limités",
+		"Display the statistics":                                     "Afficher les statistiques",
+		"Play a share code":                                          "Jouer un code de partage",
+		"Zoom in & out":                                              "Zoomer et dézoomer",
+		"Switch the walls style":                                     "Changer le style des murs",
+		"Switch the colors":                                          "Changer les couleurs",
+		"Play a maze file":                                           "Jouer un fichier labyrinthe",
+		"Display the best times":                                     "Afficher les meilleurs temps",
+		"Load a saved session":                                       "Charger une partie",
+		"Leave the maze":                                             "Quitter le labyrinthe",
+		"Pause & resume":                                             "Pause et reprise",
+		"Reset the run":                                              "Recommencer la course",
+		"Move up":                                                    "Monter",
+		"Move down":                                                  "Descendre",
+		"Move left":                                                  "Aller à gauche",
+		"Move right":                                                 "Aller à droite",
+		"Travel up":                                                  "Filer vers le haut",
+		"Travel down":                                                "Filer vers le bas",
+		"Travel left":                                                "Filer à gauche",
+		"Travel right":                                               "Filer à droite",
+		"Save the game":                                              "Sauvegarder la partie",
+		"Export the run":                                             "Exporter la course",
+		"Export the maze as png":                                     "Exporter le labyrinthe png",
+		"Display the share code":                                     "Afficher le code de partage",
+		"Display the maze structure":                                 "Afficher la structure",
+		"Reveal the solution":                                        "Révéler la solution",
+		"%s already bound to %s":                                     "%s déjà associée à %s",
+		"%s is caught by the console. Rebind %s":                     "%s est captée par la console. Associez à nouveau %s",
+		"up-left":                                                    "haut-gauche",
+		"up-right":                                                   "haut-droite",
+		"down-left":                                                  "bas-gauche",
+		"down-right":                                                 "bas-droite",
+		"%s is kept for the %s moves":                                "%s est gardée pour les déplacements %s",
+		"Failed to bind %s: %v":                                      "Impossible d'associer %s : %v",
+
+		// movement keys schemes and keys file.
+		"Cannot use the %s keys: %s already bound to %s":  "Impossible d'utiliser les touches %s : %s déjà associée à %s",
+		"Skipped %s of keys file: %s already bound to %s": "%s ignorée du fichier des touches : %s déjà associée à %s",
+
+		// sessions.
+		" Select A Session To Replay ":                         " Choisissez Une Partie À Rejouer ",
+		" Select A Session To Replay [sort: %s] [filter: %s] ": " Choisissez Une Partie À Rejouer [tri : %s] [filtre : %s] ",
+		"[search: %s] ":                             "[recherche : %s] ",
+		"[nothing to clean up] ":                    "[rien à nettoyer] ",
+		"No session matching the search or filter.": "Aucune partie ne correspond à la recherche ou au filtre.",
+		"playing":                            "en jeu",
+		"done":                               "finie",
+		"corrupt":                            "abîmée",
+		" Search ":                           " Recherche ",
+		" Session Label ":                    " Nom De La Partie ",
+		" Session Label (optional) ":         " Nom De La Partie (facultatif) ",
+		"Delete session %s?":                 "Supprimer la partie %s ?",
+		"Remove %d sessions and reclaim %s?": "Supprimer %d parties et libérer %s ?",
+		"Save this unfinished maze before quitting?": "Sauvegarder ce labyrinthe inachevé avant de quitter ?",
+		"Save this unfinished maze before closing?":  "Sauvegarder ce labyrinthe inachevé avant de fermer ?",
+		"Game saved":               "Partie sauvegardée",
+		"Save throttled, wait %ds": "Sauvegarde trop rapprochée, attendez %ds",
+		"Failed to save game: %v":  "Impossible de sauvegarder la partie : %v",
+		"Sessions store unavailable, saving into data folder": "Stockage des parties indisponible, sauvegarde dans le dossier de données",
+
+		// errors.
+		" Error ":                             " Erreur ",
+		" Confirm ":                           " Confirmation ",
+		"Retry":                               "Réessayer",
+		"New maze":                            "Nouveau labyrinthe",
+		"Quit":                                "Quitter",
+		"Close":                               "Fermer",
+		"Continue without saving":             "Continuer sans sauvegarder",
+		"The new maze cannot be generated.":   "Le nouveau labyrinthe ne peut pas être créé.",
+		"The game cannot be saved.":           "La partie ne peut pas être sauvegardée.",
+		"The session %s cannot be loaded.":    "La partie %s ne peut pas être chargée.",
+		"The daily maze cannot be displayed.": "Le labyrinthe du jour ne peut pas être affiché.",
+		"Delete this session from the saved sessions (CTRL+L) or start a new maze.":  "Supprimez cette partie des parties sauvegardées (CTRL+L) ou commencez un nouveau labyrinthe.",
+		"Update gomazes to the version which saved this session to load it.":         "Mettez à jour gomazes vers la version qui a sauvegardé cette partie pour la charger.",
+		"Enlarge the terminal (at least %d x %d) or pick a smaller maze size.":       "Agrandissez le terminal (au moins %d x %d) ou choisissez une taille plus petite.",
+		"Check the maze algorithm and the doors placement in the settings (CTRL+E).": "Vérifiez l'algorithme et la place des portes dans les réglages (CTRL+E).",
+		"The %d x %d maze exceeds the screen. Shrink it to %d x %d (n to scroll)?":   "Le labyrinthe de %d x %d dépasse l'écran. Le réduire à %d x %d (n pour défiler) ?",
+		"Failed to load colors file: %v":                                             "Impossible de charger le fichier des couleurs : %v",
+		"Failed to load settings: %v":                                                "Impossible de charger les réglages : %v",
+		"Failed to save settings: %v":                                                "Impossible de sauvegarder les réglages : %v",
+		"Failed to load keys: %v":                                                    "Impossible de charger les touches : %v",
+		"Failed to save keys: %v":                                                    "Impossible de sauvegarder les touches : %v",
+		"Failed to load translations: %v":                                            "Impossible de charger les traductions : %v",
+		"Terminal too small":                                                         "Terminal trop petit",
+		"Enlarge your terminal":                                                      "Agrandissez votre terminal",
+		"to at least %d x %d":                                                        "à au moins %d x %d",
+		"(now %d x %d)":                                                              "(actuellement %d x %d)",
+		"The game is paused.":                                                        "La partie est en pause.",
+
+		// popups.
+		"Press Esc to close.":           "Appuyez sur Esc pour fermer.",
+		"Press Esc or Ctrl+K to close.": "Appuyez sur Esc ou Ctrl+K pour fermer.",
+		" Best Times ":                  " Meilleurs Temps ",
+		"No completed maze yet.":        "Aucun labyrinthe terminé pour l'instant.",
+		" Lifetime Stats ":              " Statistiques ",
+		"Mazes generated":               "Labyrinthes créés",
+		"Mazes completed":               "Labyrinthes finis",
+		"Total moves":                   "Coups joués",
+		"Total play time":               "Temps de jeu",
+		"Avg efficiency":                "Efficacité moy.",
+		" Maze Structure ":              " Structure Du Labyrinthe ",
+		"Cells":                         "Cases",
+		"Dead ends":                     "Impasses",
+		"Junctions":                     "Carrefours",
+		"Longest corridor":              "Plus long couloir",
+		"Branches":                      "Branches",
+		"Avg branch":                    "Branche moy.",
+		"Loops":                         "Boucles",
+		"%d cells":                      "%d cases",
+		"%.1f moves":                    "%.1f coups",
+		" Run Analysis ":                " Analyse De La Course ",
+		"Moves made":                    "Coups joués",
+		"Path overlap":                  "Chemin repassé",
+		"Wrong turns":                   "Mauvais virages",
+		"Longest detour":                "Plus long détour",
+		"Segment %d/%d":                 "Segment %d/%d",
+		" Share Code ":                  " Code De Partage ",
+		" Paste Share Code ":            " Collez Le Code De Partage ",
+		"Copy it (without line breaks) to share this maze.": "Copiez-le (sans sauts de ligne) pour partager ce labyrinthe.",
+		"The share code cannot be used.":                    "Le code de partage ne peut pas être utilisé.",
+		"Failed to share the maze: %v":                      "Impossible de partager le labyrinthe : %v",
+		"The shared maze cannot be played.":                 "Le labyrinthe partagé ne peut pas être joué.",
+		" Maze File ":                                       " Fichier Labyrinthe ",
+		" Maze File To Play ":                               " Fichier Labyrinthe À Jouer ",
+		"The maze file cannot be played.":                   "Le fichier labyrinthe ne peut pas être joué.",
+		" Zoom ":                                            " Zoom ",
+		"The maze of size %d x %d is too large":             "Le labyrinthe de %d x %d est trop grand",
+		"to be zoomed into this terminal.":                  "pour être zoomé dans ce terminal.",
+		"There is no run to export as gif":                  "Aucune course à exporter en gif",
+		"Failed to create exports folder: %v":               "Impossible de créer le dossier des exports : %v",
+		"Failed to export run as gif: %v":                   "Impossible d'exporter la course en gif : %v",
+		"Run exported into %s":                              "Course exportée dans %s",
+		"Failed to create png file: %v":                     "Impossible de créer le fichier png : %v",
+		"Failed to export maze as png: %v":                  "Impossible d'exporter le labyrinthe en png : %v",
+		"Maze exported into %s":                             "Labyrinthe exporté dans %s",
+	})
+}
@@ -0,0 +1,262 @@
+package main
+
+// This file provides the leveled logs of the game. Each entry is a line of
+// key=value pairs with the time, the level, the source line and the message
+// followed by its own pairs. While playing, the lines are written into the
+// logs file by a dedicated goroutine so the gui never waits for the disk,
+// and the file is rotated once it grows over LOG_MAX_SIZE.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	LOG_FILE = "logs.log"
+	// size over which the logs file is rotated and number of old files kept.
+	LOG_MAX_SIZE = 1 << 20
+	LOG_BACKUPS  = 3
+	// lines waiting to be written. the next ones are dropped.
+	LOG_QUEUE = 256
+)
+
+// levels of the logs entries.
+type logLevel int32
+
+const (
+	LEVEL_DEBUG logLevel = iota
+	LEVEL_INFO
+	LEVEL_WARN
+	LEVEL_ERROR
+)
+
+var logLevelNames = []string{"debug", "info", "warn", "error"}
+
+var (
+	// lowest level written.
+	minLogLevel = int32(LEVEL_INFO)
+	// destination of the lines. the standard error until setupLogs.
+	logOutput logSink = &directLog{w: os.Stderr}
+)
+
+// parseLogLevel returns the level named <name>.
+func parseLogLevel(name string) (logLevel, error) {
+	for i, n := range logLevelNames {
+		if strings.EqualFold(n, name) {
+			return logLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q. expected one of %s", name, strings.Join(logLevelNames, "/"))
+}
+
+// setLogLevel sets the lowest level written.
+func setLogLevel(level logLevel) {
+	atomic.StoreInt32(&minLogLevel, int32(level))
+}
+
+// logEnabled tells if the entries of <level> are written.
+func logEnabled(level logLevel) bool {
+	return int32(level) >= atomic.LoadInt32(&minLogLevel)
+}
+
+// setupLogs writes the next lines into the rotated logs file at <path>.
+func setupLogs(path string) error {
+	file, err := openRotatedFile(path, LOG_MAX_SIZE, LOG_BACKUPS)
+	if err != nil {
+		return err
+	}
+
+	previous := logOutput
+	logOutput = newLogQueue(file)
+	previous.close()
+	return nil
+}
+
+// closeLogs writes the lines still queued then closes the logs file.
+func closeLogs() {
+	logOutput.close()
+}
+
+func logDebug(msg string, kv ...interface{}) { writeLog(LEVEL_DEBUG, msg, kv) }
+func logInfo(msg string, kv ...interface{})  { writeLog(LEVEL_INFO, msg, kv) }
+func logWarn(msg string, kv ...interface{})  { writeLog(LEVEL_WARN, msg, kv) }
+func logError(msg string, kv ...interface{}) { writeLog(LEVEL_ERROR, msg, kv) }
+
+// writeLog formats the entry <msg> with the key and value pairs <kv>
+// and queues it if its level is enabled.
+func writeLog(level logLevel, msg string, kv []interface{}) {
+	if !logEnabled(level) {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(time.Now().Format("2006-01-02T15:04:05.000Z07:00"))
+	b.WriteString(" level=")
+	b.WriteString(logLevelNames[level])
+	if _, file, line, ok := runtime.Caller(2); ok {
+		fmt.Fprintf(&b, " src=%s:%d", filepath.Base(file), line)
+	}
+	b.WriteString(" msg=")
+	b.WriteString(logValue(msg))
+
+	for i := 0; i < len(kv); i += 2 {
+		key, value := fmt.Sprint(kv[i]), interface{}("(missing)")
+		if i+1 < len(kv) {
+			value = kv[i+1]
+		}
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(logValue(fmt.Sprint(value)))
+	}
+	b.WriteString("\n")
+
+	logOutput.write(b.String())
+}
+
+// logValue quotes <s> when it holds spaces, quotes or equal signs.
+func logValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logSink receives the formatted lines.
+type logSink interface {
+	write(line string)
+	close()
+}
+
+// directLog writes the lines at once. It suits the commands
+// printing their logs on the standard error.
+type directLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (d *directLog) write(line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	io.WriteString(d.w, line)
+}
+
+func (d *directLog) close() {}
+
+// logQueue writes the lines into its file from a dedicated goroutine.
+type logQueue struct {
+	mu     sync.Mutex
+	closed bool
+	lines  chan string
+	done   chan struct{}
+}
+
+// newLogQueue starts writing the queued lines into <w> which
+// is closed once the queue is.
+func newLogQueue(w io.WriteCloser) *logQueue {
+	q := &logQueue{lines: make(chan string, LOG_QUEUE), done: make(chan struct{})}
+	go func() {
+		defer close(q.done)
+		for line := range q.lines {
+			io.WriteString(w, line)
+		}
+		w.Close()
+	}()
+	return q
+}
+
+// write queues <line> or drops it when the queue is full or closed.
+func (q *logQueue) write(line string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+
+	select {
+	case q.lines <- line:
+	default:
+	}
+}
+
+// close stops the queue once the queued lines are written.
+func (q *logQueue) close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.lines)
+	}
+	q.mu.Unlock()
+	<-q.done
+}
+
+// rotatedFile is a file moved to <path>.1 once it grows over <max> bytes,
+// the older ones being shifted up to <path>.<backups>.
+type rotatedFile struct {
+	path    string
+	max     int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// openRotatedFile opens the file at <path> to append to it.
+func openRotatedFile(path string, max int64, backups int) (*rotatedFile, error) {
+	r := &rotatedFile{path: path, max: max, backups: backups}
+	return r, r.open()
+}
+
+func (r *rotatedFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// Write appends <p> after rotating the file if it would grow too big.
+func (r *rotatedFile) Write(p []byte) (int, error) {
+	if r.size > 0 && r.size+int64(len(p)) > r.max {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the old files and starts a new one.
+func (r *rotatedFile) rotate() error {
+	r.file.Close()
+	for i := r.backups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
+
+// Close closes the current file.
+func (r *rotatedFile) Close() error {
+	return r.file.Close()
+}
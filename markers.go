@@ -0,0 +1,142 @@
+package main
+
+// This file provides markers drawn over the maze view as one character views.
+// A marker replays a list of steps following the run clock of the player.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+)
+
+// longest wait before checking the run clock again.
+const MARKER_MAX_WAIT = 100 * time.Millisecond
+
+// markers displayed by name so they can be drawn again when the
+// maze view moves.
+var markerViews = make(map[string]*overlayMarker)
+
+// overlayMarker is a character moving over the maze view.
+type overlayMarker struct {
+	name  string
+	glyph rune
+	color gocui.Attribute
+	// game whose maze view the marker is drawn over.
+	gm *Game
+	// cursor position of the maze where it is drawn.
+	cx, cy int
+	// stops the goroutine replaying the steps if any.
+	stop chan struct{}
+}
+
+// newMarker returns the marker <name> drawn with <glyph>
+// and <color> over the maze view of the game.
+func (gm *Game) newMarker(name string, glyph rune, color gocui.Attribute) *overlayMarker {
+	return &overlayMarker{name: name, glyph: glyph, color: color, gm: gm}
+}
+
+// start replays the steps of the marker in a dedicated goroutine following
+// the run <clock>. Once the last step is displayed, onDone is called if not nil.
+func (m *overlayMarker) start(g *gocui.Gui, clock *Stopwatch, steps []replayStep, onDone func(g *gocui.Gui) error) {
+	m.close(g)
+	m.stop = make(chan struct{})
+	wg.Add(1)
+	go m.replay(g, clock, steps, m.stop, onDone)
+}
+
+// replay moves the marker along the steps following the run <clock>.
+func (m *overlayMarker) replay(g *gocui.Gui, clock *Stopwatch, steps []replayStep, stop chan struct{}, onDone func(g *gocui.Gui) error) {
+	defer wg.Done()
+
+	for i, step := range steps {
+		for {
+			wait := step.At - clock.Elapsed()
+			if wait <= 0 {
+				break
+			}
+
+			// the run clock may be paused so check it again later.
+			if wait > MARKER_MAX_WAIT {
+				wait = MARKER_MAX_WAIT
+			}
+
+			select {
+			case <-exit:
+				return
+			case <-stop:
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		x, y, last := step.X, step.Y, i == len(steps)-1
+		g.Update(func(g *gocui.Gui) error {
+			select {
+			case <-stop:
+				// marker was closed in the meantime.
+				return nil
+			default:
+			}
+
+			if err := m.draw(g, x, y); err != nil {
+				return err
+			}
+
+			if last && onDone != nil {
+				return onDone(g)
+			}
+			return nil
+		})
+	}
+}
+
+// draw places the one character view of the marker over
+// the maze view at the cursor coordinates (cx, cy) of the maze.
+func (m *overlayMarker) draw(g *gocui.Gui, cx, cy int) error {
+	sx, sy, err := m.gm.mazeScreenPosition(g, cx, cy)
+	if err != nil {
+		return nil
+	}
+
+	// a double width glyph takes the next column too.
+	markerView, err := setView(g, m.name, sx-1, sy-1, sx+runewidth.RuneWidth(m.glyph), sy+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display marker view", "view", m.name, "err", err)
+		return err
+	}
+
+	markerViews[m.name] = m
+	m.cx, m.cy = cx, cy
+	markerView.Frame = false
+	// the info views hide the markers of a maze larger than the screen
+	// and a scrolled maze view the ones out of its window.
+	_, maxY := g.Size()
+	markerView.Visible = sy < maxY-3 && m.gm.isInMazeWindow(g, cx, cy)
+	markerView.FgColor = m.color
+	clearView(markerView)
+	fmt.Fprint(markerView, string(m.glyph))
+	_, _ = g.SetViewOnTop(m.name)
+	return nil
+}
+
+// close stops the replay and removes the marker view.
+func (m *overlayMarker) close(g *gocui.Gui) {
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+
+	delete(markerViews, m.name)
+	if err := g.DeleteView(m.name); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete marker view", "view", m.name, "err", err)
+	}
+}
+
+// redrawMarkers draws again the markers displayed at their positions.
+func redrawMarkers(g *gocui.Gui) {
+	for _, m := range markerViews {
+		_ = m.draw(g, m.cx, m.cy)
+	}
+}
@@ -0,0 +1,118 @@
+// Package maze holds the grid of the cells of a maze and the registries of
+// the algorithms generating and solving them. It is the part of gomazes
+// other modules import to add their own algorithms: a package registering
+// a generator or a solver from its init function shows up into the game,
+// the config file and the commands once imported by the program.
+package maze
+
+// This file provides the grid holding the cells of a maze. Each cell only
+// needs its 4 wall bits (N, S, E, W) so the grid packs one cell per byte
+// into a single slice rather than a slice of int per row. It takes 8 times
+// less memory and a single allocation, which lets the headless commands
+// generate mazes of millions of cells.
+
+// bits of the 4 walls of a cell, set when the wall is opened.
+const (
+	N = 1 // N : 0001
+	S = 2 // S : 0010
+	E = 4 // E : 0100
+	W = 8 // W : 1000
+)
+
+// Grid is the cells of a maze of Width() x Height() cells. The bits of a
+// cell are set for its opened walls.
+type Grid struct {
+	width, height int
+	cells         []byte
+}
+
+// NewGrid returns a grid of <width> x <height> cells with all walls closed.
+func NewGrid(width, height int) *Grid {
+	return &Grid{width: width, height: height, cells: make([]byte, width*height)}
+}
+
+// GridFromRows returns the grid of the cells given row by row, like the
+// grids of the saved sessions and of the JSON documents. The rows shorter
+// than the first one are completed with closed cells.
+func GridFromRows(rows [][]int) *Grid {
+	if len(rows) == 0 {
+		return NewGrid(0, 0)
+	}
+
+	g := NewGrid(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x := 0; x < len(row) && x < g.width; x++ {
+			g.Set(x, y, row[x])
+		}
+	}
+	return g
+}
+
+// Width returns the number of columns of the grid.
+func (g *Grid) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows of the grid.
+func (g *Grid) Height() int {
+	return g.height
+}
+
+// Inside tells if the cell (x, y) belongs to the grid.
+func (g *Grid) Inside(x, y int) bool {
+	return x >= 0 && x < g.width && y >= 0 && y < g.height
+}
+
+// At returns the bits of the cell (x, y).
+func (g *Grid) At(x, y int) int {
+	return int(g.cells[y*g.width+x])
+}
+
+// Set replaces the bits of the cell (x, y) by <cell>.
+func (g *Grid) Set(x, y, cell int) {
+	g.cells[y*g.width+x] = byte(cell & (N | S | E | W))
+}
+
+// Open opens the walls <d> of the cell (x, y) only, not the opposite
+// walls of its neighbours.
+func (g *Grid) Open(x, y, d int) {
+	g.cells[y*g.width+x] |= byte(d)
+}
+
+// Close closes the walls <d> of the cell (x, y) only, not the opposite
+// walls of its neighbours.
+func (g *Grid) Close(x, y, d int) {
+	g.cells[y*g.width+x] &^= byte(d)
+}
+
+// Row returns a copy of the cells of the row <y>.
+func (g *Grid) Row(y int) []int {
+	row := make([]int, g.width)
+	for x := range row {
+		row[x] = g.At(x, y)
+	}
+	return row
+}
+
+// Rows returns a copy of the cells row by row, the layout of the grids
+// in the saved sessions and the JSON documents.
+func (g *Grid) Rows() [][]int {
+	rows := make([][]int, g.height)
+	for y := range rows {
+		rows[y] = g.Row(y)
+	}
+	return rows
+}
+
+// Clone returns a copy of the grid.
+func (g *Grid) Clone() *Grid {
+	return &Grid{width: g.width, height: g.height, cells: append([]byte(nil), g.cells...)}
+}
+
+// Equal tells if both grids have the same size and cells.
+func (g *Grid) Equal(o *Grid) bool {
+	if g.width != o.width || g.height != o.height {
+		return false
+	}
+	return string(g.cells) == string(o.cells)
+}
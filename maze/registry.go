@@ -0,0 +1,98 @@
+package maze
+
+// This file provides the registries of the maze algorithms. A generator or
+// a solver registers itself by name from the init function of its own file
+// or package, then the program finds it by that name.
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// GeneratorFunc builds a maze of <width> x <height> cells from the randomness
+// of <r>. The entrance opens the north wall of the top row cell at column
+// <inX> and the exit the south wall of the bottom row cell at column <outX>.
+type GeneratorFunc func(width, height int, r *rand.Rand, inX, outX int) *Grid
+
+// SolverFunc returns the list of cells (x,y) leading from the cell <start>
+// to the cell <end> of <maze>, both included, or nil without any path.
+type SolverFunc func(maze *Grid, start, end [2]int) [][2]int
+
+var (
+	registryMu sync.RWMutex
+	// maze generation and solving algorithms by name.
+	generators = make(map[string]GeneratorFunc)
+	solvers    = make(map[string]SolverFunc)
+)
+
+// RegisterGenerator makes the generator <fn> available under <name>.
+// It panics when the name is empty or already taken, like the other
+// registries of the standard library.
+func RegisterGenerator(name string, fn GeneratorFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" || strings.ContainsAny(name, " ,:") || fn == nil {
+		panic(fmt.Sprintf("gomazes: invalid maze generator %q", name))
+	}
+	if _, found := generators[name]; found {
+		panic(fmt.Sprintf("gomazes: maze generator %q registered twice", name))
+	}
+	generators[name] = fn
+}
+
+// RegisterSolver makes the solver <fn> available under <name>.
+// It panics when the name is empty or already taken.
+func RegisterSolver(name string, fn SolverFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" || strings.ContainsAny(name, " ,:") || fn == nil {
+		panic(fmt.Sprintf("gomazes: invalid maze solver %q", name))
+	}
+	if _, found := solvers[name]; found {
+		panic(fmt.Sprintf("gomazes: maze solver %q registered twice", name))
+	}
+	solvers[name] = fn
+}
+
+// Generator returns the generator registered under <name> if any.
+func Generator(name string) (GeneratorFunc, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, found := generators[name]
+	return fn, found
+}
+
+// Solver returns the solver registered under <name> if any.
+func Solver(name string) (SolverFunc, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	fn, found := solvers[name]
+	return fn, found
+}
+
+// Generators returns the sorted names of the registered generators.
+func Generators() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Solvers returns the sorted names of the registered solvers.
+func Solvers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,182 @@
+package main
+
+// This file implements the minotaur enemy. It either patrols the maze at
+// random or chases the player once in sight, moving every other turn then.
+// Touching the minotaur loses the round. Its turns are played on the gui
+// goroutine so they stay in sync with the player moves and the redraws.
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	MINOTAUR = "minotaur"
+	// duration of a minotaur turn.
+	MINOTAUR_INTERVAL = 300 * time.Millisecond
+	// distance in cells from which the minotaur spots the player.
+	MINOTAUR_SIGHT = 8
+)
+
+var (
+	minotaurModes = []string{"off", "patrol", "chase"}
+	// index of the current mode. 0 means no minotaur.
+	currentMinotaurMode = 0
+)
+
+// minotaurState holds the minotaur marker with its cell,
+// the cell it comes from and its turns count.
+type minotaurState struct {
+	marker     *overlayMarker
+	cell, from [2]int
+	turns      int
+}
+
+// cycleMinotaur switches to the next minotaur mode.
+// It applies to the next run started.
+func cycleMinotaur(g *gocui.Gui, v *gocui.View) error {
+	currentMinotaurMode = (currentMinotaurMode + 1) % len(minotaurModes)
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// startMinotaur places the minotaur on a random cell of the bottom
+// half of the current maze then starts playing its turns.
+func (gm *Game) startMinotaur(g *gocui.Gui) {
+	gm.minotaur.marker.close(g)
+	if currentMinotaurMode == 0 || gm.maze == nil {
+		return
+	}
+
+	r := rand.New(rand.NewSource(gm.seed))
+	height := gm.maze.Height()
+	width := gm.maze.Width()
+	gm.minotaur.cell = [2]int{r.Intn(width), height/2 + r.Intn(height-height/2)}
+	gm.minotaur.from = gm.minotaur.cell
+	gm.minotaur.turns = 0
+	if err := gm.renderer.DrawOverlay(gm.minotaur.marker, 2*gm.minotaur.cell[0]+1, gm.minotaur.cell[1]+1); err != nil {
+		return
+	}
+
+	gm.minotaur.marker.stop = make(chan struct{})
+	wg.Add(1)
+	go gm.minotaurLoop(g, gm.minotaur.marker.stop, r)
+}
+
+// minotaurLoop plays a minotaur turn at each interval until stopped.
+func (gm *Game) minotaurLoop(g *gocui.Gui, stop chan struct{}, r *rand.Rand) {
+	defer wg.Done()
+	ticker := time.NewTicker(MINOTAUR_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			g.Update(func(g *gocui.Gui) error {
+				select {
+				case <-stop:
+					// minotaur was closed in the meantime.
+					return nil
+				default:
+				}
+				return gm.playMinotaurTurn(g, r)
+			})
+		}
+	}
+}
+
+// playMinotaurTurn moves the minotaur by one cell. While chasing
+// the player in sight, it only moves every other turn.
+func (gm *Game) playMinotaurTurn(g *gocui.Gui, r *rand.Rand) error {
+	if gm.paused || gm.over || gm.maze == nil {
+		return nil
+	}
+
+	mv, err := g.View(MAZE)
+	if err != nil {
+		return nil
+	}
+
+	gm.minotaur.turns++
+	var next [2]int
+	if path := gm.spotPlayer(mv); currentMinotaurMode == 2 && path != nil {
+		if gm.minotaur.turns%2 == 0 {
+			return nil
+		}
+		next = path[1]
+	} else {
+		next = gm.patrolStep(r)
+	}
+
+	gm.minotaur.from, gm.minotaur.cell = gm.minotaur.cell, next
+	if err = gm.renderer.DrawOverlay(gm.minotaur.marker, 2*next[0]+1, next[1]+1); err != nil {
+		return err
+	}
+
+	gm.catchPlayer(g, mv)
+	return nil
+}
+
+// spotPlayer returns the cells from the minotaur to the player
+// when the player stands within its sight. It returns nil otherwise.
+func (gm *Game) spotPlayer(mv *gocui.View) [][2]int {
+	cx, cy := gm.mazeCursor(mv)
+	if cy < 1 {
+		return nil
+	}
+
+	path := solveMaze(gm.maze, gm.minotaur.cell, [2]int{(cx - 1) / 2, cy - 1})
+	if len(path) < 2 || len(path)-1 > MINOTAUR_SIGHT {
+		return nil
+	}
+
+	return path
+}
+
+// patrolStep picks a random opened neighbor of the minotaur cell.
+// It only goes back to the cell it comes from at dead ends.
+func (gm *Game) patrolStep(r *rand.Rand) [2]int {
+	height := gm.maze.Height()
+	width := gm.maze.Width()
+	var ways [][2]int
+	for _, d := range [4]int{N, S, E, W} {
+		if (gm.maze.At(gm.minotaur.cell[0], gm.minotaur.cell[1]) & d) == 0 {
+			continue
+		}
+
+		nX, nY := moveTo(gm.minotaur.cell[0], gm.minotaur.cell[1], d)
+		next := [2]int{nX, nY}
+		if nY < 0 || nY >= height || nX < 0 || nX >= width || next == gm.minotaur.from {
+			continue
+		}
+		ways = append(ways, next)
+	}
+
+	if len(ways) == 0 {
+		return gm.minotaur.from
+	}
+
+	return ways[r.Intn(len(ways))]
+}
+
+// catchPlayer ends the round as lost when the player of the maze view
+// touches the minotaur, even while crossing the wall column next to it.
+func (gm *Game) catchPlayer(g *gocui.Gui, mv *gocui.View) bool {
+	if gm.minotaur.marker.stop == nil || gm.over {
+		return false
+	}
+
+	cx, cy := gm.mazeCursor(mv)
+	if cy-1 != gm.minotaur.cell[1] || abs(cx-(2*gm.minotaur.cell[0]+1)) > 1 {
+		return false
+	}
+
+	gm.endRound(g, false)
+	return true
+}
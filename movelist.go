@@ -0,0 +1,197 @@
+package main
+
+// This file turns the solution of a maze into a list of compass moves and
+// checks the move lists made by other programs, like the bots of a contest.
+// Moves use the usual compass: N is up, E is right, S is down and W is left.
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// moveCheck is the result of walking a move list through a maze.
+type moveCheck struct {
+	File    string     `json:"file,omitempty"`
+	Valid   bool       `json:"valid"`
+	Moves   int        `json:"moves"`
+	Optimal int        `json:"optimal"`
+	Reached cellReport `json:"reached"`
+	// index of the first move which failed or -1.
+	Failed  int    `json:"failed"`
+	Message string `json:"message"`
+}
+
+// moveLetter returns the compass letter of the grid direction <d>.
+func moveLetter(d int) byte {
+	for _, side := range interchangeSides {
+		if side.code == d {
+			return side.letter
+		}
+	}
+	return '?'
+}
+
+// moveDirection returns the grid direction of the compass letter <c>.
+func moveDirection(c byte) (int, bool) {
+	for _, side := range interchangeSides {
+		if side.letter == c {
+			return side.code, true
+		}
+	}
+	return 0, false
+}
+
+// solutionMoves returns the compass moves walking along <path>.
+func solutionMoves(path [][2]int) string {
+	var b strings.Builder
+	for i := 1; i < len(path); i++ {
+		for _, d := range [4]int{N, S, E, W} {
+			if x, y := moveTo(path[i-1][0], path[i-1][1], d); x == path[i][0] && y == path[i][1] {
+				b.WriteByte(moveLetter(d))
+				break
+			}
+		}
+	}
+	return b.String()
+}
+
+// parseMoveList reads a move list either as text with the letters of the
+// moves optionally separated by spaces or commas, or in JSON as a string,
+// an array of letters or an object with its "moves" like the solve reports.
+func parseMoveList(data []byte) (string, error) {
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") {
+		var content struct {
+			Moves *string `json:"moves"`
+		}
+		if err := json.Unmarshal(data, &content); err != nil {
+			return "", err
+		}
+		if content.Moves == nil {
+			return "", errors.New("no moves field in the move list")
+		}
+		text = *content.Moves
+	} else if strings.HasPrefix(text, "[") {
+		var moves []string
+		if err := json.Unmarshal(data, &moves); err != nil {
+			return "", err
+		}
+		text = strings.Join(moves, "")
+	} else if strings.HasPrefix(text, "\"") {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	for i, c := range strings.ToUpper(text) {
+		if c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r' {
+			continue
+		}
+		if _, found := moveDirection(byte(c)); !found || c > 127 {
+			return "", fmt.Errorf("wrong move %q at offset %d", c, i)
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String(), nil
+}
+
+// checkMoves walks <moves> from the entrance of <maze>. The list is valid
+// when every move goes through an opened wall and the last one reaches
+// the exit.
+func checkMoves(maze *Grid, moves string) moveCheck {
+	in, out := mazeDoors(maze)
+	check := moveCheck{Moves: len(moves), Failed: -1}
+	if path := solveMaze(maze, in, out); path != nil {
+		check.Optimal = len(path) - 1
+	}
+
+	x, y := in[0], in[1]
+	for i := 0; i < len(moves); i++ {
+		d, _ := moveDirection(moves[i])
+		if !hasPassage(maze, x, y, d) {
+			check.Failed = i
+			check.Message = fmt.Sprintf("move %d (%c) hits a wall at cell (%d,%d)", i+1, moves[i], x, y)
+			break
+		}
+		x, y = moveTo(x, y, d)
+	}
+
+	check.Reached = cellReport{x, y}
+	switch {
+	case check.Failed >= 0:
+	case x != out[0] || y != out[1]:
+		check.Message = fmt.Sprintf("moves end at cell (%d,%d) instead of the exit (%d,%d)", x, y, out[0], out[1])
+	default:
+		check.Valid = true
+		check.Message = fmt.Sprintf("exit reached in %d moves (optimal %d)", check.Moves, check.Optimal)
+	}
+
+	return check
+}
+
+// runVerify checks a move list against a maze read from a file. It fails
+// when the moves do not lead from the entrance to the exit.
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "verify [flags] -i <maze> -moves <file>", "check that a list of N/E/S/W moves solves a maze read from a file")
+	input := fs.String("i", "", "maze file in text or JSON format or saved session")
+	movesFile := fs.String("moves", "-", "move list file in text or JSON format, - for the standard input")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	if *input == "" {
+		fs.Usage()
+		return errors.New("no maze file to verify the moves against")
+	}
+
+	maze, err := readMazeFile(*input)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if *movesFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*movesFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	moves, err := parseMoveList(data)
+	if err != nil {
+		return err
+	}
+
+	check := checkMoves(maze, moves)
+	if *movesFile != "-" {
+		check.File = *movesFile
+	}
+
+	if *asJSON {
+		if err = writeJSON(os.Stdout, check); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(check.Message)
+	}
+
+	if !check.Valid {
+		return errors.New("moves do not solve the maze")
+	}
+	return nil
+}
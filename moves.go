@@ -0,0 +1,133 @@
+package main
+
+// This file implements the limited moves challenge mode. The player only gets
+// the optimal number of moves plus a margin to reach the exit of the maze.
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	MOVES = "moves"
+
+	MVWIDTH = 24
+	// extra moves granted on top of the optimal path.
+	MOVES_MARGIN_PERCENT = 25
+)
+
+// limited moves mode applied to the next maze displayed.
+var isMovesLimited = false
+
+// movesBudget holds the moves left out of the budget of the
+// displayed maze when it is played in limited moves mode.
+type movesBudget struct {
+	limited      bool
+	left, budget int
+}
+
+// toggleMovesLimit switches the limited moves challenge mode on/off.
+// It applies to the next maze displayed.
+func toggleMovesLimit(g *gocui.Gui, v *gocui.View) error {
+	isMovesLimited = !isMovesLimited
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// optimalMoves returns the minimum number of cursor moves needed to go from
+// the cursor start column on top line to the exit of the maze. Moving between
+// two cells horizontally takes 2 moves since it crosses the wall column.
+func optimalMoves(maze *Grid, startX int) int {
+	in, out := mazeDoors(maze)
+	path := solveMaze(maze, in, out)
+	if path == nil {
+		return 0
+	}
+
+	return len(cursorPath(path, startX)) - 1
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// setupMovesBudget computes the moves budget of the displayed maze and
+// displays it in a dedicated view on top of the outputs view.
+func (gm *Game) setupMovesBudget(g *gocui.Gui, mv *gocui.View) error {
+	gm.moves = movesBudget{}
+	if !isMovesLimited || gm.maze == nil {
+		return nil
+	}
+
+	x, _ := gm.entranceCursor(mv)
+	optimal := optimalMoves(gm.maze, x)
+	budget := optimal + (optimal*MOVES_MARGIN_PERCENT)/100
+	gm.moves = movesBudget{limited: true, left: budget, budget: budget}
+
+	maxX, _ := g.Size()
+	movesView, err := setView(g, MOVES, (maxX-MVWIDTH)/2, 0, (maxX+MVWIDTH)/2, 2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to create moves view", "err", err)
+		return err
+	}
+
+	movesView.Frame = true
+	movesView.FgColor = scheme().alert
+	movesView.Editable = false
+	movesView.Wrap = false
+	_, _ = g.SetViewOnTop(MOVES)
+
+	gm.updateMovesView(g)
+	return nil
+}
+
+// updateMovesView displays the remaining moves.
+func (gm *Game) updateMovesView(g *gocui.Gui) {
+	movesView, err := g.View(MOVES)
+	if err != nil {
+		return
+	}
+
+	clearView(movesView)
+	fmt.Fprint(movesView, center(trf("MOVES LEFT: %d/%d", gm.moves.left, gm.moves.budget), MVWIDTH-1, " "))
+}
+
+// closeMovesView removes the remaining moves view if any.
+func closeMovesView(g *gocui.Gui) {
+	if err := g.DeleteView(MOVES); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete moves view", "err", err)
+	}
+}
+
+// countMove counts one move and consumes it from the budget if any.
+func (gm *Game) countMove(g *gocui.Gui) {
+	gm.run.moves++
+	if !gm.moves.limited || gm.maze == nil {
+		return
+	}
+
+	gm.moves.left--
+	gm.updateMovesView(g)
+}
+
+// restoreMoves sets the moves made so far when resuming a saved run
+// and takes them off the budget in limited moves mode.
+func (gm *Game) restoreMoves(g *gocui.Gui, moves int) {
+	gm.run.moves = moves
+	if !gm.moves.limited || gm.maze == nil {
+		return
+	}
+
+	gm.moves.left = gm.moves.budget - moves
+	gm.updateMovesView(g)
+}
+
+// isOutOfMoves tells if the moves budget is exhausted.
+func (gm *Game) isOutOfMoves() bool {
+	return gm.moves.limited && gm.maze != nil && gm.moves.left <= 0
+}
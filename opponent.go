@@ -0,0 +1,175 @@
+package main
+
+// This file implements the computer opponent. It walks toward the exit at the
+// pace of its level and may explore wrong branches like a wall follower does.
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const OPPONENT = "opponent"
+
+// opponentLevel defines the pace of the opponent and the chance
+// it takes to explore a wrong branch at each cell of the solution.
+type opponentLevel struct {
+	name           string
+	stepsPerSecond int
+	mistakes       float64
+}
+
+var (
+	opponentLevels = []opponentLevel{
+		{"off", 0, 0},
+		{"slow", 2, 0.3},
+		{"normal", 4, 0.15},
+		{"fast", 8, 0},
+	}
+
+	// index of the current level. 0 means no opponent.
+	currentOpponentLevel = 0
+)
+
+// cycleOpponent switches to the next opponent level.
+// It applies to the next run started.
+func cycleOpponent(g *gocui.Gui, v *gocui.View) error {
+	currentOpponentLevel = (currentOpponentLevel + 1) % len(opponentLevels)
+	refreshOutputsTitle(g)
+	return nil
+}
+
+// cursorPath converts a list of adjacent cells into the list of cursor
+// positions of the maze view, starting from column startX of top line.
+func cursorPath(cells [][2]int, startX int) [][2]int {
+	if len(cells) == 0 {
+		return nil
+	}
+
+	// move along the top line to entrance column then go down.
+	entranceX := 2*cells[0][0] + 1
+	steps := [][2]int{{startX, 0}}
+	for x := startX; x != entranceX; {
+		if x < entranceX {
+			x++
+		} else {
+			x--
+		}
+		steps = append(steps, [2]int{x, 0})
+	}
+	steps = append(steps, [2]int{entranceX, 1})
+
+	for i := 1; i < len(cells); i++ {
+		prev, cur := cells[i-1], cells[i]
+		if cur[0] != prev[0] {
+			// cross the wall column between both cells.
+			steps = append(steps, [2]int{prev[0] + cur[0] + 1, cur[1] + 1})
+		}
+		steps = append(steps, [2]int{2*cur[0] + 1, cur[1] + 1})
+	}
+
+	return steps
+}
+
+// opponentWalk returns the cells walked by the opponent from start to end.
+// At each cell of the solution, it may explore a wrong branch up to a dead
+// end with a probability of <mistakes> then come back to the solution.
+func opponentWalk(maze *Grid, start, end [2]int, mistakes float64, r *rand.Rand) [][2]int {
+	solution := solveMaze(maze, start, end)
+	if solution == nil {
+		return nil
+	}
+
+	visited := make(map[[2]int]bool, len(solution))
+	for _, cell := range solution {
+		visited[cell] = true
+	}
+
+	walk := [][2]int{start}
+	for i := 0; i < len(solution)-1; i++ {
+		cur := solution[i]
+		if mistakes > 0 && r.Float64() < mistakes {
+			branch := exploreBranch(maze, cur, visited, r)
+			walk = append(walk, branch...)
+			// walk back to the solution.
+			for j := len(branch) - 2; j >= 0; j-- {
+				walk = append(walk, branch[j])
+			}
+			if len(branch) > 0 {
+				walk = append(walk, cur)
+			}
+		}
+		walk = append(walk, solution[i+1])
+	}
+
+	return walk
+}
+
+// exploreBranch goes from cell through unvisited opened neighbors
+// picked randomly until a dead end. It returns the cells walked.
+func exploreBranch(maze *Grid, cell [2]int, visited map[[2]int]bool, r *rand.Rand) [][2]int {
+	height := maze.Height()
+	width := maze.Width()
+	var branch [][2]int
+	var randomDirections = [4]int{N, S, E, W}
+
+	for {
+		found := false
+		shuffleDirection(r, &randomDirections)
+		for _, d := range randomDirections {
+			if (maze.At(cell[0], cell[1]) & d) == 0 {
+				continue
+			}
+
+			nX, nY := moveTo(cell[0], cell[1], d)
+			next := [2]int{nX, nY}
+			if nY < 0 || nY >= height || nX < 0 || nX >= width || visited[next] {
+				continue
+			}
+
+			visited[next] = true
+			branch = append(branch, next)
+			cell = next
+			found = true
+			break
+		}
+
+		if !found {
+			return branch
+		}
+	}
+}
+
+// startOpponent makes the opponent walk from the entrance
+// of the maze displayed into the maze view <mv>.
+func (gm *Game) startOpponent(g *gocui.Gui, mv *gocui.View) {
+	gm.opponent.close(g)
+
+	level := opponentLevels[currentOpponentLevel]
+	if level.stepsPerSecond == 0 || gm.maze == nil {
+		return
+	}
+
+	in, out := mazeDoors(gm.maze)
+	r := rand.New(rand.NewSource(gm.seed))
+	cells := opponentWalk(gm.maze, in, out, level.mistakes, r)
+
+	x, _ := gm.entranceCursor(mv)
+	interval := time.Second / time.Duration(level.stepsPerSecond)
+	var steps []replayStep
+	for i, pos := range cursorPath(cells, x) {
+		steps = append(steps, replayStep{X: pos[0], Y: pos[1], At: time.Duration(i) * interval})
+	}
+
+	gm.opponent.start(g, gm.run.clock, steps, gm.opponentArrived)
+}
+
+// opponentArrived ends the round as lost when the opponent
+// reaches the exit before the player.
+func (gm *Game) opponentArrived(g *gocui.Gui) error {
+	if !gm.over && gm.maze != nil {
+		gm.endRound(g, false)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+// This file adds a generator for the large mazes through the generators
+// registry. It splits the grid into square regions carved at the same
+// time by a backtracker each, then stitches the regions together with a
+// single passage between the regions joined by a random spanning tree, so
+// the maze stays perfect. The layout of the regions does not depend on the
+// number of cpus so a seed builds the same maze on every machine.
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/jeamon/gomazes/maze"
+)
+
+const (
+	// side in cells of the regions carved concurrently.
+	PARALLEL_REGION = 256
+)
+
+// parallelWorkers is the number of regions carved at once. Zero uses all
+// the cpus available.
+var parallelWorkers = 0
+
+func init() {
+	maze.RegisterGenerator("parallel", createParallelMaze)
+}
+
+// mazeRegion is a rectangle of cells from (x0,y0) included to (x1,y1)
+// excluded carved with its own randomness.
+type mazeRegion struct {
+	x0, y0, x1, y1 int
+	seed           int64
+}
+
+// createParallelMaze constructs the maze with the entrance on top row at
+// column inX and the exit on bottom row at column outX from the randomness
+// of <r> by carving its regions concurrently.
+func createParallelMaze(width, height int, r *rand.Rand, inX, outX int) *Grid {
+	maze := NewGrid(width, height)
+	cols := (width + PARALLEL_REGION - 1) / PARALLEL_REGION
+	rows := (height + PARALLEL_REGION - 1) / PARALLEL_REGION
+
+	// the seeds are drawn before carving so the workers order does not matter.
+	regions := make([]mazeRegion, 0, cols*rows)
+	for ry := 0; ry < rows; ry++ {
+		for rx := 0; rx < cols; rx++ {
+			region := mazeRegion{seed: r.Int63()}
+			region.x0, region.y0, region.x1, region.y1 = regionBounds(maze, rx, ry)
+			regions = append(regions, region)
+		}
+	}
+
+	workers := parallelWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	// each region only opens the walls of its own cells so the
+	// workers never write the same cells.
+	jobs := make(chan mazeRegion)
+	var carving sync.WaitGroup
+	for i := 0; i < workers && i < len(regions); i++ {
+		carving.Add(1)
+		go func() {
+			defer carving.Done()
+			for region := range jobs {
+				carveRegion(maze, region)
+			}
+		}()
+	}
+	for _, region := range regions {
+		jobs <- region
+	}
+	close(jobs)
+	carving.Wait()
+
+	stitchRegions(maze, cols, rows, r)
+
+	// open the entrance north wall and the outdoor south wall.
+	maze.Open(inX, 0, N)
+	maze.Open(outX, height-1, S)
+	return maze
+}
+
+// carveRegion digs a perfect maze into the cells of <region> with a
+// backtracker walking from a random cell of the region.
+func carveRegion(maze *Grid, region mazeRegion) {
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+	var randomDirections = [4]int{N, S, E, W}
+
+	r := seededRand(region.seed)
+	w, h := region.x1-region.x0, region.y1-region.y0
+	visited := make([]bool, w*h)
+	start := r.Intn(w * h)
+	visited[start] = true
+	// the stack holds the index of the cells into the region.
+	stack := []int32{int32(start)}
+
+	for len(stack) > 0 {
+		i := int(stack[len(stack)-1])
+		x, y := region.x0+i%w, region.y0+i/w
+
+		carved := false
+		shuffleDirection(r, &randomDirections)
+		for _, d := range randomDirections {
+			nX, nY := moveTo(x, y, d)
+			if nX < region.x0 || nX >= region.x1 || nY < region.y0 || nY >= region.y1 {
+				continue
+			}
+
+			j := (nY-region.y0)*w + nX - region.x0
+			if visited[j] {
+				continue
+			}
+
+			visited[j] = true
+			maze.Open(x, y, d)
+			maze.Open(nX, nY, oppositeDirections[d])
+			stack = append(stack, int32(j))
+			carved = true
+			break
+		}
+
+		if !carved {
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// stitchRegions joins the <cols> x <rows> regions of <maze> along a random
+// spanning tree with one passage at a random place of each border crossed.
+func stitchRegions(maze *Grid, cols, rows int, r *rand.Rand) {
+	var randomDirections = [4]int{N, S, E, W}
+	visited := make([]bool, cols*rows)
+	visited[0] = true
+	stack := []int{0}
+
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		rx, ry := i%cols, i/cols
+
+		joined := false
+		shuffleDirection(r, &randomDirections)
+		for _, d := range randomDirections {
+			nRX, nRY := moveTo(rx, ry, d)
+			if nRX < 0 || nRX >= cols || nRY < 0 || nRY >= rows || visited[nRY*cols+nRX] {
+				continue
+			}
+
+			visited[nRY*cols+nRX] = true
+			openBorder(maze, rx, ry, d, r)
+			stack = append(stack, nRY*cols+nRX)
+			joined = true
+			break
+		}
+
+		if !joined {
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// openBorder opens a passage at a random place of the border between the
+// region (rx,ry) of <maze> and its neighbour region in the direction <d>.
+func openBorder(maze *Grid, rx, ry, d int, r *rand.Rand) {
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+	x0, y0, x1, y1 := regionBounds(maze, rx, ry)
+
+	var x, y int
+	switch d {
+	case N, S:
+		x = x0 + r.Intn(x1-x0)
+		if y = y0; d == S {
+			y = y1 - 1
+		}
+	case E, W:
+		y = y0 + r.Intn(y1-y0)
+		if x = x0; d == W {
+			x = x1 - 1
+		}
+	}
+
+	nX, nY := moveTo(x, y, d)
+	maze.Open(x, y, d)
+	maze.Open(nX, nY, oppositeDirections[d])
+}
+
+// regionBounds returns the cells from (x0,y0) included to (x1,y1) excluded
+// of the region (rx,ry) of <maze>. The last regions of the rows and the
+// columns get the remaining cells.
+func regionBounds(maze *Grid, rx, ry int) (x0, y0, x1, y1 int) {
+	x0, y0 = rx*PARALLEL_REGION, ry*PARALLEL_REGION
+	x1, y1 = x0+PARALLEL_REGION, y0+PARALLEL_REGION
+	if x1 > maze.Width() {
+		x1 = maze.Width()
+	}
+	if y1 > maze.Height() {
+		y1 = maze.Height()
+	}
+	return x0, y0, x1, y1
+}
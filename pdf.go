@@ -0,0 +1,187 @@
+package main
+
+// This file writes printable PDF puzzle sheets. The mazes are laid out on
+// A4 pages with their titles and the answer pages follow at the back. The
+// document is built by hand with vector lines so no library is needed.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	// A4 page size and its margin in points.
+	PDF_PAGE_WIDTH  = 595.0
+	PDF_PAGE_HEIGHT = 842.0
+	PDF_MARGIN      = 40.0
+	// height of the titles of the pages and of the mazes.
+	PDF_HEADER = 30.0
+	PDF_TITLE  = 20.0
+	// maximum number of mazes on a single page.
+	PDF_MAX_PER_PAGE = 12
+)
+
+// pdfText escapes a string written into a content stream. The standard
+// fonts only cover latin characters so the other ones are replaced.
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteRune('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pdfMazeSheet holds the content stream of a page.
+type pdfMazeSheet struct {
+	bytes.Buffer
+}
+
+// text writes <s> at (x,y) with a font size.
+func (p *pdfMazeSheet) text(x, y, size float64, s string) {
+	fmt.Fprintf(p, "BT /F1 %.1f Tf %.2f %.2f Td (%s) Tj ET\n", size, x, y, pdfText(s))
+}
+
+// line adds a segment to the current path.
+func (p *pdfMazeSheet) line(x0, y0, x1, y1 float64) {
+	fmt.Fprintf(p, "%.2f %.2f m %.2f %.2f l\n", x0, y0, x1, y1)
+}
+
+// drawMaze draws the walls of <maze> centered into the box whose top left
+// corner is (bx,by) and optionally its solution path.
+func (p *pdfMazeSheet) drawMaze(maze *Grid, bx, by, bw, bh float64, withSolution bool) {
+	height, width := maze.Height(), maze.Width()
+	cs := math.Min(bw/float64(width), bh/float64(height))
+	ox := bx + (bw-cs*float64(width))/2
+	oy := by - (bh-cs*float64(height))/2
+
+	fmt.Fprintf(p, "0 0 0 RG %.2f w 2 J\n", math.Max(cs/12, 0.5))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := maze.At(x, y)
+			x0, y0 := ox+float64(x)*cs, oy-float64(y)*cs
+			if y == 0 && (cell&N) == 0 {
+				p.line(x0, y0, x0+cs, y0)
+			}
+			if (cell & S) == 0 {
+				p.line(x0, y0-cs, x0+cs, y0-cs)
+			}
+			// east side is on the left and west side on the right.
+			if (cell & E) == 0 {
+				p.line(x0, y0, x0, y0-cs)
+			}
+			if (cell & W) == 0 {
+				p.line(x0+cs, y0, x0+cs, y0-cs)
+			}
+		}
+	}
+	p.WriteString("S\n")
+
+	if !withSolution {
+		return
+	}
+
+	in, out := mazeDoors(maze)
+	path := solveMaze(maze, in, out)
+	if path == nil {
+		return
+	}
+
+	cells := append([][2]int{{in[0], in[1] - 1}}, path...)
+	cells = append(cells, [2]int{out[0], out[1] + 1})
+	fmt.Fprintf(p, "0.82 0.06 0.06 RG %.2f w 1 J 1 j\n", math.Max(cs/4, 0.5))
+	for i, cell := range cells {
+		x, y := ox+(float64(cell[0])+0.5)*cs, oy-(float64(cell[1])+0.5)*cs
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(p, "%.2f %.2f %s\n", x, y, op)
+	}
+	p.WriteString("S\n")
+}
+
+// layoutPages draws the <mazes> on as many pages as needed with <perPage>
+// mazes on each. The mazes are placed on a grid as square as possible.
+func layoutPages(mazes []*Grid, perPage int, header string, withSolution bool) []*pdfMazeSheet {
+	cols := int(math.Ceil(math.Sqrt(float64(perPage))))
+	rows := (perPage + cols - 1) / cols
+	slotW := (PDF_PAGE_WIDTH - 2*PDF_MARGIN) / float64(cols)
+	slotH := (PDF_PAGE_HEIGHT - 2*PDF_MARGIN - PDF_HEADER) / float64(rows)
+
+	var pages []*pdfMazeSheet
+	for first := 0; first < len(mazes); first += perPage {
+		page := &pdfMazeSheet{}
+		page.text(PDF_MARGIN, PDF_PAGE_HEIGHT-PDF_MARGIN-16, 16, header)
+
+		for i := first; i < len(mazes) && i < first+perPage; i++ {
+			slot := i - first
+			x := PDF_MARGIN + float64(slot%cols)*slotW
+			y := PDF_PAGE_HEIGHT - PDF_MARGIN - PDF_HEADER - float64(slot/cols)*slotH
+			maze := mazes[i]
+
+			title := fmt.Sprintf("Maze %d - %d x %d", i+1, maze.Width(), maze.Height())
+			if withSolution {
+				title += " - answer"
+			}
+			page.text(x+4, y-14, 11, title)
+			page.drawMaze(maze, x+8, y-PDF_TITLE, slotW-16, slotH-PDF_TITLE-8, withSolution)
+		}
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// writeMazesPDF writes a PDF document with the <mazes> laid out <perPage>
+// on each page under the <title> followed by the pages of their answers.
+func writeMazesPDF(w io.Writer, mazes []*Grid, perPage int, title string) error {
+	pages := layoutPages(mazes, perPage, title, false)
+	pages = append(pages, layoutPages(mazes, perPage, title+" - Answers", true)...)
+
+	// objects are numbered from 1: catalog, pages tree, font then
+	// a content stream followed by its page for each page.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var kids []string
+	for _, page := range pages {
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+		content := len(objects)
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>",
+			PDF_PAGE_WIDTH, PDF_PAGE_HEIGHT, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(doc.Bytes())
+	return err
+}
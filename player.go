@@ -0,0 +1,84 @@
+package main
+
+// This file draws the player position with a chosen glyph instead of the
+// bare terminal cursor. The glyph is a marker following the maze view
+// cursor so the moves and the walls checks are left unchanged. A double
+// width glyph, like most emoji, needs the 2 columns of the zoomed cells so
+// the small cells keep showing the terminal cursor.
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/awesome-gocui/gocui"
+	"github.com/mattn/go-runewidth"
+)
+
+const PLAYER = "player"
+
+// glyph chosen for the player. 0 means the terminal cursor.
+var playerGlyph rune
+
+// setupPlayerGlyph checks the glyph chosen for the player. An empty one
+// keeps the terminal cursor.
+func (gm *Game) setupPlayerGlyph(glyph string) error {
+	if glyph == "" {
+		playerGlyph = 0
+		return nil
+	}
+
+	r, size := utf8.DecodeRuneInString(glyph)
+	if size != len(glyph) || r == utf8.RuneError || !unicode.IsGraphic(r) || unicode.IsSpace(r) {
+		return fmt.Errorf("invalid player glyph %q. expected a single visible character", glyph)
+	}
+
+	if w := runewidth.RuneWidth(r); w < 1 || w > 2 {
+		return fmt.Errorf("invalid player glyph %q. expected a character of 1 or 2 columns", glyph)
+	}
+
+	playerGlyph = r
+	gm.player.glyph = r
+	return nil
+}
+
+// showPlayer displays or hides the player position.
+func (gm *Game) showPlayer(g *gocui.Gui, shown bool) {
+	var cx, cy int
+	if mv, err := g.View(MAZE); err == nil {
+		cx, cy = gm.mazeCursor(mv)
+	}
+	_ = gm.renderer.DrawPlayer(cx, cy, shown)
+}
+
+// playerGlyphFits tells if the player glyph can be drawn into the cells
+// displayed. A double width glyph would hide the wall next to small cells.
+func playerGlyphFits() bool {
+	return playerGlyph != 0 && (isZoomShown || runewidth.RuneWidth(playerGlyph) == 1)
+}
+
+// followPlayer moves the player glyph to the maze view cursor and hides
+// the terminal cursor while the glyph is drawn. It runs on each layout.
+func (gm *Game) followPlayer(g *gocui.Gui) {
+	if playerGlyph == 0 {
+		return
+	}
+
+	mv, err := g.View(MAZE)
+	drawn := err == nil && gm.playerShown && playerGlyphFits()
+	if cv := g.CurrentView(); err == nil && cv != nil && cv.Name() == MAZE {
+		g.Cursor = gm.playerShown && !drawn
+	}
+
+	if !drawn {
+		if markerViews[PLAYER] != nil {
+			gm.player.close(g)
+		}
+		return
+	}
+
+	cx, cy := gm.mazeCursor(mv)
+	if markerViews[PLAYER] == nil || gm.player.cx != cx || gm.player.cy != cy {
+		_ = gm.player.draw(g, cx, cy)
+	}
+}
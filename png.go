@@ -0,0 +1,172 @@
+package main
+
+// This file exports mazes as PNG images. Unlike the gif frames, the cell size
+// and the colors can be chosen and the solution path may be drawn over.
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// imageStyle describes how a maze is drawn on a PNG image.
+type imageStyle struct {
+	// size of each cell in pixels.
+	cell       int
+	background color.RGBA
+	wall       color.RGBA
+	path       color.RGBA
+}
+
+// default style of the PNG images.
+var defaultImageStyle = imageStyle{
+	cell:       CELL_PIXELS,
+	background: color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	wall:       color.RGBA{0x00, 0x00, 0x00, 0xFF},
+	path:       color.RGBA{0xD0, 0x10, 0x10, 0xFF},
+}
+
+// parseColor reads a color written as #rrggbb or rrggbb.
+func parseColor(hex string) (color.RGBA, error) {
+	var c color.RGBA
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return c, fmt.Errorf("invalid color %q. expected #rrggbb", hex)
+	}
+
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return c, fmt.Errorf("invalid color %q. expected #rrggbb", hex)
+	}
+
+	c.A = 0xFF
+	return c, nil
+}
+
+// renderMazePNG draws the walls of <maze> with the style <s> and its
+// solution path from the entrance to the exit <withSolution>.
+func renderMazePNG(maze *Grid, s imageStyle, withSolution bool) image.Image {
+	height, width := maze.Height(), maze.Width()
+	c := s.cell
+	// walls are a tenth of the cell and at least a pixel thick.
+	t := c / 10
+	if t < 1 {
+		t = 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, (width+2)*c+t, (height+2)*c+t))
+	draw.Draw(img, img.Bounds(), image.NewUniform(s.background), image.Point{}, draw.Src)
+
+	fill := func(x0, y0, x1, y1 int, col color.RGBA) {
+		draw.Draw(img, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := maze.At(x, y)
+			x0, y0 := (x+1)*c, (y+1)*c
+
+			// top wall is only drawn on first row except at the entrance.
+			if y == 0 && (cell&N) == 0 {
+				fill(x0, y0, x0+c+t, y0+t, s.wall)
+			}
+
+			if (cell & S) == 0 {
+				fill(x0, y0+c, x0+c+t, y0+c+t, s.wall)
+			}
+
+			// east side is on the left and west side on the right.
+			if (cell & E) == 0 {
+				fill(x0, y0, x0+t, y0+c+t, s.wall)
+			}
+
+			if (cell & W) == 0 {
+				fill(x0+c, y0, x0+c+t, y0+c+t, s.wall)
+			}
+		}
+	}
+
+	if !withSolution {
+		return img
+	}
+
+	in, out := mazeDoors(maze)
+	path := solveMaze(maze, in, out)
+	if path == nil {
+		return img
+	}
+
+	// extend the path through the doors then join the cells centers.
+	r := c / 8
+	if r < 1 {
+		r = 1
+	}
+	center := func(cell [2]int) (int, int) {
+		return (cell[0]+1)*c + c/2 + t/2, (cell[1]+1)*c + c/2 + t/2
+	}
+
+	cells := append([][2]int{{in[0], in[1] - 1}}, path...)
+	cells = append(cells, [2]int{out[0], out[1] + 1})
+	for i := 1; i < len(cells); i++ {
+		ax, ay := center(cells[i-1])
+		bx, by := center(cells[i])
+		if ax > bx {
+			ax, bx = bx, ax
+		}
+		if ay > by {
+			ay, by = by, ay
+		}
+		fill(ax-r, ay-r, bx+r+1, by+r+1, s.path)
+	}
+
+	return img
+}
+
+// writeMazePNG encodes the image of <maze> as PNG into <w>.
+func writeMazePNG(w io.Writer, maze *Grid, s imageStyle, withSolution bool) error {
+	if s.cell < 2 {
+		return errors.New("cell size must be at least 2 pixels")
+	}
+	return png.Encode(w, renderMazePNG(maze, s, withSolution))
+}
+
+// exportMazePNG writes the current maze as PNG image into the exports
+// folder of the data directory, the one of the player over ssh. Its
+// solution is drawn once the round is over.
+func (gm *Game) exportMazePNG(g *gocui.Gui, mv *gocui.View) error {
+	if gm.maze == nil {
+		return nil
+	}
+
+	fpath, err := exportPath(gm.id + ".png")
+	if err != nil {
+		logError("Failed to create exports folder", "err", err)
+		notify("Failed to create exports folder: %v", err)
+		return nil
+	}
+
+	file, err := os.Create(fpath)
+	if err != nil {
+		logError("Failed to create png file", "err", err)
+		notify("Failed to create png file: %v", err)
+		return nil
+	}
+	defer file.Close()
+
+	if err = writeMazePNG(file, gm.maze, defaultImageStyle, gm.over); err != nil {
+		logError("Failed to export maze as png", "err", err)
+		notify("Failed to export maze as png: %v", err)
+		return nil
+	}
+
+	logInfo("Exported maze as png", "file", fpath)
+	notify("Maze exported into %s", fpath)
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+// This file provides the popup views displayed at the center of the screen
+// over the maze like the leaderboard. The game is paused while displayed.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// displayPopupView displays <content> into a framed view named <name> titled
+// with the translation of <title> at the center of the screen and moves the
+// focus on it. The view is closed with the
+// Escape and Ctrl+Q keys or with <keys> which are expected to be its openers.
+func (gm *Game) displayPopupView(g *gocui.Gui, cv *gocui.View, name, title, content string, width int, keys ...interface{}) error {
+
+	if cv.Name() == MAZE {
+		gm.cursorX, gm.cursorY = gm.mazeCursor(cv)
+		if !gm.paused && !gm.over {
+			if err := gm.togglePause(g, cv); err != nil {
+				logError("Failed to pause the game before displaying view", "view", name, "err", err)
+				publishError(err)
+				return err
+			}
+		}
+	}
+
+	maxX, maxY := g.Size()
+	height := strings.Count(content, "\n") + 2
+	if height > maxY-2 {
+		height = maxY - 2
+	}
+
+	popupView, err := setView(g, name, (maxX-width)/2, (maxY-height)/2, (maxX+width)/2, (maxY+height)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to create view", "view", name, "err", err)
+		return err
+	}
+
+	popupView.Title = tr(title)
+	popupView.FgColor = gocui.ColorGreen
+	popupView.Editable = false
+	popupView.Wrap = false
+	clearView(popupView)
+	fmt.Fprint(popupView, content)
+
+	if _, err := g.SetCurrentView(name); err != nil {
+		logError("Failed to set focus", "view", name, "err", err)
+		return err
+	}
+	_, _ = g.SetViewOnTop(name)
+	g.Cursor = false
+
+	for _, k := range append(keys, gocui.KeyEsc, gocui.KeyCtrlQ) {
+		if err := g.SetKeybinding(name, k, gocui.ModNone, gm.closePopupView); err != nil {
+			logError("Failed to bind key to view", "key", k, "view", name, "err", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closePopupView closes a popup view and moves the focus
+// back to the maze view if any or the outputs view.
+func (gm *Game) closePopupView(g *gocui.Gui, pv *gocui.View) error {
+	g.DeleteKeybindings(pv.Name())
+	if err := g.DeleteView(pv.Name()); err != nil {
+		logError("Failed to delete view", "view", pv.Name(), "err", err)
+		return err
+	}
+
+	if _, err := g.View(MAZE); err != gocui.ErrUnknownView {
+		mv, err := g.SetCurrentView(MAZE)
+		if err != nil {
+			logError("Failed to set back focus on maze view", "err", err)
+			publishError(err)
+			return err
+		}
+
+		gm.setMazeCursor(mv, gm.cursorX, gm.cursorY)
+		return nil
+	}
+
+	return setFocusOnView(g, OUTPUTS)
+}
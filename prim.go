@@ -0,0 +1,74 @@
+package main
+
+// This file adds the randomized Prim algorithm through the generators
+// registry. It grows the maze from the entrance by opening, at each step,
+// a random cell of its frontier towards one of its visited neighbours.
+// The mazes get many short dead ends and are harder to read at a glance
+// than the long corridors of the backtracker.
+
+import (
+	"math/rand"
+
+	"github.com/jeamon/gomazes/maze"
+)
+
+func init() {
+	maze.RegisterGenerator("prim", createPrimMaze)
+}
+
+// createPrimMaze constructs the maze with the entrance on top row at column
+// inX and the exit on bottom row at column outX from the randomness of <r>.
+func createPrimMaze(width, height int, r *rand.Rand, inX, outX int) *Grid {
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+
+	maze := NewGrid(width, height)
+
+	visited := make([][]bool, height)
+	inFrontier := make([][]bool, height)
+	for y := range visited {
+		visited[y] = make([]bool, width)
+		inFrontier[y] = make([]bool, width)
+	}
+
+	var frontier [][2]int
+	visit := func(x, y int) {
+		visited[y][x] = true
+		for _, d := range [4]int{N, S, E, W} {
+			nX, nY := moveTo(x, y, d)
+			if nY >= 0 && nY < height && nX >= 0 && nX < width && !visited[nY][nX] && !inFrontier[nY][nX] {
+				inFrontier[nY][nX] = true
+				frontier = append(frontier, [2]int{nX, nY})
+			}
+		}
+	}
+
+	visit(inX, 0)
+	for len(frontier) > 0 {
+		// pick and remove a random frontier cell.
+		i := r.Intn(len(frontier))
+		cell := frontier[i]
+		frontier[i] = frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+		x, y := cell[0], cell[1]
+
+		// open it towards a random visited neighbour.
+		var ways []int
+		for _, d := range [4]int{N, S, E, W} {
+			nX, nY := moveTo(x, y, d)
+			if nY >= 0 && nY < height && nX >= 0 && nX < width && visited[nY][nX] {
+				ways = append(ways, d)
+			}
+		}
+		d := ways[r.Intn(len(ways))]
+		nX, nY := moveTo(x, y, d)
+		maze.Open(x, y, d)
+		maze.Open(nX, nY, oppositeDirections[d])
+
+		visit(x, y)
+	}
+
+	// open the entrance north wall and the outdoor south wall.
+	maze.Open(inX, 0, N)
+	maze.Open(outX, height-1, S)
+	return maze
+}
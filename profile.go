@@ -0,0 +1,55 @@
+package main
+
+// This file provides the profiling of the game in the field. The hidden
+// -pprof flag serves the net/http/pprof handlers at the given address while
+// playing, so the cpu and memory profiles and the execution traces of huge
+// mazes can be fetched with go tool pprof or go tool trace. The generation
+// and the drawing of the mazes are marked as regions into the traces.
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// flags working but not listed by the usage of the commands.
+var hiddenFlags = map[string]bool{"pprof": true}
+
+// startProfiling serves the profiles at <addr> like ":6060" until the
+// returned function is called. The listener is opened at once so a busy
+// address is reported to the caller.
+func startProfiling(addr string) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logError("Failed to serve profiles", "addr", addr, "err", err)
+		}
+	}()
+
+	logInfo("Serving profiles", "addr", ln.Addr().String())
+	return func() { server.Close() }, nil
+}
+
+// traceRegion marks the work named <name> into the execution
+// trace if any. The returned function ends the region.
+func traceRegion(name string) func() {
+	if !trace.IsEnabled() {
+		return func() {}
+	}
+	return trace.StartRegion(context.Background(), name).End
+}
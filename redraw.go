@@ -0,0 +1,139 @@
+package main
+
+// This file keeps the maze view up to date without rewriting it whole at
+// each frame. The renderer remembers the lines drawn and the features
+// changing the look of some cursor positions, like the trail, the solution
+// or the fog, mark them dirty. The next frame only rewrites those positions
+// while a new maze, a new style or a new view get drawn whole.
+
+import (
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// mazeFrame is what the maze view shows.
+type mazeFrame struct {
+	// view drawn with the lines of the maze and the window
+	// of them it shows split into glyphs.
+	view  *gocui.View
+	lines []string
+	runes [][]rune
+	// cursor position of the maze at the top left corner of the
+	// window and the size of the view.
+	ox, oy int
+	vx, vy int
+	// player position and fog radius of the frame.
+	cx, cy int
+	fog    int
+	// cursor positions to rewrite on the next frame.
+	dirty map[[2]int]bool
+	// set when the whole maze must be rewritten.
+	stale bool
+}
+
+// invalidate marks the cursor <positions> dirty, or the whole frame
+// when none is given.
+func (f *mazeFrame) invalidate(positions ...[2]int) {
+	if len(positions) == 0 {
+		f.stale = true
+		return
+	}
+
+	if f.dirty == nil {
+		f.dirty = make(map[[2]int]bool)
+	}
+	for _, pos := range positions {
+		f.dirty[pos] = true
+	}
+}
+
+// needsFull tells if the <lines> cannot be drawn into <mv> from the window
+// origin (ox, oy) with the <fog> radius by only rewriting the dirty
+// positions of the frame.
+func (f *mazeFrame) needsFull(mv *gocui.View, lines []string, ox, oy, fog int) bool {
+	if f.stale || f.view != mv || f.fog != fog || len(f.lines) != len(lines) || mv.LinesHeight() != len(f.runes) {
+		return true
+	}
+
+	if vx, vy := mv.Size(); f.ox != ox || f.oy != oy || f.vx != vx || f.vy != vy {
+		return true
+	}
+
+	for y := range lines {
+		if lines[y] != f.lines[y] {
+			return true
+		}
+	}
+	return false
+}
+
+// reset records the frame of the <lines> whose <window> from the origin
+// (ox, oy) is fully drawn into <mv> with the <fog> radius.
+func (f *mazeFrame) reset(mv *gocui.View, lines, window []string, ox, oy, cx, cy, fog int) {
+	f.view, f.lines = mv, lines
+	f.runes = make([][]rune, len(window))
+	for y, line := range window {
+		f.runes[y] = []rune(line)
+	}
+	f.ox, f.oy = ox, oy
+	f.vx, f.vy = mv.Size()
+	f.cx, f.cy, f.fog = cx, cy, fog
+	f.dirty, f.stale = nil, false
+}
+
+// moveFog marks dirty the positions entering or leaving the fog once the
+// player moved from the position of the frame to (cx, cy).
+func (f *mazeFrame) moveFog(cx, cy int) {
+	if f.fog == 0 || (f.cx == cx && f.cy == cy) {
+		return
+	}
+
+	for _, c := range [2][2]int{{f.cx, f.cy}, {cx, cy}} {
+		for y := c[1] - f.fog; y <= c[1]+f.fog; y++ {
+			for x := c[0] - 2*f.fog - 1; x <= c[0]+2*f.fog+1; x++ {
+				f.invalidate([2]int{x, y})
+			}
+		}
+	}
+	f.cx, f.cy = cx, cy
+}
+
+// drawGlyph writes into <b> the glyph of the cursor position (x, y) of
+// the <lines> painted or hidden by the fog around the player at (cx, cy).
+func (gm *Game) drawGlyph(b *strings.Builder, glyph rune, x, y, cx, cy int) {
+	// a cell is 2 columns wide and 1 line tall.
+	if gm.fog > 0 && (abs(x-cx) > 2*gm.fog+1 || abs(y-cy) > gm.fog) {
+		b.WriteByte(' ')
+		return
+	}
+	gm.writePainted(b, glyph, x, y)
+}
+
+// redrawDirty rewrites into <mv> the dirty positions of the frame
+// shown by its window with the cells of the game <gm>.
+func (f *mazeFrame) redrawDirty(gm *Game, mv *gocui.View) {
+	var b strings.Builder
+	for pos := range f.dirty {
+		x, y := pos[0]-f.ox, pos[1]-f.oy
+		if y < 0 || y >= len(f.runes) || x < 0 || x >= len(f.runes[y]) {
+			continue
+		}
+
+		b.Reset()
+		gm.drawGlyph(&b, f.runes[y][x], pos[0], pos[1], f.cx, f.cy)
+		if err := mv.SetWritePos(x, y); err != nil {
+			continue
+		}
+		mv.WriteString(b.String())
+	}
+	f.dirty = nil
+}
+
+// invalidateMaze marks the cursor <positions> of the maze view to redraw
+// on the next frame, or the whole maze when none is given.
+func (gm *Game) invalidateMaze(positions ...[2]int) {
+	if gm.renderer != nil {
+		gm.renderer.Invalidate(positions...)
+	}
+}
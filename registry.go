@@ -0,0 +1,43 @@
+package main
+
+// This file registers the builtin maze algorithms into the registries of
+// the maze package. The other generators register the same way from the
+// init function of their own file, like prim.go, or of their own package
+// imported by the program, and then show up in the algorithm picker of the
+// settings, in the config file and in the flags of the commands without
+// any change to the other files.
+
+import (
+	"github.com/jeamon/gomazes/maze"
+)
+
+// GeneratorFunc builds a maze. See maze.GeneratorFunc.
+type GeneratorFunc = maze.GeneratorFunc
+
+// SolverFunc returns the path between two cells. See maze.SolverFunc.
+type SolverFunc = maze.SolverFunc
+
+func init() {
+	maze.RegisterGenerator("backtracker", createMaze)
+	maze.RegisterSolver("bfs", solveMaze)
+}
+
+// findGenerator returns the generator registered under <name> if any.
+func findGenerator(name string) (GeneratorFunc, bool) {
+	return maze.Generator(name)
+}
+
+// findSolver returns the solver registered under <name> if any.
+func findSolver(name string) (SolverFunc, bool) {
+	return maze.Solver(name)
+}
+
+// generatorNames returns the sorted names of the registered generators.
+func generatorNames() []string {
+	return maze.Generators()
+}
+
+// solverNames returns the sorted names of the registered solvers.
+func solverNames() []string {
+	return maze.Solvers()
+}
@@ -0,0 +1,94 @@
+package main
+
+// This file renders mazes into paletted images. Each cell is drawn as a square
+// of CELL_PIXELS pixels surrounded by its closed walls, with one cell margin.
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+const CELL_PIXELS = 12
+
+// colors indexes into mazePalette.
+const (
+	backgroundColorIndex uint8 = iota
+	wallColorIndex
+	markerColorIndex
+)
+
+// mazePalette is shared by all rendered images.
+var mazePalette = color.Palette{
+	color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	color.RGBA{0x00, 0x00, 0x00, 0xFF},
+	color.RGBA{0xD0, 0x10, 0x10, 0xFF},
+}
+
+// renderMazeImage draws the walls of a maze into a new image
+// with the walls color of the given theme.
+func renderMazeImage(maze *Grid, t wallTheme) *image.Paletted {
+	height := maze.Height()
+	width := maze.Width()
+	c := CELL_PIXELS
+
+	palette := append(color.Palette{}, mazePalette...)
+	palette[wallColorIndex] = t.imageColor
+	img := image.NewPaletted(image.Rect(0, 0, (width+2)*c+1, (height+2)*c+1), palette)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			cell := maze.At(x, y)
+			x0, y0 := (x+1)*c, (y+1)*c
+
+			// top wall is only drawn on first row except at the entrance.
+			if y == 0 && (cell&N) == 0 {
+				drawLine(img, x0, y0, x0+c, y0)
+			}
+
+			if (cell & S) == 0 {
+				drawLine(img, x0, y0+c, x0+c, y0+c)
+			}
+
+			// east side is on the left and west side on the right.
+			if (cell & E) == 0 {
+				drawLine(img, x0, y0, x0, y0+c)
+			}
+
+			if (cell & W) == 0 {
+				drawLine(img, x0+c, y0, x0+c, y0+c)
+			}
+		}
+	}
+
+	return img
+}
+
+// drawLine draws an horizontal or vertical wall line.
+func drawLine(img *image.Paletted, x0, y0, x1, y1 int) {
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			img.SetColorIndex(x, y, wallColorIndex)
+		}
+	}
+}
+
+// cursorToPixel converts the maze view cursor coordinates into the center
+// pixel of the matching position. Odd columns are cells and even columns are
+// walls between them. The first line is the top wall holding the entrance.
+func cursorToPixel(cx, cy int) (int, int) {
+	c := CELL_PIXELS
+	return c + cx*c/2, cy*c + c/2
+}
+
+// markerRect returns the square area of the player marker at (cx, cy).
+func markerRect(cx, cy int) image.Rectangle {
+	px, py := cursorToPixel(cx, cy)
+	r := CELL_PIXELS / 4
+	return image.Rect(px-r, py-r, px+r+1, py+r+1)
+}
+
+// drawMarker fills the player marker at the cursor coordinates (cx, cy).
+func drawMarker(img draw.Image, cx, cy int) {
+	draw.Draw(img, markerRect(cx, cy), image.NewUniform(mazePalette[markerColorIndex]), image.Point{}, draw.Src)
+}
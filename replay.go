@@ -0,0 +1,64 @@
+package main
+
+// This file keeps the replay log of the current run. Each cursor position
+// taken by the player is recorded with the time elapsed since run started.
+
+import (
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// replayStep is a recorded cursor position of the maze view.
+type replayStep struct {
+	X, Y int
+	At   time.Duration
+}
+
+// runState holds the progress of the current run.
+type runState struct {
+	// positions taken and cursor positions walked since the run started.
+	log   []replayStep
+	trail map[[2]int]bool
+	// run clock which does not count paused durations.
+	// it is also read by the markers goroutines.
+	clock *Stopwatch
+	// moves made and collisions since the run started.
+	moves      int
+	collisions int
+	// set once the run is accounted into the statistics.
+	accounted bool
+}
+
+// restart clears the run to record a new one from now.
+func (r *runState) restart() {
+	r.clock.Reset()
+	r.clock.Start()
+
+	r.log = r.log[:0]
+	r.trail = make(map[[2]int]bool)
+	r.moves, r.collisions = 0, 0
+	r.accounted = false
+}
+
+// recordMove appends the current cursor position to the replay log.
+func (gm *Game) recordMove(mv *gocui.View) {
+	cx, cy := gm.mazeCursor(mv)
+	gm.run.log = append(gm.run.log, replayStep{X: cx, Y: cy, At: gm.runElapsed()})
+	gm.markTrail(cx, cy)
+}
+
+// pauseRunLog freezes the run clock.
+func (gm *Game) pauseRunLog() {
+	gm.run.clock.Pause()
+}
+
+// resumeRunLog restarts the run clock.
+func (gm *Game) resumeRunLog() {
+	gm.run.clock.Start()
+}
+
+// runElapsed returns the time played since the run started.
+func (gm *Game) runElapsed() time.Duration {
+	return gm.run.clock.Elapsed()
+}
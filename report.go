@@ -0,0 +1,122 @@
+package main
+
+// This file provides the JSON reports printed by the commands with the -json
+// flag. Field names are part of the command line interface and must stay.
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// cellReport is the coordinates of a cell.
+type cellReport struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// statsReport describes the shape of a maze.
+type statsReport struct {
+	Cells          int     `json:"cells"`
+	DeadEnds       int     `json:"dead_ends"`
+	Solvable       bool    `json:"solvable"`
+	SolutionLength int     `json:"solution_length"`
+	UniqueSolution bool    `json:"unique_solution"`
+	Difficulty     float64 `json:"difficulty"`
+	DecisionPoints int     `json:"decision_points"`
+	BranchFactor   float64 `json:"branch_factor"`
+	Score          int     `json:"score"`
+}
+
+// mazeReport is the JSON report of a maze. The seed and the algorithm
+// are only known for the generated mazes.
+type mazeReport struct {
+	File      string       `json:"file,omitempty"`
+	Width     int          `json:"width"`
+	Height    int          `json:"height"`
+	Seed      *int64       `json:"seed,omitempty"`
+	Algorithm string       `json:"algorithm,omitempty"`
+	Entrance  cellReport   `json:"entrance"`
+	Exit      cellReport   `json:"exit"`
+	Grid      [][]int      `json:"grid"`
+	Solution  []cellReport `json:"solution,omitempty"`
+	Moves     string       `json:"moves,omitempty"`
+	Stats     statsReport  `json:"stats"`
+}
+
+// structureReport is the JSON report of the structure of a maze printed
+// by the analyze command. The seed and the algorithm are only known for
+// the generated mazes.
+type structureReport struct {
+	File            string  `json:"file,omitempty"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	Seed            *int64  `json:"seed,omitempty"`
+	Algorithm       string  `json:"algorithm,omitempty"`
+	Cells           int     `json:"cells"`
+	DeadEnds        int     `json:"dead_ends"`
+	Junctions       int     `json:"junctions"`
+	LongestCorridor int     `json:"longest_corridor"`
+	Branches        int     `json:"branches"`
+	AverageBranch   float64 `json:"average_branch"`
+	Loops           int     `json:"loops"`
+}
+
+// newMazeReport describes <maze> with its stats. The solution path
+// is only included <withSolution>.
+func newMazeReport(maze *Grid, seed *int64, algorithm string, withSolution bool) *mazeReport {
+	width, height := maze.Width(), maze.Height()
+	in, out := mazeDoors(maze)
+	path := solveMaze(maze, in, out)
+	score := scoreMaze(maze)
+
+	report := &mazeReport{
+		Width:     width,
+		Height:    height,
+		Seed:      seed,
+		Algorithm: algorithm,
+		Entrance:  cellReport{in[0], in[1]},
+		Exit:      cellReport{out[0], out[1]},
+		Grid:      maze.Rows(),
+		Stats: statsReport{
+			Cells:          width * height,
+			DeadEnds:       countDeadEnds(maze),
+			Solvable:       path != nil,
+			SolutionLength: len(path),
+			UniqueSolution: hasUniqueSolution(maze),
+			Difficulty:     mazeDifficulty(maze, width, height),
+			DecisionPoints: score.decisionPoints,
+			BranchFactor:   score.branchFactor,
+			Score:          score.value,
+		},
+	}
+
+	if withSolution {
+		report.Solution = make([]cellReport, 0, len(path))
+		for _, cell := range path {
+			report.Solution = append(report.Solution, cellReport{cell[0], cell[1]})
+		}
+		report.Moves = solutionMoves(path)
+	}
+
+	return report
+}
+
+// countDeadEnds returns the number of cells with a single opening.
+func countDeadEnds(maze *Grid) int {
+	count := 0
+	for y := 0; y < maze.Height(); y++ {
+		for x := 0; x < maze.Width(); x++ {
+			if cell := maze.At(x, y); cell == N || cell == S || cell == E || cell == W {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// writeJSON writes <v> as indented JSON followed by a new line.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,129 @@
+package main
+
+// This file keeps the views in place when the terminal gets resized. The
+// outputs view and the bottom bar follow the layout while the maze and the
+// windows displayed over it are moved so they stay centered.
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// how a view follows the terminal size.
+const (
+	ANCHOR_CENTER = iota
+	ANCHOR_LEFT
+	ANCHOR_RIGHT
+	ANCHOR_TOP
+	ANCHOR_BOTTOM
+)
+
+var (
+	// terminal size of the previous layout. it starts with
+	// the size used to create the views.
+	layoutWidth, layoutHeight int
+
+	// views placed by the layout at each redraw.
+	layoutViews = map[string]bool{OUTPUTS: true, TIMER: true, POSITION: true, STATUS: true, SIZE: true, SEED: true, INFOS: true}
+
+	// views not centered on the terminal.
+	viewAnchors = map[string]int{DAILY: ANCHOR_LEFT, ANALYSIS: ANCHOR_RIGHT, MOVES: ANCHOR_TOP, TOAST: ANCHOR_BOTTOM}
+)
+
+// mazeViewPosition returns the coordinates of the maze view centered into
+// the outputs view of size (vx, vy). Along the axes where the maze is larger
+// than the outputs view, the view spans the outputs view and scrolls.
+func (gm *Game) mazeViewPosition(vx, vy int) (int, int, int, int) {
+	mx1, mx2 := (vx-(2*gm.width+2))/2, 0
+	my1, my2 := (vy-(gm.height+2))/2, 0
+	if mx1 < 0 {
+		mx1, mx2 = 0, vx+1
+	} else {
+		mx2 = mx1 + (2*gm.width + 2)
+	}
+	if my1 < 0 {
+		my1, my2 = 0, vy+1
+	} else {
+		my2 = my1 + (gm.height + 2)
+	}
+
+	return mx1, my1, mx2, my2
+}
+
+// followResize moves the views which are not placed by the layout when the
+// terminal size changed to (maxX, maxY). It runs once the outputs view got
+// its new size. The maze view is centered again, a scrolled one showing the
+// window of its new size around the player, and the markers drawn over it
+// move along. The other views keep their anchor on the terminal.
+func (gm *Game) followResize(g *gocui.Gui, maxX, maxY int) {
+	if layoutWidth == maxX && layoutHeight == maxY {
+		return
+	}
+
+	oldX, oldY := layoutWidth, layoutHeight
+	layoutWidth, layoutHeight = maxX, maxY
+
+	logDebug("Terminal resized", "from", fmt.Sprintf("%dx%d", oldX, oldY), "to", fmt.Sprintf("%dx%d", maxX, maxY))
+
+	if iv, err := g.View(INFOS); err == nil {
+		clearView(iv)
+		fmt.Fprint(iv, center(tr(INFOS_TEXT), maxX-SDWIDTH-2, " "))
+	}
+
+	if mv, err := g.View(MAZE); err == nil {
+		if ov, err := g.View(OUTPUTS); err == nil {
+			cx, cy := gm.mazeCursor(mv)
+			mx1, my1, mx2, my2 := gm.mazeViewPosition(ov.Size())
+			if _, err = setView(g, MAZE, mx1, my1, mx2, my2); err != nil {
+				logError("Failed to move maze view", "err", err)
+			} else if gm.maze != nil {
+				_ = gm.setMazeCursor(mv, cx, cy)
+				gm.drawMaze(mv)
+			}
+		}
+		// the zoomed view is centered too or removed when too large.
+		gm.applyZoom(g, mv)
+		redrawMarkers(g)
+	}
+
+	cdx, cdy := maxX/2-oldX/2, maxY/2-oldY/2
+	for _, v := range g.Views() {
+		name := v.Name()
+		if layoutViews[name] || name == MAZE || name == ZOOM || markerViews[name] != nil {
+			continue
+		}
+
+		dx, dy := cdx, cdy
+		switch viewAnchors[name] {
+		case ANCHOR_LEFT:
+			dx, dy = 0, 0
+		case ANCHOR_RIGHT:
+			dx, dy = maxX-oldX, 0
+		case ANCHOR_TOP:
+			dy = 0
+		case ANCHOR_BOTTOM:
+			dy = maxY - oldY
+		}
+
+		if dx == 0 && dy == 0 {
+			continue
+		}
+
+		x0, y0, x1, y1, err := g.ViewPosition(name)
+		if err != nil {
+			continue
+		}
+
+		if _, err = setView(g, name, x0+dx, y0+dy, x1+dx, y1+dy); err != nil {
+			logError("Failed to move view", "view", name, "err", err)
+		}
+	}
+
+	// the help follows the terminal height too.
+	if hv, err := g.View(HELP); err == nil {
+		if err = placeHelpView(g, hv); err != nil {
+			logError("Failed to place help view", "err", err)
+		}
+	}
+}
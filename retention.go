@@ -0,0 +1,169 @@
+package main
+
+// This file implements the retention policy of the saved sessions. The number
+// and the age of the sessions kept can be limited so the oldest ones are
+// pruned automatically after each save or on demand from the sessions list.
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+var (
+	// maximum number of saved sessions kept and maximum
+	// age of a saved session. zero means no limit.
+	maxSessions   = 0
+	maxSessionAge time.Duration
+)
+
+// listSavedFiles returns the saved sessions with their size and
+// last modification time sorted from the oldest to the newest.
+func listSavedFiles() ([]savedFile, error) {
+	files, err := sessionStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modified.Before(files[j].modified)
+	})
+
+	return files, nil
+}
+
+// expiredSessions returns the files among <files> (sorted oldest first) which
+// are beyond the retention limits at <now>. The session of the current maze
+// is never returned so a run in progress does not lose its save.
+func (gm *Game) expiredSessions(files []savedFile, now time.Time) []savedFile {
+	var expired, kept []savedFile
+	for _, file := range files {
+		if file.name == gm.id {
+			continue
+		}
+
+		if maxSessionAge > 0 && now.Sub(file.modified) > maxSessionAge {
+			expired = append(expired, file)
+			continue
+		}
+		kept = append(kept, file)
+	}
+
+	// the current session counts into the limit too.
+	total := len(kept)
+	if len(kept)+len(expired) < len(files) {
+		total++
+	}
+
+	if maxSessions > 0 && total > maxSessions {
+		extra := total - maxSessions
+		if extra > len(kept) {
+			extra = len(kept)
+		}
+		expired = append(expired, kept[:extra]...)
+	}
+
+	return expired
+}
+
+// removeSessions deletes the session <files> and returns
+// the number of bytes reclaimed.
+func removeSessions(files []savedFile) int64 {
+	var reclaimed int64
+	for _, file := range files {
+		if err := sessionStore.Delete(file.name); err != nil {
+			logError("Failed to remove session", "session", file.name, "err", err)
+			continue
+		}
+		reclaimed += file.size
+	}
+
+	return reclaimed
+}
+
+// pruneSessions removes the saved sessions beyond the retention limits.
+func (gm *Game) pruneSessions() {
+	if maxSessions <= 0 && maxSessionAge <= 0 {
+		return
+	}
+
+	files, err := listSavedFiles()
+	if err != nil {
+		logError("Failed to list sessions to prune", "err", err)
+		return
+	}
+
+	expired := gm.expiredSessions(files, time.Now())
+	if len(expired) == 0 {
+		return
+	}
+
+	reclaimed := removeSessions(expired)
+	logInfo("Pruned saved sessions", "count", len(expired), "reclaimed", formatBytes(reclaimed))
+}
+
+// formatBytes formats a number of bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// cleanupSessions asks to confirm the removal of the sessions beyond the
+// retention limits with the space to reclaim then refreshes the listview.
+// Without any limit set, the completed sessions are the ones cleaned up.
+func (gm *Game) cleanupSessions(g *gocui.Gui, lv *gocui.View) error {
+	files, err := listSavedFiles()
+	if err != nil {
+		logError("Failed to list sessions to clean up", "err", err)
+		return nil
+	}
+
+	var expired []savedFile
+	if maxSessions > 0 || maxSessionAge > 0 {
+		expired = gm.expiredSessions(files, time.Now())
+	} else {
+		completed := make(map[string]bool)
+		for _, entry := range gm.sessions.all {
+			completed[entry.name] = entry.completed
+		}
+
+		for _, file := range files {
+			if completed[file.name] && file.name != gm.id {
+				expired = append(expired, file)
+			}
+		}
+	}
+
+	if len(expired) == 0 {
+		lv.Title += tr("[nothing to clean up] ")
+		return nil
+	}
+
+	var size int64
+	for _, file := range expired {
+		size += file.size
+	}
+
+	question := trf("Remove %d sessions and reclaim %s?", len(expired), formatBytes(size))
+	return askConfirm(g, question, SESSIONS_LIST, func(g *gocui.Gui, yes bool) error {
+		if !yes {
+			return nil
+		}
+
+		reclaimed := removeSessions(expired)
+		logInfo("Cleaned up saved sessions", "count", len(expired), "reclaimed", formatBytes(reclaimed))
+		return gm.refreshSessionsList(g, lv)
+	}, nil)
+}
@@ -0,0 +1,70 @@
+package main
+
+// This file rates how hard a maze is to solve so the players can pick
+// comparable challenges. The score from 0 to 100 grows with the length of
+// the solution, the share of the maze it goes through, the choices met on
+// the way to the exit and the dead ends lying in wait for the wrong ones.
+
+import (
+	"math"
+)
+
+// mazeScore describes the difficulty of a maze.
+type mazeScore struct {
+	// cells of the shortest path from the entrance to the exit.
+	solutionLength int
+	// cells with a single opening.
+	deadEnds int
+	// cells of the solution offering more than one way forward.
+	decisionPoints int
+	// average number of ways forward at the decision points.
+	branchFactor float64
+	// overall difficulty from 0 to 100. 0 for an unsolvable maze.
+	value int
+}
+
+// scoreMaze rates the difficulty of <maze>.
+func scoreMaze(maze *Grid) mazeScore {
+	in, out := mazeDoors(maze)
+	path := solveMaze(maze, in, out)
+	score := mazeScore{solutionLength: len(path), deadEnds: countDeadEnds(maze), branchFactor: 1}
+	if path == nil {
+		return score
+	}
+
+	// the ways forward exclude the cell the player comes from.
+	ways := 0
+	for i, cell := range path {
+		forward := 0
+		for _, d := range [4]int{N, S, E, W} {
+			if hasPassage(maze, cell[0], cell[1], d) {
+				forward++
+			}
+		}
+		if i > 0 {
+			forward--
+		}
+
+		if forward > 1 {
+			score.decisionPoints++
+			ways += forward
+		}
+	}
+	if score.decisionPoints > 0 {
+		score.branchFactor = float64(ways) / float64(score.decisionPoints)
+	}
+
+	// each part is scaled to [0, 1] with the values of the hardest mazes.
+	// the lengths and the choices count on a log scale since doubling
+	// them does not make a maze twice harder.
+	cells := float64(maze.Width() * maze.Height())
+	length := math.Min(math.Log2(float64(len(path)))/12, 1)
+	decisions := math.Min(math.Log2(float64(1+score.decisionPoints))/9, 1)
+	coverage := math.Min(float64(len(path))/cells/0.5, 1)
+	deadEnds := math.Min(float64(score.deadEnds)/cells/0.3, 1)
+	branching := math.Min(math.Max(score.branchFactor-2, 0), 1)
+
+	value := 40*length + 35*decisions + 10*coverage + 10*deadEnds + 5*branching
+	score.value = int(math.Round(value))
+	return score
+}
@@ -0,0 +1,208 @@
+package main
+
+// This file handles the mazes larger than the outputs view. They are either
+// shrunk to fit like before or kept at the requested size while the maze view
+// scrolls so the player stays on screen. The mode comes from the -fit flag or
+// the settings, and the ask mode lets the player pick once the size exceeds.
+// A scrolled maze view only spans the outputs view and shows the window of
+// the maze around the player, so its cursor is the one of the maze minus the
+// window origin and only the window gets formatted when drawn.
+
+import (
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	// columns and lines kept between the player and the edges of the
+	// screen before the maze view scrolls.
+	SCROLL_MARGIN_X = 8
+	SCROLL_MARGIN_Y = 3
+)
+
+var (
+	// ways to handle the mazes larger than the screen.
+	mazeFitModes = []string{"ask", "shrink", "scroll"}
+	mazeFit      = "ask"
+	// way picked by the player in the ask mode.
+	mazeFitAnswer = ""
+)
+
+// isMazeFitMode tells if <name> is a way to handle the large mazes.
+func isMazeFitMode(name string) bool {
+	for _, m := range mazeFitModes {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// activeMazeFit returns the way the large mazes are handled, or ask
+// when the player did not pick one yet.
+func activeMazeFit() string {
+	if mazeFit == "ask" && mazeFitAnswer != "" {
+		return mazeFitAnswer
+	}
+	return mazeFit
+}
+
+// setMazeFit switches the way to handle the large mazes. The answer to
+// the previous question is forgotten.
+func setMazeFit(mode string) {
+	mazeFit, mazeFitAnswer = mode, ""
+}
+
+// changeMazeFit switches the way to handle the large mazes from the settings.
+func (gm *Game) changeMazeFit(g *gocui.Gui, step int) {
+	i := 0
+	for j, m := range mazeFitModes {
+		if m == mazeFit {
+			i = j
+		}
+	}
+
+	setMazeFit(mazeFitModes[stepIndex(i, len(mazeFitModes), step)])
+	if ov, err := g.View(OUTPUTS); err == nil {
+		gm.limitMazeSize(ov.Size())
+	}
+	gm.displayMazeSize(g)
+}
+
+// mazeFits tells if the default maze size fits into the outputs view of size (x, y).
+func (gm *Game) mazeFits(x, y int) bool {
+	return 2*gm.width < x && gm.height < y
+}
+
+// givenMazePlays tells if a maze of <width> x <height> cells which cannot be
+// shrunk, like an imported or shared one, plays into the outputs view of
+// size (x, y). It either fits or scrolls unless the player picked to shrink.
+func givenMazePlays(width, height, x, y int) bool {
+	return (2*width < x && height < y) || activeMazeFit() != "shrink"
+}
+
+// askMazeFit asks the player to shrink the default maze size to the
+// outputs view <ov> or to scroll, then displays a new maze.
+func (gm *Game) askMazeFit(g *gocui.Gui, ov *gocui.View) error {
+	x, y := ov.Size()
+	w, h := gm.width, gm.height
+	if 2*w >= x {
+		w = (x - 2) / 2
+	}
+	if h >= y {
+		h = y - 2
+	}
+
+	question := trf("The %d x %d maze exceeds the screen. Shrink it to %d x %d (n to scroll)?", gm.width, gm.height, w, h)
+	return askConfirm(g, question, OUTPUTS, func(g *gocui.Gui, yes bool) error {
+		mazeFitAnswer = "scroll"
+		if yes {
+			mazeFitAnswer = "shrink"
+		}
+		logInfo("Picked the way to fit the large mazes", "mode", mazeFitAnswer)
+		return gm.displayNewMaze(g, ov)
+	}, nil)
+}
+
+// scrollAxis returns the first position shown by a view of <room> positions
+// along an axis of the maze of <size> positions so the cursor at <pos> stays
+// <margin> positions away from the edges. The window shown from <start> only
+// moves once the cursor gets closer, and then centers it.
+func scrollAxis(start, pos, size, room, margin int) int {
+	if size <= room {
+		return 0
+	}
+
+	if margin > room/4 {
+		margin = room / 4
+	}
+
+	if at := pos - start; at < margin || at >= room-margin {
+		start = pos - room/2
+	}
+	return clamp(start, 0, size-room)
+}
+
+// mazeCursor returns the cursor position of the maze where the player
+// stands, which is the one of the maze view <mv> from the window origin.
+func (gm *Game) mazeCursor(mv *gocui.View) (int, int) {
+	cx, cy := mv.Cursor()
+	return cx + gm.scrollX, cy + gm.scrollY
+}
+
+// setMazeCursor places the player at the cursor position (cx, cy) of the
+// maze. The window shown by the maze view <mv> scrolls first when the
+// player would get too close to its edges. The maze drawn next follows.
+func (gm *Game) setMazeCursor(mv *gocui.View, cx, cy int) error {
+	vx, vy := mv.Size()
+	gm.scrollX = scrollAxis(gm.scrollX, cx, 2*gm.width+1, vx, SCROLL_MARGIN_X)
+	gm.scrollY = scrollAxis(gm.scrollY, cy, gm.height+1, vy, SCROLL_MARGIN_Y)
+	return setCursor(mv, cx-gm.scrollX, cy-gm.scrollY)
+}
+
+// moveMazeCursor moves the player by (dx, dy) from its position.
+func (gm *Game) moveMazeCursor(mv *gocui.View, dx, dy int) {
+	cx, cy := gm.mazeCursor(mv)
+	_ = gm.setMazeCursor(mv, cx+dx, cy+dy)
+}
+
+// isMazeScrolled tells if the maze view <mv> only shows
+// a window of the maze larger than it.
+func (gm *Game) isMazeScrolled(mv *gocui.View) bool {
+	vx, vy := mv.Size()
+	return 2*gm.width+1 > vx || gm.height+1 > vy
+}
+
+// isInMazeWindow tells if the cursor position (cx, cy) of the maze
+// is shown by the maze view.
+func (gm *Game) isInMazeWindow(g *gocui.Gui, cx, cy int) bool {
+	mv, err := g.View(MAZE)
+	if err != nil || isZoomShown {
+		return true
+	}
+
+	vx, vy := mv.Size()
+	x, y := cx-gm.scrollX, cy-gm.scrollY
+	return x >= 0 && x < vx && y >= 0 && y < vy
+}
+
+// mazeWindowLines returns the styled lines of the window of the current maze
+// from the cursor position (cx0, cy0) included to (cx1, cy1) excluded. Only
+// the window is formatted, with the columns around it and the line below it
+// since the walls style looks at the neighbors of each position.
+func (gm *Game) mazeWindowLines(cx0, cy0, cx1, cy1 int) []string {
+	// the walls style tells the columns apart by their parity.
+	x0 := (cx0 - 1) / 2 * 2
+	if x0 < 0 {
+		x0 = 0
+	}
+
+	window := formatMazeWindow(gm.maze, x0, cy0, cx1+1, cy1+1)
+	ascii := window.String()
+	lines := styleMazeLines(strings.Split(themeMaze(ascii, gm.theme), "\n"), ascii, wallStyles[currentWallStyle])
+	if len(lines) > cy1-cy0 {
+		lines = lines[:cy1-cy0]
+	}
+
+	for y, line := range lines {
+		glyphs := []rune(line)
+		from, to := cx0-x0, cx1-x0
+		if to > len(glyphs) {
+			to = len(glyphs)
+		}
+		if from > to {
+			from = to
+		}
+		lines[y] = string(glyphs[from:to])
+	}
+	return lines
+}
+
+// raiseInfoViews puts the info views back over the maze view so
+// they hide the lines of a maze larger than the outputs view.
+func raiseInfoViews(g *gocui.Gui) {
+	for _, name := range []string{TIMER, POSITION, STATUS, SIZE, SEED, INFOS} {
+		_, _ = g.SetViewOnTop(name)
+	}
+}
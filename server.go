@@ -0,0 +1,367 @@
+package main
+
+// This file implements the serve command. It exposes the maze engine over
+// a REST API so other applications can generate, fetch and solve mazes, and
+// with -ssh the game itself to remote players (see sshplay.go):
+//
+//	POST /mazes                      generate a maze from a JSON request
+//	GET  /mazes/{id}[.json|.txt|.png] fetch the maze as JSON, ascii or image
+//	GET  /mazes/{id}/solution[...]   fetch the maze with its solution
+//	GET  /algorithms                 list the generators and solvers
+//	GET  /                           play the mazes from a web browser
+//
+// The id of a maze is its share code, so the server keeps no state and
+// the mazes stay available after a restart. The format may also be given
+// with the format query parameter instead of the extension.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// bounds of the mazes dimensions accepted by the api.
+	SERVE_MAX_SIZE = 200
+	// bound of the width and height in pixels of the images served.
+	SERVE_MAX_PIXELS = 4096
+	// maximum size in bytes of a request body.
+	SERVE_MAX_BODY = 1 << 16
+	// time given to the ongoing requests once stopped.
+	SERVE_SHUTDOWN = 5 * time.Second
+)
+
+// mazeRequest is the body of a maze generation request. All the
+// fields are optional and default to the ones of the gen command.
+type mazeRequest struct {
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Seed      *int64  `json:"seed"`
+	Algorithm string  `json:"algorithm"`
+	Doors     string  `json:"doors"`
+	Braid     float64 `json:"braid"`
+}
+
+// mazeResource is the JSON representation of a maze served by the api.
+type mazeResource struct {
+	ID string `json:"id"`
+	*mazeReport
+	Links map[string]string `json:"links"`
+}
+
+// apiError is the body of the failed requests.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// runServe serves the REST api and the games over ssh until interrupted.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "serve [flags]", "serve the generation, the export and the solving of mazes over a REST api and the game over ssh")
+	addr := fs.String("http", ":8080", "address to listen on for the http requests (empty for none)")
+	sshAddr := fs.String("ssh", "", "address like :2222 to listen on for the players connecting with ssh (default none)")
+	sshMax := fs.Int("ssh-max", 16, "maximum number of ssh players at once")
+	dir := fs.String("dir", "", "directory to keep the ssh host key and the files of the ssh players (default per-user directories)")
+	level := fs.String("log-level", "info", "lowest level of the logs printed: "+strings.Join(logLevelNames, "/"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	minLevel, err := parseLogLevel(*level)
+	if err != nil {
+		return err
+	}
+	setLogLevel(minLevel)
+
+	if *addr == "" && *sshAddr == "" {
+		return errors.New("nothing to serve. give the -http or the -ssh address")
+	}
+
+	if *sshMax < 1 {
+		return fmt.Errorf("invalid ssh-max %d", *sshMax)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, 2)
+	var shutdowns []func(context.Context) error
+
+	if *addr != "" {
+		server := &http.Server{
+			Addr:              *addr,
+			Handler:           logRequests(newAPIHandler()),
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      30 * time.Second,
+		}
+		shutdowns = append(shutdowns, server.Shutdown)
+
+		go func() {
+			logInfo("Serving the maze api", "addr", *addr, "version", appVersion())
+			failed <- server.ListenAndServe()
+		}()
+	}
+
+	if *sshAddr != "" {
+		if err = setupDirs(*dir); err != nil {
+			return err
+		}
+
+		server, err := newSSHServer(*sshAddr, *sshMax)
+		if err != nil {
+			return err
+		}
+		shutdowns = append(shutdowns, server.Shutdown)
+
+		go func() {
+			logInfo("Serving the game over ssh", "addr", *sshAddr, "version", appVersion())
+			failed <- server.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err = <-failed:
+	case <-ctx.Done():
+	}
+
+	logInfo("Stopping the servers")
+	shutdown, cancel := context.WithTimeout(context.Background(), SERVE_SHUTDOWN)
+	defer cancel()
+	for _, fn := range shutdowns {
+		fn(shutdown)
+	}
+	return err
+}
+
+// newAPIHandler returns the handler routing the requests of the api.
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mazes", handleCreateMaze)
+	mux.HandleFunc("/mazes/", handleGetMaze)
+	mux.HandleFunc("/algorithms", handleAlgorithms)
+	mux.Handle("/", newWebHandler())
+	return mux
+}
+
+// handleCreateMaze generates the maze described by the request body.
+func handleCreateMaze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	req := mazeRequest{}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, SERVE_MAX_BODY))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && err != io.EOF {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+
+	if req.Width == 0 {
+		req.Width = 15
+	}
+	if req.Height == 0 {
+		req.Height = 10
+	}
+	if req.Algorithm == "" {
+		req.Algorithm = "backtracker"
+	}
+	if req.Doors == "" {
+		req.Doors = "center"
+	}
+	if req.Seed == nil {
+		seed := time.Now().UnixNano()
+		req.Seed = &seed
+	}
+
+	if req.Width < CLI_MIN_SIZE || req.Width > SERVE_MAX_SIZE || req.Height < CLI_MIN_SIZE || req.Height > SERVE_MAX_SIZE {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("maze size %d x %d out of [%d, %d]", req.Width, req.Height, CLI_MIN_SIZE, SERVE_MAX_SIZE))
+		return
+	}
+
+	if req.Braid < 0 || req.Braid > 1 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("braid %v out of [0, 1]", req.Braid))
+		return
+	}
+
+	maze, err := generateMazeWith(req.Width, req.Height, *req.Seed, req.Algorithm, req.Doors, req.Braid)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := encodeShareCode(maze, *req.Seed, req.Algorithm, req.Braid)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	resource := newMazeResource(id, newMazeReport(maze, req.Seed, req.Algorithm, false))
+	w.Header().Set("Location", resource.Links["self"])
+	writeAPIJSON(w, http.StatusCreated, resource)
+}
+
+// handleGetMaze writes the maze of the path /mazes/{id} with its solution
+// when the path ends with /solution, in the format asked.
+func handleGetMaze(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/mazes/"), "/")
+	if len(parts) > 2 || (len(parts) == 2 && strings.TrimSuffix(parts[1], extension(parts[1])) != "solution") {
+		writeAPIError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	withSolution := len(parts) == 2
+	last := parts[len(parts)-1]
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = strings.TrimPrefix(extension(last), ".")
+	}
+	id := strings.TrimSuffix(parts[0], extension(parts[0]))
+
+	width, height, err := shareCodeSize(id)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("unknown maze: %v", err))
+		return
+	}
+	if width > SERVE_MAX_SIZE || height > SERVE_MAX_SIZE {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("maze size %d x %d out of [%d, %d]", width, height, CLI_MIN_SIZE, SERVE_MAX_SIZE))
+		return
+	}
+
+	maze, seed, err := decodeShareCode(id)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("unknown maze: %v", err))
+		return
+	}
+
+	switch format {
+	case "", "json":
+		resource := newMazeResource(id, newMazeReport(maze, &seed, "", withSolution))
+		if withSolution && !resource.Stats.Solvable {
+			writeAPIError(w, http.StatusUnprocessableEntity, errors.New("maze has no solution"))
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, resource)
+
+	case "txt", "text", "ascii":
+		data := formatMaze(maze, maze.Width(), maze.Height())
+		text := data.String()
+		if withSolution {
+			if text, err = annotateSolution(maze, text, solveMaze); err != nil {
+				writeAPIError(w, http.StatusUnprocessableEntity, err)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, text+"\n")
+
+	case "png":
+		style := defaultImageStyle
+		if cell := r.URL.Query().Get("cell"); cell != "" {
+			if style.cell, err = strconv.Atoi(cell); err != nil || style.cell < 2 || style.cell > 64 {
+				writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid cell size %q", cell))
+				return
+			}
+		}
+		if (width+2)*style.cell > SERVE_MAX_PIXELS || (height+2)*style.cell > SERVE_MAX_PIXELS {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("image of %d x %d cells of %d pixels exceeds %d pixels", width, height, style.cell, SERVE_MAX_PIXELS))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		if err = writeMazePNG(w, maze, style, withSolution); err != nil {
+			logError("Failed to write maze image", "id", id, "err", err)
+		}
+
+	default:
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("unknown format %q. expected json, ascii or png", format))
+	}
+}
+
+// handleAlgorithms lists the names of the registered generators and solvers.
+func handleAlgorithms(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeAPIError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, map[string][]string{
+		"generators": generatorNames(),
+		"solvers":    solverNames(),
+	})
+}
+
+// newMazeResource returns the resource of the maze <id> described by <report>.
+func newMazeResource(id string, report *mazeReport) *mazeResource {
+	self := "/mazes/" + id
+	return &mazeResource{
+		ID:         id,
+		mazeReport: report,
+		Links: map[string]string{
+			"self":     self,
+			"ascii":    self + ".txt",
+			"png":      self + ".png",
+			"solution": self + "/solution",
+		},
+	}
+}
+
+// extension returns the extension of the last element of <path> if any.
+func extension(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[i:]
+	}
+	return ""
+}
+
+// writeAPIJSON writes <v> as JSON with the http <status>.
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := writeJSON(w, v); err != nil {
+		logError("Failed to write response", "err", err)
+	}
+}
+
+// writeAPIError writes <err> as the JSON body of the http <status>.
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeAPIJSON(w, status, apiError{Error: err.Error()})
+}
+
+// statusRecorder keeps the status written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs each request served by <next> with its status and duration.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		logInfo("Served request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start))
+	})
+}
@@ -0,0 +1,309 @@
+package main
+
+// This file defines the format of the saved sessions. A session is stored
+// as gzip compressed and versioned JSON carrying the maze grid with its
+// dimensions so it can be restored whatever the current maze size is. The
+// sessions of the huge mazes are stored in binary (see sessionbin.go).
+// Sessions saved by the older versions as plain JSON or as cursor position
+// followed by the ascii maze are still read and migrated (see sessionversion.go).
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	SESSIONS_FOLDER = "savedsessions"
+	SEWIDTH         = 70
+	// trailer carrying the sha256 of the bytes before it.
+	SESSION_CHECKSUM = "\nsha256:"
+)
+
+// savedSession is the content of a saved session file.
+type savedSession struct {
+	Version   int     `json:"version"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Seed      int64   `json:"seed"`
+	Algorithm string  `json:"algorithm"`
+	Grid      [][]int `json:"grid"`
+	CursorX   int     `json:"cursor_x"`
+	CursorY   int     `json:"cursor_y"`
+	ElapsedMs int64   `json:"elapsed_ms"`
+	Moves     int     `json:"moves"`
+	Label     string  `json:"label,omitempty"`
+	Completed bool    `json:"completed,omitempty"`
+	// difficulty score of the maze from 0 to 100.
+	Difficulty int `json:"difficulty,omitempty"`
+	// version of the program which saved the session.
+	Producer string `json:"producer,omitempty"`
+}
+
+// currentSession captures the current maze and the progress of the
+// player with the cursor position of the maze view.
+func (gm *Game) currentSession(mv *gocui.View) *savedSession {
+	cx, cy := gm.mazeCursor(mv)
+	return &savedSession{
+		Version:    SESSION_VERSION,
+		Width:      gm.maze.Width(),
+		Height:     gm.maze.Height(),
+		Seed:       gm.seed,
+		Algorithm:  mazeAlgorithm,
+		Grid:       gm.maze.Rows(),
+		CursorX:    cx,
+		CursorY:    cy,
+		ElapsedMs:  gm.clock.Elapsed().Milliseconds(),
+		Moves:      gm.run.moves,
+		Label:      gm.label,
+		Completed:  gm.over && gm.isAtExit(mv),
+		Difficulty: gm.score.value,
+		Producer:   appVersion(),
+	}
+}
+
+// saveSession writes the session of the current maze with
+// the cursor position of the maze view without throttling.
+func (gm *Game) saveSession(mv *gocui.View) error {
+	if gm.maze == nil {
+		return nil
+	}
+
+	if err := writeSession(gm.id, gm.currentSession(mv)); err != nil {
+		logError("Failed to save session file", "err", err)
+		notify("Failed to save game: %v", err)
+		return err
+	}
+
+	gm.lastSave = time.Now()
+	notify("Game saved")
+	events.publish(gameEvent{kind: EVENT_SAVE})
+	gm.pruneSessions()
+	return nil
+}
+
+// completeSession flags the saved session of the current maze as
+// completed once the exit is reached. Unsaved mazes are left as is.
+func (gm *Game) completeSession(mv *gocui.View) {
+	if _, err := sessionStore.Load(gm.id); err != nil {
+		return
+	}
+
+	gm.saveSession(mv)
+}
+
+// labelSession sets the label of the saved session named <id>.
+func labelSession(id, label string) error {
+	s, err := loadSession(id)
+	if err != nil {
+		return err
+	}
+
+	s.Label = label
+	return writeSession(id, s)
+}
+
+// writeSession encodes the session <s> as gzip compressed JSON, or in
+// binary for the huge mazes, and saves it into the sessions store with <id>.
+func writeSession(id string, s *savedSession) error {
+	if isBinarySession(s) {
+		data := encodeBinarySession(s)
+		sum := sha256.Sum256(data)
+		data = append(data, SESSION_CHECKSUM+hex.EncodeToString(sum[:])...)
+		return sessionStore.Save(id, data)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err = zw.Write(data); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(compressed.Bytes())
+	compressed.WriteString(SESSION_CHECKSUM + hex.EncodeToString(sum[:]))
+	return sessionStore.Save(id, compressed.Bytes())
+}
+
+// verifyChecksum checks and strips the checksum trailer of <data>. Files
+// written before checksums were added have no trailer and are kept as is.
+func verifyChecksum(data []byte) ([]byte, error) {
+	i := bytes.LastIndex(data, []byte(SESSION_CHECKSUM))
+	if i < 0 || len(data)-i-len(SESSION_CHECKSUM) != 2*sha256.Size {
+		return data, nil
+	}
+
+	payload, expected := data[:i], string(data[i+len(SESSION_CHECKSUM):])
+	sum := sha256.Sum256(payload)
+	if hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrSaveCorrupt)
+	}
+
+	return payload, nil
+}
+
+// readSessionData returns the content of a stored session <data> once its
+// checksum is verified. Compressed data are detected with the gzip magic
+// number and inflated while plain data written by older versions are
+// returned as is.
+func readSessionData(data []byte) ([]byte, error) {
+	data, err := verifyChecksum(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSaveCorrupt, err)
+	}
+	defer zr.Close()
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSaveCorrupt, err)
+	}
+
+	return content, nil
+}
+
+// loadSession reads the session <id>. A session saved with an older
+// format is migrated and rewritten with the current format.
+func loadSession(id string) (*savedSession, error) {
+	data, err := sessionStore.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s, migrated, err := decodeSession(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if migrated {
+		if err = writeSession(id, s); err != nil {
+			logError("Failed to rewrite migrated session file", "err", err)
+		}
+	}
+
+	return s, nil
+}
+
+// decodeSession decodes the stored session <data> and migrates it to the
+// current version. It tells if the session was saved with an older format.
+func decodeSession(data []byte) (*savedSession, bool, error) {
+	data, err := readSessionData(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	s := &savedSession{}
+	switch {
+	case bytes.HasPrefix(data, []byte(SESSION_BINARY_MAGIC)):
+		if s, err = decodeBinarySession(data); errors.Is(err, ErrSaveVersion) {
+			return nil, false, err
+		} else if err != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrSaveCorrupt, err)
+		}
+
+	case !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")):
+		if s, err = parseLegacySession(data); err != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrSaveCorrupt, err)
+		}
+
+	default:
+		if err = json.Unmarshal(data, s); err != nil {
+			return nil, false, fmt.Errorf("%w: %v", ErrSaveCorrupt, err)
+		}
+	}
+
+	// the fields of the newer versions may not be understood.
+	if s.Version > SESSION_VERSION {
+		return nil, false, errSessionVersion(s)
+	}
+
+	if s.Height <= 0 || s.Width <= 0 || len(s.Grid) != s.Height {
+		return nil, false, fmt.Errorf("%w: wrong maze dimensions", ErrSaveCorrupt)
+	}
+
+	for _, row := range s.Grid {
+		if len(row) != s.Width {
+			return nil, false, fmt.Errorf("%w: wrong maze dimensions", ErrSaveCorrupt)
+		}
+	}
+
+	migrated, err := migrateSession(s)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s, migrated, nil
+}
+
+// parseLegacySession reads a session saved by the older versions. The
+// first line contains the cursor coordinates (x, y) optionally followed by
+// the time played in ms and the moves made. The next lines are the maze.
+// These sessions have no version so they are migrated from the first one.
+func parseLegacySession(data []byte) (*savedSession, error) {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	xy := strings.Fields(strings.TrimSpace(line))
+	if len(xy) != 2 && len(xy) != 4 {
+		return nil, errors.New("wrong coordinates values")
+	}
+
+	s := &savedSession{}
+	if s.CursorX, err = strconv.Atoi(xy[0]); err != nil {
+		return nil, errors.New("wrong X coordinates value")
+	}
+
+	if s.CursorY, err = strconv.Atoi(xy[1]); err != nil {
+		return nil, errors.New("wrong Y coordinates value")
+	}
+
+	if len(xy) == 4 {
+		if s.ElapsedMs, err = strconv.ParseInt(xy[2], 10, 64); err != nil {
+			return nil, errors.New("wrong elapsed time value")
+		}
+
+		if s.Moves, err = strconv.Atoi(xy[3]); err != nil {
+			return nil, errors.New("wrong moves count value")
+		}
+	}
+
+	mazeData := string(data[len(line):])
+	maze, err := parseMaze(mazeData)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Grid = maze.Rows()
+	s.Height, s.Width = maze.Height(), maze.Width()
+	s.Seed = hashSeed(mazeData)
+	return s, nil
+}
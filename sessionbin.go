@@ -0,0 +1,180 @@
+package main
+
+// This file provides the compact binary format of the saved sessions. The
+// JSON of a huge maze takes long to write and to parse, so the sessions of
+// the mazes of at least SESSION_BINARY_CELLS cells are saved as a header of
+// varints followed by the walls of the cells packed two per byte. The
+// format is recognized by its magic when loading whatever the settings.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// first bytes of a binary session.
+	SESSION_BINARY_MAGIC = "GMZS"
+	// cells of the mazes saved in binary in the auto format.
+	SESSION_BINARY_CELLS = 250000
+)
+
+var (
+	// formats of the saved sessions picked with -save-format.
+	sessionFormatNames = []string{"auto", "json", "binary"}
+	sessionFormat      = "auto"
+)
+
+// isSessionFormat tells if <name> is a format of the saved sessions.
+func isSessionFormat(name string) bool {
+	for _, f := range sessionFormatNames {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinarySession tells if the session <s> is saved in binary.
+func isBinarySession(s *savedSession) bool {
+	switch sessionFormat {
+	case "binary":
+		return true
+	case "json":
+		return false
+	}
+	return s.Width*s.Height >= SESSION_BINARY_CELLS
+}
+
+// encodeBinarySession returns the binary format of the session <s>.
+func encodeBinarySession(s *savedSession) []byte {
+	buf := make([]byte, 0, 64+len(s.Label)+len(s.Producer)+(s.Width*s.Height+1)/2)
+	buf = append(buf, SESSION_BINARY_MAGIC...)
+	buf = appendUvarint(buf, uint64(s.Version))
+	buf = appendUvarint(buf, uint64(s.Width))
+	buf = appendUvarint(buf, uint64(s.Height))
+	buf = appendVarint(buf, s.Seed)
+	buf = appendString(buf, s.Algorithm)
+	buf = appendVarint(buf, int64(s.CursorX))
+	buf = appendVarint(buf, int64(s.CursorY))
+	buf = appendVarint(buf, s.ElapsedMs)
+	buf = appendVarint(buf, int64(s.Moves))
+	buf = appendString(buf, s.Label)
+	buf = appendString(buf, s.Producer)
+	if s.Completed {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = appendUvarint(buf, uint64(s.Difficulty))
+
+	// each cell fits into 4 bits so two cells per byte.
+	var b byte
+	for y, row := range s.Grid {
+		for x, cell := range row {
+			i := y*s.Width + x
+			if i%2 == 0 {
+				b = byte(cell & 0x0f)
+			} else {
+				buf = append(buf, b|byte(cell&0x0f)<<4)
+			}
+		}
+	}
+	if (s.Width*s.Height)%2 == 1 {
+		buf = append(buf, b)
+	}
+
+	return buf
+}
+
+// decodeBinarySession reads the session saved in binary into <data>.
+func decodeBinarySession(data []byte) (*savedSession, error) {
+	if !bytes.HasPrefix(data, []byte(SESSION_BINARY_MAGIC)) {
+		return nil, errors.New("not a binary session")
+	}
+
+	r := bufio.NewReader(bytes.NewReader(data[len(SESSION_BINARY_MAGIC):]))
+	s := &savedSession{}
+	var err error
+	uvarint := func() int {
+		var n uint64
+		if err == nil {
+			n, err = binary.ReadUvarint(r)
+		}
+		return int(n)
+	}
+	varint := func() int64 {
+		var n int64
+		if err == nil {
+			n, err = binary.ReadVarint(r)
+		}
+		return n
+	}
+	str := func() string {
+		n := uvarint()
+		if err == nil && n > len(data) {
+			err = errors.New("string longer than the data")
+		}
+		if err != nil {
+			return ""
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return string(b)
+	}
+
+	s.Version = uvarint()
+	if err == nil && s.Version > SESSION_VERSION {
+		// the layout of the newer versions is unknown.
+		return nil, errSessionVersion(s)
+	}
+	s.Width, s.Height = uvarint(), uvarint()
+	s.Seed = varint()
+	s.Algorithm = str()
+	s.CursorX, s.CursorY = int(varint()), int(varint())
+	s.ElapsedMs = varint()
+	s.Moves = int(varint())
+	s.Label = str()
+	s.Producer = str()
+	var completed byte
+	if err == nil {
+		completed, err = r.ReadByte()
+	}
+	s.Completed = completed == 1
+	// the difficulty follows since the version 2.
+	if s.Version >= 2 {
+		s.Difficulty = uvarint()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("truncated header: %v", err)
+	}
+
+	if s.Width <= 0 || s.Height <= 0 || s.Width > 2*len(data) || s.Height > 2*len(data) || s.Width*s.Height > 2*len(data) {
+		return nil, errors.New("wrong maze dimensions")
+	}
+
+	cells := make([]byte, (s.Width*s.Height+1)/2)
+	if _, err = io.ReadFull(r, cells); err != nil {
+		return nil, errors.New("truncated grid")
+	}
+
+	s.Grid = make([][]int, s.Height)
+	for y := range s.Grid {
+		s.Grid[y] = make([]int, s.Width)
+		for x := range s.Grid[y] {
+			i := y*s.Width + x
+			s.Grid[y][x] = int(cells[i/2]>>(4*(i%2))) & 0x0f
+		}
+	}
+
+	return s, nil
+}
+
+// appendString appends the length of <s> as varint then <s> to <buf>.
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
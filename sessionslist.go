@@ -0,0 +1,422 @@
+package main
+
+// This file provides the helpers of the saved sessions listview which
+// allows to pick a session to replay or to remove it.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	SESSIONS_LIST   = "listview"
+	SESSIONS_SEARCH = "sessionsearch"
+	LISTWIDTH       = 80
+)
+
+// sessionEntry describes a saved session into the listview.
+type sessionEntry struct {
+	name          string
+	width, height int
+	elapsed       time.Duration
+	completed     bool
+	difficulty    int
+	corrupt       bool
+	modified      time.Time
+	label         string
+}
+
+// orders and filters of the listview.
+var (
+	sessionSorts   = []string{"date", "size", "time", "difficulty"}
+	sessionFilters = []string{"all", "playing", "done"}
+)
+
+// sessionsList holds all saved sessions and the ones displayed in the
+// order of the listview lines with the current order, filter and the
+// text searched into the sessions names, labels and sizes.
+type sessionsList struct {
+	all    []sessionEntry
+	listed []sessionEntry
+	sort   int
+	filter int
+	query  string
+}
+
+// loadSessionEntries reads the details of each saved session. The
+// sessions which cannot be read are listed without their details.
+func loadSessionEntries() ([]sessionEntry, error) {
+	files, err := sessionStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+
+	entries := make([]sessionEntry, 0, len(files))
+	for _, file := range files {
+		filename := file.name
+		entry := sessionEntry{name: filename, modified: file.modified}
+		if s, err := loadSession(filename); err == nil {
+			entry.width, entry.height = s.Width, s.Height
+			entry.elapsed = time.Duration(s.ElapsedMs) * time.Millisecond
+			entry.completed = s.Completed
+			entry.label = s.Label
+			entry.difficulty = s.Difficulty
+		} else {
+			logError("Failed to read session", "session", filename, "err", err)
+			entry.corrupt = errors.Is(err, ErrSaveCorrupt)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// line formats the entry as a listview line. Dots of the names are
+// displayed as colons since they separate time units.
+func (e sessionEntry) line(index int) string {
+	status := "playing"
+	if e.corrupt {
+		status = "corrupt"
+	} else if e.completed {
+		status = "done"
+	}
+
+	label := ""
+	if e.label != "" {
+		label = "| " + e.label
+	}
+
+	return fmt.Sprintf(" [%02d] %-22s %7s %3d %s %s %s %s",
+		index, strings.ReplaceAll(e.name, ".", ":"), fmt.Sprintf("%dx%d", e.width, e.height), e.difficulty,
+		formatSeconds(int64(e.elapsed/time.Second)), padText(tr(status), 7), e.modified.Format("01-02 15:04"), label)
+}
+
+// matches tells if the name, the label or the size of the
+// entry contains <query> whatever the letters case.
+func (e sessionEntry) matches(query string) bool {
+	if query == "" {
+		return true
+	}
+
+	query = strings.ToLower(query)
+	for _, field := range []string{strings.ReplaceAll(e.name, ".", ":"), e.label, fmt.Sprintf("%dx%d", e.width, e.height)} {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// arrangeSessions returns the entries kept by the current search
+// and filter sorted with the current order.
+func (gm *Game) arrangeSessions(entries []sessionEntry) []sessionEntry {
+	var arranged []sessionEntry
+	for _, entry := range entries {
+		if !entry.matches(gm.sessions.query) {
+			continue
+		}
+
+		switch sessionFilters[gm.sessions.filter] {
+		case "playing":
+			if entry.completed {
+				continue
+			}
+		case "done":
+			if !entry.completed {
+				continue
+			}
+		}
+		arranged = append(arranged, entry)
+	}
+
+	sort.SliceStable(arranged, func(i, j int) bool {
+		a, b := arranged[i], arranged[j]
+		switch sessionSorts[gm.sessions.sort] {
+		case "size":
+			return a.width*a.height < b.width*b.height
+		case "time":
+			return a.elapsed < b.elapsed
+		case "difficulty":
+			return a.difficulty < b.difficulty
+		}
+		// most recent first.
+		return a.modified.After(b.modified)
+	})
+
+	return arranged
+}
+
+// fillSessionsList writes the sessions kept by the current filter with
+// their details into the listview sorted with the current order.
+func (gm *Game) fillSessionsList(lv *gocui.View, entries []sessionEntry) {
+	clearView(lv)
+	gm.sessions.all = entries
+	gm.sessions.listed = gm.arrangeSessions(entries)
+	lv.Title = trf(" Select A Session To Replay [sort: %s] [filter: %s] ",
+		sessionSorts[gm.sessions.sort], sessionFilters[gm.sessions.filter])
+	if gm.sessions.query != "" {
+		lv.Title += trf("[search: %s] ", gm.sessions.query)
+	}
+
+	if len(gm.sessions.listed) == 0 {
+		fmt.Fprintln(lv, " "+tr("No session matching the search or filter."))
+		return
+	}
+
+	for i, entry := range gm.sessions.listed {
+		fmt.Fprintln(lv, entry.line(i+1))
+	}
+}
+
+// cycleSessionsSort switches the listview to the next order.
+func (gm *Game) cycleSessionsSort(g *gocui.Gui, lv *gocui.View) error {
+	gm.sessions.sort = (gm.sessions.sort + 1) % len(sessionSorts)
+	gm.fillSessionsList(lv, gm.sessions.all)
+	return gm.selectSession(lv, 0)
+}
+
+// cycleSessionsFilter switches the listview to the next filter.
+func (gm *Game) cycleSessionsFilter(g *gocui.Gui, lv *gocui.View) error {
+	gm.sessions.filter = (gm.sessions.filter + 1) % len(sessionFilters)
+	gm.fillSessionsList(lv, gm.sessions.all)
+	return gm.selectSession(lv, 0)
+}
+
+// selectedIndex returns the index of the highlighted session.
+func selectedIndex(lv *gocui.View) int {
+	_, cy := lv.Cursor()
+	_, oy := lv.Origin()
+	return cy + oy
+}
+
+// selectSession highlights the session at <index> which is kept into
+// the list bounds. The list scrolls so the session stays visible.
+func (gm *Game) selectSession(lv *gocui.View, index int) error {
+	if index >= len(gm.sessions.listed) {
+		index = len(gm.sessions.listed) - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+
+	_, height := lv.Size()
+	_, oy := lv.Origin()
+	if index < oy {
+		oy = index
+	} else if index >= oy+height {
+		oy = index - height + 1
+	}
+
+	if err := lv.SetOrigin(0, oy); err != nil {
+		return err
+	}
+	return setCursor(lv, 0, index-oy)
+}
+
+// sessionsPage returns the number of sessions shown at once.
+func sessionsPage(lv *gocui.View) int {
+	_, height := lv.Size()
+	if height < 1 {
+		return 1
+	}
+	return height
+}
+
+// sessionCursorDown highlights the next session.
+func (gm *Game) sessionCursorDown(g *gocui.Gui, lv *gocui.View) error {
+	return gm.selectSession(lv, selectedIndex(lv)+1)
+}
+
+// sessionCursorUp highlights the previous session.
+func (gm *Game) sessionCursorUp(g *gocui.Gui, lv *gocui.View) error {
+	return gm.selectSession(lv, selectedIndex(lv)-1)
+}
+
+// sessionPageDown highlights the session one page below.
+func (gm *Game) sessionPageDown(g *gocui.Gui, lv *gocui.View) error {
+	return gm.selectSession(lv, selectedIndex(lv)+sessionsPage(lv))
+}
+
+// sessionPageUp highlights the session one page above.
+func (gm *Game) sessionPageUp(g *gocui.Gui, lv *gocui.View) error {
+	return gm.selectSession(lv, selectedIndex(lv)-sessionsPage(lv))
+}
+
+// sessionHome highlights the first session.
+func (gm *Game) sessionHome(g *gocui.Gui, lv *gocui.View) error {
+	return gm.selectSession(lv, 0)
+}
+
+// sessionEnd highlights the last session.
+func (gm *Game) sessionEnd(g *gocui.Gui, lv *gocui.View) error {
+	return gm.selectSession(lv, len(gm.sessions.listed)-1)
+}
+
+// selectedSession returns the name of the session highlighted in the listview.
+func (gm *Game) selectedSession(lv *gocui.View) (string, error) {
+	index := selectedIndex(lv)
+	if index < 0 || index >= len(gm.sessions.listed) {
+		return "", errors.New("no session at this line")
+	}
+
+	return gm.sessions.listed[index].name, nil
+}
+
+// labelSelectedSession asks for the label of the session highlighted
+// in the listview then saves it and refreshes the list in place.
+func (gm *Game) labelSelectedSession(g *gocui.Gui, lv *gocui.View) error {
+	session, err := gm.selectedSession(lv)
+	if err != nil {
+		logError("Cannot label current focused session", "err", err)
+		return nil
+	}
+
+	s, err := loadSession(session)
+	if err != nil {
+		logError("Failed to load session to label", "err", err)
+		return nil
+	}
+
+	return askInput(g, " Session Label ", s.Label, SESSIONS_LIST, func(g *gocui.Gui, label string) error {
+		if err := labelSession(session, label); err != nil {
+			logError("Failed to label session", "err", err)
+			return nil
+		}
+		return gm.refreshSessionsList(g, lv)
+	}, nil)
+}
+
+// deleteSelectedSession asks to confirm then removes the session file
+// highlighted in the listview and refreshes the list in place.
+func (gm *Game) deleteSelectedSession(g *gocui.Gui, lv *gocui.View) error {
+	session, err := gm.selectedSession(lv)
+	if err != nil {
+		logError("Cannot delete current focused session", "err", err)
+		return nil
+	}
+
+	question := trf("Delete session %s?", strings.ReplaceAll(session, ".", ":"))
+	return askConfirm(g, question, SESSIONS_LIST, func(g *gocui.Gui, yes bool) error {
+		if !yes {
+			return nil
+		}
+
+		if err := sessionStore.Delete(session); err != nil {
+			logError("Failed to delete session", "err", err)
+			return nil
+		}
+
+		return gm.refreshSessionsList(g, lv)
+	}, nil)
+}
+
+// refreshSessionsList reloads the sessions into the listview and keeps
+// the highlight on the same line. The listview closes once empty.
+func (gm *Game) refreshSessionsList(g *gocui.Gui, lv *gocui.View) error {
+	entries, err := loadSessionEntries()
+	if err != nil {
+		logError("Failed to list saved sessions", "err", err)
+		return nil
+	}
+
+	if len(entries) == 0 {
+		return gm.closeListView(g, lv)
+	}
+
+	gm.fillSessionsList(lv, entries)
+	_ = gm.selectSession(lv, selectedIndex(lv))
+
+	return nil
+}
+
+// startSessionsSearch opens a search line over the listview. The
+// sessions are narrowed down live at each character typed. Enter
+// keeps the search while Escape clears it, both back to the list.
+func (gm *Game) startSessionsSearch(g *gocui.Gui, lv *gocui.View) error {
+	x0, y0, x1, y1, err := g.ViewPosition(SESSIONS_LIST)
+	if err != nil {
+		return nil
+	}
+
+	// place the search line above the listview if there is room.
+	sy := y0 - 3
+	if sy < 0 {
+		sy = y1
+	}
+
+	searchView, err := setView(g, SESSIONS_SEARCH, x0, sy, x1, sy+2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display sessions search view", "err", err)
+		return err
+	}
+
+	searchView.Title = tr(" Search ")
+	searchView.Frame = true
+	searchView.FgColor = gocui.ColorYellow
+	searchView.Editable = true
+	clearView(searchView)
+	fmt.Fprint(searchView, gm.sessions.query)
+	setCursor(searchView, len(gm.sessions.query), 0)
+	searchView.Editor = gocui.EditorFunc(func(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gocui.DefaultEditor.Edit(v, key, ch, mod)
+		gm.sessions.query = strings.TrimSpace(v.Buffer())
+		gm.fillSessionsList(lv, gm.sessions.all)
+		_ = gm.selectSession(lv, 0)
+	})
+
+	if _, err = g.SetCurrentView(SESSIONS_SEARCH); err != nil {
+		logError("Failed to set focus on sessions search view", "err", err)
+		return err
+	}
+	_, _ = g.SetViewOnTop(SESSIONS_SEARCH)
+	g.Cursor = true
+
+	keep := func(g *gocui.Gui, v *gocui.View) error {
+		return gm.closeSessionsSearch(g, lv, false)
+	}
+
+	discard := func(g *gocui.Gui, v *gocui.View) error {
+		return gm.closeSessionsSearch(g, lv, true)
+	}
+
+	if err = g.SetKeybinding(SESSIONS_SEARCH, gocui.KeyEnter, gocui.ModNone, keep); err != nil {
+		logError("Failed to bind Enter key to sessions search view", "err", err)
+		return err
+	}
+
+	if err = g.SetKeybinding(SESSIONS_SEARCH, gocui.KeyEsc, gocui.ModNone, discard); err != nil {
+		logError("Failed to bind Esc key to sessions search view", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// closeSessionsSearch removes the search line and moves the focus back
+// to the listview. With <reset>, the search is cleared.
+func (gm *Game) closeSessionsSearch(g *gocui.Gui, lv *gocui.View, reset bool) error {
+	g.Cursor = false
+	g.DeleteKeybindings(SESSIONS_SEARCH)
+	if err := g.DeleteView(SESSIONS_SEARCH); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete sessions search view", "err", err)
+	}
+
+	if reset && gm.sessions.query != "" {
+		gm.sessions.query = ""
+		gm.fillSessionsList(lv, gm.sessions.all)
+		_ = gm.selectSession(lv, 0)
+	}
+
+	_, err := g.SetCurrentView(SESSIONS_LIST)
+	return err
+}
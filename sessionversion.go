@@ -0,0 +1,79 @@
+package main
+
+// This file keeps the saved sessions loading as their format evolves. Each
+// session carries the version of the format it was saved with, whatever its
+// encoding: compressed JSON, plain JSON of the older versions, binary or the
+// legacy text. Once decoded, a session is brought up to the current version
+// by the migrations of each version in turn, then saved again. Raising the
+// version means adding the migration from the previous one below.
+
+import (
+	"fmt"
+)
+
+const (
+	// version of the saved sessions format written.
+	SESSION_VERSION = 2
+)
+
+// sessionMigrations upgrade a session of the version of their index to the
+// next version. There is one per version before the current one.
+var sessionMigrations = []func(s *savedSession) error{
+	migrateUnversionedSession,
+	migrateSessionDifficulty,
+}
+
+func init() {
+	if len(sessionMigrations) != SESSION_VERSION {
+		panic(fmt.Sprintf("%d sessions migrations for the version %d", len(sessionMigrations), SESSION_VERSION))
+	}
+}
+
+// errSessionVersion returns the error of the session <s> saved by a newer
+// version of the program with a format this one cannot read.
+func errSessionVersion(s *savedSession) error {
+	if s.Producer != "" {
+		return fmt.Errorf("%w: version %d saved by gomazes %s (this is %s reading up to %d)", ErrSaveVersion, s.Version, s.Producer, appVersion(), SESSION_VERSION)
+	}
+	return fmt.Errorf("%w: version %d (this is %s reading up to %d)", ErrSaveVersion, s.Version, appVersion(), SESSION_VERSION)
+}
+
+// migrateSession brings the session <s> of a version not newer than the
+// current one up to it. It tells if the session was migrated.
+func migrateSession(s *savedSession) (bool, error) {
+	if s.Version < 0 {
+		return false, fmt.Errorf("%w: wrong version %d", ErrSaveCorrupt, s.Version)
+	}
+
+	from := s.Version
+	for s.Version < SESSION_VERSION {
+		if err := sessionMigrations[s.Version](s); err != nil {
+			return false, fmt.Errorf("%w: cannot migrate version %d: %v", ErrSaveCorrupt, s.Version, err)
+		}
+		s.Version++
+	}
+
+	if from < SESSION_VERSION {
+		logDebug("Migrated session", "from", from, "to", s.Version)
+	}
+	return from < SESSION_VERSION, nil
+}
+
+// migrateUnversionedSession upgrades the sessions saved before the
+// sessions had a version: the cursor position followed by the ascii maze
+// and the plain JSON without algorithm, all built by the backtracker.
+func migrateUnversionedSession(s *savedSession) error {
+	if s.Algorithm == "" {
+		s.Algorithm = "backtracker"
+	}
+	return nil
+}
+
+// migrateSessionDifficulty scores the mazes of the sessions saved before
+// the difficulty was kept with them.
+func migrateSessionDifficulty(s *savedSession) error {
+	if s.Difficulty == 0 {
+		s.Difficulty = scoreMaze(GridFromRows(s.Grid)).value
+	}
+	return nil
+}
@@ -0,0 +1,449 @@
+package main
+
+// This file provides the settings screen and the settings file. The screen
+// lists the size, difficulty, algorithm, colors, walls, autosave interval, key
+// scheme, language and bell cues of the game. Each change applies at once and all of them
+// are saved into the config.yaml file of the config directory when it closes,
+// so they are restored on next runs. The command line flags still prevail. The
+// bindings line opens the keymap screen which keeps its own keys file.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	SETTINGS = "settings"
+	// name of the file keeping the settings into the config directory.
+	CONFIG_FILE = "config.yaml"
+	// settings file of the older versions read when there is no config file.
+	SETTINGS_FILE = "settings"
+	SETWIDTH      = 50
+)
+
+// gameSetting is a line of the settings screen. The left and right arrows
+// call change with -1 or +1 while Enter calls edit if any, else change.
+type gameSetting struct {
+	name   string
+	value  func() string
+	change func(g *gocui.Gui, step int)
+	edit   func(g *gocui.Gui) error
+}
+
+var (
+	// intervals between automatic saves of the played maze. 0 means off.
+	autosaveIntervals = []time.Duration{0, 30 * time.Second, time.Minute, 2 * time.Minute, 5 * time.Minute}
+	currentAutosave   = 0
+
+	// lines of the settings screen. set by setupSettings since
+	// some of them change the game played.
+	gameSettings []gameSetting
+)
+
+// setupSettings sets the lines of the settings screen of the game <gm>.
+func setupSettings(gm *Game) {
+	gameSettings = []gameSetting{
+		{"Size", gm.sizeSetting, nil, gm.editSizeSetting},
+		{"Difficulty", difficultySetting, gm.changeDifficulty, nil},
+		{"Algorithm", func() string { return mazeAlgorithm }, changeAlgorithm, nil},
+		{"Colors", func() string { return scheme().name }, gm.changeColors, nil},
+		{"Walls", func() string { return wallStyles[currentWallStyle].name }, gm.changeWalls, nil},
+		{"Fit", activeMazeFit, gm.changeMazeFit, nil},
+		{"Autosave", autosaveSetting, gm.changeAutosave, nil},
+		{"Keys", func() string { return keySchemes[currentKeyScheme].name }, gm.changeKeys, nil},
+		{"Language", localeSetting, gm.changeLocale, nil},
+	}
+	gameSettings = append(gameSettings, bellSettings()...)
+	gameSettings = append(gameSettings, gameSetting{"Bindings", bindingsSetting, nil, gm.displayKeymapView})
+}
+
+// stepIndex returns the index <i> moved by <step> into a list of <n> values.
+func stepIndex(i, n, step int) int {
+	return ((i+step)%n + n) % n
+}
+
+// sizeSetting returns the default maze size.
+func (gm *Game) sizeSetting() string {
+	return fmt.Sprintf("%d x %d", gm.width, gm.height)
+}
+
+// editSizeSetting asks the default maze size.
+func (gm *Game) editSizeSetting(g *gocui.Gui) error {
+	return askInput(g, " Size (width x height) ", gm.sizeSetting(), SETTINGS, func(g *gocui.Gui, text string) error {
+		if text == "" {
+			return nil
+		}
+
+		if ov, err := g.View(OUTPUTS); err == nil {
+			x, y := ov.Size()
+			gm.setupMazeSize(text, x, y)
+		}
+		gm.displayMazeSize(g)
+		drawSettings(g)
+		return nil
+	}, nil)
+}
+
+// difficultySetting returns the difficulty preset in use.
+func difficultySetting() string {
+	if currentPreset == "" {
+		return "custom"
+	}
+	return currentPreset
+}
+
+// changeDifficulty switches the difficulty preset. The custom
+// settings come before the presets.
+func (gm *Game) changeDifficulty(g *gocui.Gui, step int) {
+	i := 0
+	for j, p := range difficultyPresets {
+		if p.name == currentPreset {
+			i = j + 1
+		}
+	}
+
+	i = stepIndex(i, len(difficultyPresets)+1, step)
+	if i == 0 {
+		clearPreset()
+		return
+	}
+
+	p := difficultyPresets[i-1]
+	applyPreset(p)
+	gm.width, gm.height = p.width, p.height
+	if ov, err := g.View(OUTPUTS); err == nil {
+		gm.limitMazeSize(ov.Size())
+	}
+	gm.displayMazeSize(g)
+}
+
+// changeAlgorithm switches the algorithm of next mazes. The
+// difficulty becomes custom with its other rules kept.
+func changeAlgorithm(g *gocui.Gui, step int) {
+	names := generatorNames()
+	i := sort.SearchStrings(names, mazeAlgorithm)
+	if i == len(names) {
+		i = 0
+	}
+	mazeAlgorithm = names[stepIndex(i, len(names), step)]
+	currentPreset = ""
+}
+
+// changeColors switches the color scheme.
+func (gm *Game) changeColors(g *gocui.Gui, step int) {
+	currentColorScheme = stepIndex(currentColorScheme, len(colorSchemes), step)
+	refreshOutputsTitle(g)
+	gm.applyColorScheme(g)
+}
+
+// changeWalls switches the walls style.
+func (gm *Game) changeWalls(g *gocui.Gui, step int) {
+	currentWallStyle = stepIndex(currentWallStyle, len(wallStyles), step)
+	gm.applyWallStyle(g)
+}
+
+// autosaveSetting returns the interval between automatic saves.
+func autosaveSetting() string {
+	interval := autosaveIntervals[currentAutosave]
+	if interval == 0 {
+		return "off"
+	}
+	return interval.String()
+}
+
+// changeAutosave switches the interval between automatic saves.
+func (gm *Game) changeAutosave(g *gocui.Gui, step int) {
+	currentAutosave = stepIndex(currentAutosave, len(autosaveIntervals), step)
+	gm.scheduleAutosave()
+}
+
+// changeKeys switches the movement keys scheme. The schemes needing
+// keys already bound are skipped.
+func (gm *Game) changeKeys(g *gocui.Gui, step int) {
+	for i := stepIndex(currentKeyScheme, len(keySchemes), step); i != currentKeyScheme; i = stepIndex(i, len(keySchemes), step) {
+		if gm.switchKeyScheme(g, i) {
+			return
+		}
+	}
+}
+
+// scheduleAutosave sets the time of the next automatic save.
+func (gm *Game) scheduleAutosave() {
+	gm.autosaveDue = time.Now().Add(autosaveIntervals[currentAutosave])
+}
+
+// autosave saves the played maze once the autosave interval elapsed.
+func (gm *Game) autosave(mv *gocui.View) {
+	if autosaveIntervals[currentAutosave] == 0 || !gm.isUnfinished() || time.Now().Before(gm.autosaveDue) {
+		return
+	}
+
+	gm.scheduleAutosave()
+	_ = gm.saveSession(mv)
+}
+
+// displaySettingsView opens the settings screen over the outputs view.
+func (gm *Game) displaySettingsView(g *gocui.Gui, v *gocui.View) error {
+	maxX, maxY := g.Size()
+	height := len(gameSettings) + 3
+	settingsView, err := setView(g, SETTINGS, (maxX-SETWIDTH)/2, (maxY-height)/2, (maxX+SETWIDTH)/2, (maxY+height)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display settings view", "err", err)
+		return err
+	}
+
+	settingsView.Frame = true
+	settingsView.FgColor = gocui.ColorYellow
+	settingsView.SelBgColor = gocui.ColorGreen
+	settingsView.SelFgColor = gocui.ColorBlack
+	settingsView.Highlight = true
+	settingsView.Editable = false
+
+	if _, err = g.SetCurrentView(SETTINGS); err != nil {
+		logError("Failed to set focus on settings view", "err", err)
+		return err
+	}
+	_, _ = g.SetViewOnTop(SETTINGS)
+	g.Cursor = false
+	setCursor(settingsView, 0, 0)
+	drawSettings(g)
+
+	bindings := map[gocui.Key]func(g *gocui.Gui, v *gocui.View) error{
+		gocui.KeyArrowUp:    moveSettingsCursor(-1),
+		gocui.KeyArrowDown:  moveSettingsCursor(1),
+		gocui.KeyArrowLeft:  changeSetting(-1),
+		gocui.KeyArrowRight: changeSetting(1),
+		gocui.KeyEnter:      editSetting,
+		gocui.KeyEsc:        gm.closeSettingsView,
+		gocui.KeyCtrlQ:      gm.closeSettingsView,
+		gocui.KeyCtrlE:      gm.closeSettingsView,
+	}
+	for key, handler := range bindings {
+		if err = g.SetKeybinding(SETTINGS, key, gocui.ModNone, handler); err != nil {
+			logError("Failed to bind key to settings view", "key", key, "err", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drawSettings writes the settings lines with their values.
+func drawSettings(g *gocui.Gui) {
+	settingsView, err := g.View(SETTINGS)
+	if err != nil {
+		return
+	}
+
+	// the title follows the language picked from the settings.
+	settingsView.Title = tr(" Settings [↕ select - ↔ change - Esc close] ")
+	clearView(settingsView)
+	for _, s := range gameSettings {
+		fmt.Fprintf(settingsView, " %s %s\n", padText(tr(s.name), 12), s.value())
+	}
+}
+
+// moveSettingsCursor returns the handler selecting the setting <step> lines away.
+func moveSettingsCursor(step int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		_, cy := v.Cursor()
+		return setCursor(v, 0, stepIndex(cy, len(gameSettings), step))
+	}
+}
+
+// changeSetting returns the handler changing the selected setting by <step>.
+func changeSetting(step int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		_, cy := v.Cursor()
+		if s := gameSettings[cy]; s.change != nil {
+			s.change(g, step)
+			drawSettings(g)
+		}
+		return nil
+	}
+}
+
+// editSetting edits the selected setting or switches to its next value.
+func editSetting(g *gocui.Gui, v *gocui.View) error {
+	_, cy := v.Cursor()
+	if s := gameSettings[cy]; s.edit != nil {
+		return s.edit(g)
+	}
+	return changeSetting(1)(g, v)
+}
+
+// closeSettingsView closes the settings screen and saves the settings.
+func (gm *Game) closeSettingsView(g *gocui.Gui, v *gocui.View) error {
+	g.DeleteKeybindings(SETTINGS)
+	if err := g.DeleteView(SETTINGS); err != nil {
+		logError("Failed to delete settings view", "err", err)
+		return err
+	}
+
+	if err := gm.saveSettings(); err != nil {
+		logError("Failed to save settings file", "err", err)
+		notify("Failed to save settings: %v", err)
+	}
+
+	return setFocusOnView(g, OUTPUTS)
+}
+
+// saveSettings writes the settings into the config file as flat yaml.
+func (gm *Game) saveSettings() error {
+	var b strings.Builder
+	b.WriteString("# gomazes config. command line flags prevail.\n")
+	fmt.Fprintf(&b, "size: %dx%d\n", gm.width, gm.height)
+	fmt.Fprintf(&b, "difficulty: %s\n", difficultySetting())
+	fmt.Fprintf(&b, "algorithm: %s\n", mazeAlgorithm)
+	fmt.Fprintf(&b, "colors: %s\n", configValue(scheme().name))
+	fmt.Fprintf(&b, "walls: %s\n", wallStyles[currentWallStyle].name)
+	fmt.Fprintf(&b, "themed_walls: %t\n", isThemedWalls)
+	fmt.Fprintf(&b, "# mazes larger than the screen: ask, shrink or scroll.\n")
+	fmt.Fprintf(&b, "fit: %s\n", mazeFit)
+	fmt.Fprintf(&b, "# seconds between automatic saves. 0 means off.\n")
+	fmt.Fprintf(&b, "autosave: %d\n", int(autosaveIntervals[currentAutosave].Seconds()))
+	fmt.Fprintf(&b, "keys: %s\n", keySchemes[currentKeyScheme].name)
+	fmt.Fprintf(&b, "# language of the texts: auto for the one of the environment.\n")
+	fmt.Fprintf(&b, "lang: %s\n", localeChoice)
+	writeBellSettings(&b)
+	fmt.Fprintf(&b, "# folder of the saved sessions. empty means the data folder.\n")
+	fmt.Fprintf(&b, "saves: %s\n", configValue(savesDir))
+	return os.WriteFile(configPath(CONFIG_FILE), []byte(b.String()), 0644)
+}
+
+// configValue quotes <s> when it would not be read back as is.
+func configValue(s string) string {
+	if s == "" || strings.ContainsAny(s, "#:\"'") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// readSettings reads the <name: value> lines of the config file. The
+// settings file of the older versions with <name = value> lines is read
+// when there is no config file yet.
+func readSettings() (map[string]string, error) {
+	file, err := os.Open(configPath(CONFIG_FILE))
+	if os.IsNotExist(err) {
+		file, err = os.Open(configPath(SETTINGS_FILE))
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sep := strings.IndexAny(line, ":=")
+		if sep < 0 {
+			logWarn("Skipped line of config file", "line", line)
+			continue
+		}
+
+		value := strings.TrimSpace(line[sep+1:])
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			// plain values may end with a comment.
+			value = strings.TrimSpace(value[:i])
+		}
+		values[strings.TrimSpace(line[:sep])] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// loadSettings applies the settings of the config file if any. Unknown
+// names and wrong values are skipped. The difficulty comes before the size
+// and the algorithm so these may customize it.
+func (gm *Game) loadSettings() error {
+	values, err := readSettings()
+	if err != nil || values == nil {
+		return err
+	}
+
+	if p, found := findPreset(values["difficulty"]); found {
+		applyPreset(p)
+		gm.width, gm.height = p.width, p.height
+	}
+
+	if size, found := values["size"]; found {
+		if w, h, ok := parseSize(size); ok {
+			gm.width, gm.height = w, h
+		}
+	}
+
+	if name, found := values["algorithm"]; found && name != mazeAlgorithm {
+		if _, found := findGenerator(name); found {
+			mazeAlgorithm = name
+			currentPreset = ""
+		}
+	}
+
+	if i, found := findColorScheme(values["colors"]); found {
+		currentColorScheme = i
+	}
+
+	if i, found := findWallStyle(values["walls"]); found {
+		currentWallStyle = i
+	}
+
+	if themed, err := strconv.ParseBool(values["themed_walls"]); err == nil {
+		isThemedWalls = themed
+	}
+
+	if mode := values["fit"]; isMazeFitMode(mode) {
+		setMazeFit(mode)
+	}
+
+	if secs, err := strconv.Atoi(values["autosave"]); err == nil {
+		for i, interval := range autosaveIntervals {
+			if int(interval.Seconds()) == secs {
+				currentAutosave = i
+			}
+		}
+	}
+
+	if i, found := findKeyScheme(values["keys"]); found {
+		currentKeyScheme = i
+	}
+
+	loadBellSettings(values)
+
+	if name := values["lang"]; name != "" && !setLocale(name) {
+		logWarn("Unknown language of config file", "lang", name)
+	}
+
+	savesDir = expandHome(values["saves"])
+	return nil
+}
+
+// parseSize reads a size like "25x15".
+func parseSize(size string) (int, int, bool) {
+	s := strings.Split(size, "x")
+	if len(s) != 2 {
+		return 0, 0, false
+	}
+
+	w, werr := strconv.Atoi(strings.TrimSpace(s[0]))
+	h, herr := strconv.Atoi(strings.TrimSpace(s[1]))
+	if werr != nil || herr != nil || w < 1 || h < 1 {
+		return 0, 0, false
+	}
+	return w, h, true
+}
@@ -0,0 +1,278 @@
+package main
+
+// This file provides the share codes of the mazes. A share code is a compact
+// base64 string which rebuilds the exact same maze so two players can play it
+// and compare their times. A maze which can be generated again from its seed
+// is encoded with its generation settings, otherwise with its full grid.
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	SHARE_CODE   = "sharecode"
+	SHARE_ERROR  = "shareerror"
+	SHCWIDTH     = 70
+	SHARE_FORMAT = 1
+
+	// kinds of share codes.
+	SHARE_SEED = 1
+	SHARE_GRID = 2
+
+	// maximum dimension accepted from a share code.
+	SHARE_MAX_SIZE = 1000
+)
+
+// errInvalidShareCode flags a share code which cannot be decoded.
+var errInvalidShareCode = errors.New("invalid share code")
+
+// sharedMaze is the content of a share code.
+type sharedMaze struct {
+	width, height int
+	seed          int64
+	algorithm     string
+	inX, outX     int
+	braid         float64
+}
+
+// regenerate builds the maze from the generation settings.
+func (s *sharedMaze) regenerate() (*Grid, error) {
+	generator, found := findGenerator(s.algorithm)
+	if !found {
+		return nil, fmt.Errorf("%w: unknown maze algorithm %q", errInvalidShareCode, s.algorithm)
+	}
+
+	maze := generator(s.width, s.height, seededRand(s.seed), s.inX, s.outX)
+	braidMaze(maze, s.braid, seededRand(s.seed))
+	return maze, nil
+}
+
+// appendUvarint appends the varint encoding of <n> to <buf>.
+func appendUvarint(buf []byte, n uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutUvarint(tmp[:], n)]...)
+}
+
+// appendVarint appends the varint encoding of <n> to <buf>.
+func appendVarint(buf []byte, n int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	return append(buf, tmp[:binary.PutVarint(tmp[:], n)]...)
+}
+
+// encodeShareCode returns the share code of <maze> generated from <seed>.
+// The generation settings are kept only when they rebuild the same maze.
+// It refuses the mazes larger than what decodeShareCode accepts.
+func encodeShareCode(maze *Grid, seed int64, algorithm string, braid float64) (string, error) {
+	if maze.Width() > SHARE_MAX_SIZE || maze.Height() > SHARE_MAX_SIZE {
+		return "", fmt.Errorf("maze %d x %d larger than %d x %d", maze.Width(), maze.Height(), SHARE_MAX_SIZE, SHARE_MAX_SIZE)
+	}
+
+	in, out := mazeDoors(maze)
+	s := &sharedMaze{
+		width:     maze.Width(),
+		height:    maze.Height(),
+		seed:      seed,
+		algorithm: algorithm,
+		inX:       in[0],
+		outX:      out[0],
+		braid:     braid,
+	}
+
+	var buf []byte
+	buf = append(buf, SHARE_FORMAT)
+	if regenerated, err := s.regenerate(); err == nil && regenerated.Equal(maze) {
+		buf = append(buf, SHARE_SEED)
+		buf = appendUvarint(buf, uint64(s.width))
+		buf = appendUvarint(buf, uint64(s.height))
+		buf = appendVarint(buf, s.seed)
+		buf = appendUvarint(buf, uint64(len(s.algorithm)))
+		buf = append(buf, s.algorithm...)
+		buf = appendUvarint(buf, uint64(s.inX))
+		buf = appendUvarint(buf, uint64(s.outX))
+		buf = appendUvarint(buf, math.Float64bits(s.braid))
+	} else {
+		// each cell fits into 4 bits so two cells per byte.
+		buf = append(buf, SHARE_GRID)
+		buf = appendUvarint(buf, uint64(s.width))
+		buf = appendUvarint(buf, uint64(s.height))
+		buf = appendVarint(buf, s.seed)
+		for i := 0; i < s.width*s.height; i += 2 {
+			b := byte(maze.At(i%s.width, i/s.width) & 0x0f)
+			if j := i + 1; j < s.width*s.height {
+				b |= byte(maze.At(j%s.width, j/s.width)&0x0f) << 4
+			}
+			buf = append(buf, b)
+		}
+	}
+
+	sum := crc32.ChecksumIEEE(buf)
+	buf = append(buf, byte(sum>>8), byte(sum))
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// shareCodeSize returns the maze dimensions written into the share <code>
+// without rebuilding the maze, so they can be checked beforehand.
+func shareCodeSize(code string) (int, int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(code))
+	if err != nil || len(buf) < 4 || buf[0] != SHARE_FORMAT {
+		return 0, 0, errInvalidShareCode
+	}
+
+	r := bytes.NewReader(buf[2 : len(buf)-2])
+	width, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, 0, errInvalidShareCode
+	}
+	height, err := binary.ReadUvarint(r)
+	if err != nil || width > SHARE_MAX_SIZE || height > SHARE_MAX_SIZE {
+		return 0, 0, fmt.Errorf("%w: wrong maze dimensions", errInvalidShareCode)
+	}
+	return int(width), int(height), nil
+}
+
+// decodeShareCode rebuilds the maze of a share code with its seed.
+func decodeShareCode(code string) (*Grid, int64, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(code))
+	if err != nil || len(buf) < 4 {
+		return nil, 0, errInvalidShareCode
+	}
+
+	payload := buf[:len(buf)-2]
+	sum := crc32.ChecksumIEEE(payload)
+	if buf[len(buf)-2] != byte(sum>>8) || buf[len(buf)-1] != byte(sum) {
+		return nil, 0, fmt.Errorf("%w: checksum mismatch", errInvalidShareCode)
+	}
+
+	if payload[0] != SHARE_FORMAT {
+		return nil, 0, fmt.Errorf("%w: unsupported format %d", errInvalidShareCode, payload[0])
+	}
+
+	kind := payload[1]
+	r := bytes.NewReader(payload[2:])
+	uvarint := func() int {
+		n, e := binary.ReadUvarint(r)
+		if e != nil || n > SHARE_MAX_SIZE {
+			err = errInvalidShareCode
+			return 0
+		}
+		return int(n)
+	}
+
+	s := &sharedMaze{}
+	s.width, s.height = uvarint(), uvarint()
+	if err != nil || s.width <= 0 || s.height <= 0 {
+		return nil, 0, fmt.Errorf("%w: wrong maze dimensions", errInvalidShareCode)
+	}
+	if s.seed, err = binary.ReadVarint(r); err != nil {
+		return nil, 0, errInvalidShareCode
+	}
+
+	switch kind {
+	case SHARE_SEED:
+		name := make([]byte, uvarint())
+		if n, e := io.ReadFull(r, name); e != nil || n != len(name) {
+			return nil, 0, errInvalidShareCode
+		}
+		s.algorithm = string(name)
+		s.inX, s.outX = uvarint(), uvarint()
+		bits, e := binary.ReadUvarint(r)
+		if err != nil || e != nil || s.inX >= s.width || s.outX >= s.width {
+			return nil, 0, errInvalidShareCode
+		}
+		s.braid = math.Float64frombits(bits)
+
+		maze, err := s.regenerate()
+		return maze, s.seed, err
+
+	case SHARE_GRID:
+		cells := make([]byte, (s.width*s.height+1)/2)
+		if n, _ := r.Read(cells); n != len(cells) {
+			return nil, 0, fmt.Errorf("%w: truncated grid", errInvalidShareCode)
+		}
+
+		maze := NewGrid(s.width, s.height)
+		for y := 0; y < s.height; y++ {
+			for x := 0; x < s.width; x++ {
+				i := y*s.width + x
+				maze.Set(x, y, int(cells[i/2]>>(4*(i%2)))&0x0f)
+			}
+		}
+		return maze, s.seed, nil
+	}
+
+	return nil, 0, fmt.Errorf("%w: unknown kind %d", errInvalidShareCode, kind)
+}
+
+// displayShareCode shows the share code of the current maze so it can be
+// copied. The code is also written into the logs.
+func (gm *Game) displayShareCode(g *gocui.Gui, mv *gocui.View) error {
+	if gm.maze == nil {
+		return nil
+	}
+
+	code, err := encodeShareCode(gm.maze, gm.seed, mazeAlgorithm, mazeBraid)
+	if err != nil {
+		logError("Failed to share maze", "err", err)
+		notify("Failed to share the maze: %v", err)
+		return nil
+	}
+	logInfo("Share code of maze", "size", fmt.Sprintf("%dx%d", gm.width, gm.height), "code", code)
+
+	// split the code on several lines to fit into the view.
+	var lines strings.Builder
+	for i := 0; i < len(code); i += SHCWIDTH - 4 {
+		end := i + SHCWIDTH - 4
+		if end > len(code) {
+			end = len(code)
+		}
+		lines.WriteString(" " + code[i:end] + "\n")
+	}
+
+	content := "\n" + lines.String() + "\n " + tr("Copy it (without line breaks) to share this maze.") + "\n " + tr("Press Esc or Ctrl+K to close.")
+	return gm.displayPopupView(g, mv, SHARE_CODE, " Share Code ", content, SHCWIDTH, actionKeys(MAZE, "share-code")...)
+}
+
+// importShareCode asks for a share code then displays its maze.
+func (gm *Game) importShareCode(g *gocui.Gui, v *gocui.View) error {
+	return askInput(g, " Paste Share Code ", "", OUTPUTS, gm.playShareCode, nil)
+}
+
+// playShareCode rebuilds the maze of <code> and displays it as a new maze.
+func (gm *Game) playShareCode(g *gocui.Gui, code string) error {
+	if code == "" {
+		return nil
+	}
+
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		return err
+	}
+
+	maze, seed, err := decodeShareCode(strings.Join(strings.Fields(code), ""))
+	if err != nil {
+		logError("Failed to decode share code", "err", err)
+		message := fmt.Sprintf("\n %s\n %v.\n\n %s", tr("The share code cannot be used."), err, tr("Press Esc to close."))
+		return gm.displayPopupView(g, ov, SHARE_ERROR, " Share Code ", message, SEWIDTH)
+	}
+
+	width, height := maze.Width(), maze.Height()
+	xLines, yLines := ov.Size()
+	if !givenMazePlays(width, height, xLines, yLines) {
+		err = errTooSmallFor(width, height)
+		logWarn("Cannot display shared maze", "err", err)
+		message := fmt.Sprintf("\n %s\n %s.\n %s\n\n %s", tr("The shared maze cannot be played."), capitalize(err.Error()), errorHint(err), tr("Press Esc to close."))
+		return gm.displayPopupView(g, ov, SHARE_ERROR, " Share Code ", message, SEWIDTH)
+	}
+
+	return gm.displayGivenMaze(g, ov, maze, seed)
+}
@@ -0,0 +1,85 @@
+package main
+
+// This file provides a breadth-first search based solver for generated mazes.
+// It is headless so it can be used before any maze get displayed on the gui.
+
+// solveMaze returns the shortest list of cells (x,y) leading from start cell
+// to end cell, both included. It returns nil when end cannot be reached.
+func solveMaze(maze *Grid, start, end [2]int) [][2]int {
+	height := maze.Height()
+	if height == 0 {
+		return nil
+	}
+	width := maze.Width()
+
+	// keep for each visited cell the index of the cell we came from,
+	// or -1 when not visited yet. the start cell comes from itself.
+	// the slice takes far less memory than a map on huge mazes.
+	parents := make([]int32, width*height)
+	for i := range parents {
+		parents[i] = -1
+	}
+	index := func(cell [2]int) int32 {
+		return int32(cell[1]*width + cell[0])
+	}
+	parents[index(start)] = index(start)
+	queue := [][2]int{start}
+
+	for len(queue) > 0 {
+		cell := queue[0]
+		queue = queue[1:]
+		if cell == end {
+			break
+		}
+
+		for _, d := range [4]int{N, S, E, W} {
+			// skip the direction if its wall is still there.
+			if (maze.At(cell[0], cell[1]) & d) == 0 {
+				continue
+			}
+
+			nX, nY := moveTo(cell[0], cell[1], d)
+			if !maze.Inside(nX, nY) {
+				continue
+			}
+
+			next := [2]int{nX, nY}
+			if parents[index(next)] >= 0 {
+				continue
+			}
+			parents[index(next)] = index(cell)
+			queue = append(queue, next)
+		}
+	}
+
+	if !maze.Inside(end[0], end[1]) || parents[index(end)] < 0 {
+		return nil
+	}
+
+	// walk back from end cell to start cell then reverse.
+	var path [][2]int
+	for i := index(end); ; i = parents[i] {
+		path = append(path, [2]int{int(i) % width, int(i) / width})
+		if i == index(start) {
+			break
+		}
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// mazeDifficulty rates a maze by the share of its cells that must be
+// walked through to solve it. It returns 0 for an unsolvable maze.
+func mazeDifficulty(maze *Grid, width, height int) float64 {
+	in, out := mazeDoors(maze)
+	path := solveMaze(maze, in, out)
+	if path == nil {
+		return 0
+	}
+
+	return float64(len(path)) / float64(width*height)
+}
@@ -0,0 +1,343 @@
+package main
+
+// This file streams a game to spectators. The play command started with
+// -spectate publishes the maze, the moves and the status of the rounds as
+// JSON lines to the spectators connected on its TCP port, and the watch
+// command of another terminal draws them live. It suits teaching a class
+// or streaming a run without sharing the terminal of the player.
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// frames waiting to be sent to a spectator. slower ones are dropped.
+	SPECTATE_QUEUE = 64
+	// maximum length of a frame read by the watch command.
+	SPECTATE_MAX_FRAME = 1 << 24
+)
+
+// spectateFrame is a line sent to the spectators. The maze frames carry
+// the grid with the player position and status, the move frames only the
+// position and the status frames only the status.
+type spectateFrame struct {
+	Kind      string  `json:"kind"`
+	Grid      [][]int `json:"grid,omitempty"`
+	X         int     `json:"x"`
+	Y         int     `json:"y"`
+	Status    string  `json:"status,omitempty"`
+	ElapsedMs int64   `json:"elapsed_ms,omitempty"`
+}
+
+// spectateHub sends the game events to the connected spectators.
+type spectateHub struct {
+	mu      sync.Mutex
+	clients map[net.Conn]chan []byte
+	// last maze frame sent to the spectators joining later.
+	state spectateFrame
+	// set once a position of the current maze is known.
+	placed bool
+}
+
+// startSpectating streams the game to the spectators connecting at
+// <addr> like ":7777" until the returned function is called.
+func startSpectating(addr string) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := &spectateHub{clients: make(map[net.Conn]chan []byte)}
+	go hub.follow(events.subscribe(EVENT_MOVE, EVENT_START, EVENT_PAUSE, EVENT_RESUME, EVENT_WIN, EVENT_LOSE, EVENT_CLEAR))
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			hub.join(conn)
+		}
+	}()
+
+	logInfo("Streaming the game to spectators", "addr", ln.Addr().String())
+
+	// the players of the network find the game without its address.
+	unannounce := func() {}
+	if !isLoopback(addr) {
+		if unannounce, err = startAnnouncing(ln.Addr().(*net.TCPAddr).Port); err != nil {
+			logWarn("Failed to announce the game on the network", "err", err)
+			unannounce = func() {}
+		}
+	}
+
+	return func() {
+		unannounce()
+		ln.Close()
+		hub.close()
+	}, nil
+}
+
+// follow turns the events received from <ch> into frames until the exit.
+func (h *spectateHub) follow(ch <-chan gameEvent) {
+	for {
+		select {
+		case <-exit:
+			return
+		case e := <-ch:
+			h.handle(e)
+		}
+	}
+}
+
+// handle updates the state with the event <e> and sends its frame.
+func (h *spectateHub) handle(e gameEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch e.kind {
+	case EVENT_MOVE:
+		h.placed = true
+		h.state.X, h.state.Y = e.x, e.y
+		h.broadcast(spectateFrame{Kind: "move", X: e.x, Y: e.y})
+		return
+
+	case EVENT_START:
+		if e.maze == nil {
+			return
+		}
+		h.state.Grid = e.maze.Rows()
+		h.state.Status = "playing"
+		h.state.ElapsedMs = e.elapsed.Milliseconds()
+		if !h.placed {
+			in, _ := mazeDoors(e.maze)
+			h.state.X, h.state.Y = 2*in[0]+1, 0
+			h.placed = true
+		}
+		h.broadcast(h.state)
+		return
+
+	case EVENT_PAUSE:
+		h.state.Status = "paused"
+	case EVENT_RESUME:
+		h.state.Status = "playing"
+	case EVENT_WIN:
+		h.state.Status = "won"
+	case EVENT_LOSE:
+		h.state.Status = "lost"
+	case EVENT_CLEAR:
+		h.state = spectateFrame{Status: "waiting"}
+		h.placed = false
+	}
+	h.broadcast(spectateFrame{Kind: "status", Status: h.state.Status})
+}
+
+// join sends the current state to the spectator <conn> then the next frames.
+func (h *spectateHub) join(conn net.Conn) {
+	queue := make(chan []byte, SPECTATE_QUEUE)
+	go func() {
+		defer conn.Close()
+		for frame := range queue {
+			conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if _, err := conn.Write(frame); err != nil {
+				h.leave(conn)
+				return
+			}
+		}
+	}()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[conn] = queue
+	logInfo("Spectator joined", "remote", conn.RemoteAddr().String())
+	if h.state.Grid != nil {
+		h.send(conn, queue, h.state)
+	} else {
+		h.send(conn, queue, spectateFrame{Kind: "status", Status: "waiting"})
+	}
+}
+
+// leave forgets the spectator <conn> and stops its queue.
+func (h *spectateHub) leave(conn net.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if queue, found := h.clients[conn]; found {
+		delete(h.clients, conn)
+		close(queue)
+		logInfo("Spectator left", "remote", conn.RemoteAddr().String())
+	}
+}
+
+// broadcast queues <f> for all spectators. The lock must be held.
+func (h *spectateHub) broadcast(f spectateFrame) {
+	for conn, queue := range h.clients {
+		h.send(conn, queue, f)
+	}
+}
+
+// send queues <f> for the spectator <conn> or drops it when its queue is
+// full since a spectator too slow would never catch up. The lock must be held.
+func (h *spectateHub) send(conn net.Conn, queue chan []byte, f spectateFrame) {
+	if f.Kind == "" {
+		f.Kind = "maze"
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		logError("Failed to encode spectator frame", "err", err)
+		return
+	}
+
+	select {
+	case queue <- append(data, '\n'):
+	default:
+		delete(h.clients, conn)
+		close(queue)
+		logWarn("Dropped slow spectator", "remote", conn.RemoteAddr().String())
+	}
+}
+
+// close disconnects all the spectators.
+func (h *spectateHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn, queue := range h.clients {
+		delete(h.clients, conn)
+		close(queue)
+	}
+}
+
+// runWatch draws the game streamed at the address given until it ends.
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "watch [flags] [host:port]", "draw live the game of a player started with play -spectate, found on the local network without address")
+	player := fs.String("player", "@", "character drawing the player")
+	list := fs.Bool("list", false, "only list the games found on the local network")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	if *list {
+		games, err := discoverGames(DISCOVERY_WAIT)
+		if err != nil {
+			return err
+		}
+		for _, g := range games {
+			fmt.Printf("%s\t%s\tgomazes %s\n", g.addr, g.name, g.version)
+		}
+		return nil
+	}
+
+	addr := fs.Arg(0)
+	if addr == "" {
+		var err error
+		if addr, err = pickGame(); err != nil {
+			return err
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), SPECTATE_MAX_FRAME)
+	state := spectateFrame{Status: "waiting"}
+	var maze *Grid
+	for scanner.Scan() {
+		f := spectateFrame{}
+		if err = json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			return fmt.Errorf("invalid frame: %v", err)
+		}
+
+		switch f.Kind {
+		case "maze":
+			if len(f.Grid) == 0 || len(f.Grid[0]) == 0 {
+				return fmt.Errorf("invalid frame: empty maze")
+			}
+			state = f
+			maze = GridFromRows(f.Grid)
+		case "move":
+			state.X, state.Y = f.X, f.Y
+		case "status":
+			state.Status = f.Status
+			if f.Status == "waiting" {
+				maze = nil
+			}
+		}
+
+		drawWatched(addr, maze, state, *player)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	fmt.Println("The player stopped streaming.")
+	return nil
+}
+
+// drawWatched redraws the terminal with <maze> and the player at the
+// position of <state>. The mazes larger than the terminal only get the
+// window around the player drawn so each frame costs the size of the screen.
+func drawWatched(addr string, maze *Grid, state spectateFrame, player string) {
+	var b strings.Builder
+	// move home and clear the screen.
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "Watching %s :: %s\n\n", addr, strings.ToUpper(state.Status))
+	if maze == nil {
+		b.WriteString("Waiting for the next maze...\n")
+		os.Stdout.WriteString(b.String())
+		return
+	}
+
+	// the window is centered on the player below the 2 header lines.
+	cx0, cy0 := 0, 0
+	cx1, cy1 := 2*maze.Width()+1, maze.Height()+1
+	if cols, rows, err := term.GetSize(int(os.Stdout.Fd())); err == nil && rows > 3 {
+		cx0, cx1 = windowAround(state.X, cols, cx1)
+		cy0, cy1 = windowAround(state.Y, rows-3, cy1)
+	}
+
+	window := formatMazeWindow(maze, cx0, cy0, cx1, cy1)
+	for y, line := range strings.Split(window.String(), "\n") {
+		if x := state.X - cx0; y+cy0 == state.Y && x >= 0 && x < len(line) {
+			line = line[:x] + player + line[x+1:]
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	os.Stdout.WriteString(b.String())
+}
+
+// windowAround returns the bounds of the window of <size> positions
+// centered on <pos> and kept inside [0, total).
+func windowAround(pos, size, total int) (int, int) {
+	if size >= total {
+		return 0, total
+	}
+
+	start := pos - size/2
+	if start < 0 {
+		start = 0
+	}
+	if start+size > total {
+		start = total - size
+	}
+	return start, start + size
+}
@@ -0,0 +1,179 @@
+package main
+
+// This file lets remote players play over SSH. The serve command started
+// with -ssh accepts any player and runs a new game process for each session
+// into a pseudo terminal bound to the SSH channel, so every player gets
+// an isolated game state. The saves, records and settings of the players
+// signed with a public key are kept into a folder of the data directory
+// named after the key fingerprint, whatever the user name claimed. The
+// players without a key play from a temporary folder removed on exit.
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// host key of the ssh server generated on first start.
+	SSH_HOST_KEY = "ssh_host_ed25519_key"
+	// folder of the data directory holding one folder per ssh player key.
+	SSH_PLAYERS_FOLDER = "players"
+	// sessions dropped after this long without any key pressed.
+	SSH_IDLE_TIMEOUT = 30 * time.Minute
+)
+
+// newSSHServer returns the ssh server listening at <addr> which runs
+// the game for at most <maxSessions> sessions at once.
+func newSSHServer(addr string, maxSessions int) (*ssh.Server, error) {
+	keyPath := configPath(SSH_HOST_KEY)
+	if err := ensureHostKey(keyPath); err != nil {
+		return nil, fmt.Errorf("cannot create ssh host key: %w", err)
+	}
+
+	slots := make(chan struct{}, maxSessions)
+	server := &ssh.Server{
+		Addr:        addr,
+		IdleTimeout: SSH_IDLE_TIMEOUT,
+		// any key is welcome since it only names the folder of the player.
+		PublicKeyHandler: func(ctx ssh.Context, key ssh.PublicKey) bool {
+			return true
+		},
+		// the players without a key still play but keep nothing.
+		KeyboardInteractiveHandler: func(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+			return true
+		},
+		Handler: func(s ssh.Session) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			default:
+				io.WriteString(s, "Too many players right now. Please retry later.\r\n")
+				s.Exit(1)
+				return
+			}
+
+			if err := playOverSSH(s); err != nil {
+				logError("Failed to run ssh session", "user", s.User(), "remote", s.RemoteAddr().String(), "err", err)
+				fmt.Fprintf(s, "The game cannot be started: %v.\r\n", err)
+				s.Exit(1)
+				return
+			}
+			s.Exit(0)
+		},
+	}
+
+	if err := server.SetOption(ssh.HostKeyFile(keyPath)); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// playOverSSH runs the game of the session <s> into a pseudo terminal
+// following the size of the remote terminal until the game exits.
+func playOverSSH(s ssh.Session) error {
+	ptyReq, resized, isPty := s.Pty()
+	if !isPty {
+		return errors.New("a terminal is needed to play. connect with ssh -t")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	dir, cleanup, err := sshPlayerDir(s.PublicKey())
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cmd := exec.Command(exe, "play", "-dir", dir)
+	cmd.Env = append(os.Environ(), "TERM="+ptyReq.Term)
+
+	logInfo("Starting ssh session", "user", s.User(), "dir", dir, "remote", s.RemoteAddr().String(), "size", fmt.Sprintf("%dx%d", ptyReq.Window.Width, ptyReq.Window.Height))
+	start := time.Now()
+
+	tty, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(ptyReq.Window.Width), Rows: uint16(ptyReq.Window.Height)})
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	go func() {
+		for win := range resized {
+			pty.Setsize(tty, &pty.Winsize{Cols: uint16(win.Width), Rows: uint16(win.Height)})
+		}
+	}()
+
+	// the keys are forwarded until the session closes. The game is
+	// then killed if it is still running like a closed terminal.
+	go func() {
+		io.Copy(tty, s)
+		cmd.Process.Kill()
+	}()
+	io.Copy(s, tty)
+
+	err = cmd.Wait()
+	logInfo("Ended ssh session", "user", s.User(), "played", time.Since(start).Round(time.Second))
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
+// sshPlayerDir returns the folder of the player signed with <key> named
+// after its fingerprint, so the user names never share or steal a folder.
+// Without a key, the folder is a temporary one removed by <cleanup>.
+func sshPlayerDir(key ssh.PublicKey) (dir string, cleanup func(), err error) {
+	if key != nil {
+		sum := sha256.Sum256(key.Marshal())
+		return dataPath(filepath.Join(SSH_PLAYERS_FOLDER, hex.EncodeToString(sum[:]))), func() {}, nil
+	}
+
+	if err = os.MkdirAll(dataPath(SSH_PLAYERS_FOLDER), 0755); err != nil {
+		return "", nil, err
+	}
+	if dir, err = os.MkdirTemp(dataPath(SSH_PLAYERS_FOLDER), "anonymous-"); err != nil {
+		return "", nil, err
+	}
+	return dir, func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logWarn("Failed to remove ssh player folder", "dir", dir, "err", err)
+		}
+	}, nil
+}
+
+// ensureHostKey generates the ed25519 host key at <path> if missing.
+func ensureHostKey(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	block, err := gossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		return err
+	}
+
+	logInfo("Generated ssh host key", "path", path)
+	return os.WriteFile(path, pem.EncodeToMemory(block), 0600)
+}
@@ -0,0 +1,167 @@
+package main
+
+// This file keeps the lifetime statistics of the player into a stats file.
+// Each run is accounted once it ends: won, lost, restarted or closed.
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	STATS         = "stats"
+	STATS_RECORDS = "stats.txt"
+	STWIDTH       = 40
+)
+
+// lifetimeStats are the cumulative statistics of all runs.
+type lifetimeStats struct {
+	generated int64
+	completed int64
+	moves     int64
+	playTime  time.Duration
+	// sum of the efficiency of each completed run which is
+	// the optimal moves over the moves made.
+	efficiencySum float64
+}
+
+// loadStats reads the lifetime statistics. Each line of
+// the stats file is made of <name> <value>.
+func loadStats() (lifetimeStats, error) {
+	var stats lifetimeStats
+
+	file, err := os.Open(dataPath(STATS_RECORDS))
+	if os.IsNotExist(err) {
+		return stats, nil
+	} else if err != nil {
+		return stats, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "generated":
+			stats.generated = int64(value)
+		case "completed":
+			stats.completed = int64(value)
+		case "moves":
+			stats.moves = int64(value)
+		case "playtime_ms":
+			stats.playTime = time.Duration(value) * time.Millisecond
+		case "efficiency_sum":
+			stats.efficiencySum = value
+		}
+	}
+
+	return stats, scanner.Err()
+}
+
+// saveStats writes the lifetime statistics into the stats file.
+func saveStats(stats lifetimeStats) error {
+	file, err := os.Create(dataPath(STATS_RECORDS))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "generated %d\ncompleted %d\nmoves %d\nplaytime_ms %d\nefficiency_sum %f\n",
+		stats.generated, stats.completed, stats.moves, stats.playTime.Milliseconds(), stats.efficiencySum)
+	return err
+}
+
+// updateStats loads the statistics, applies <change> then saves them.
+func updateStats(change func(stats *lifetimeStats)) {
+	stats, err := loadStats()
+	if err != nil {
+		logError("Failed to load lifetime stats", "err", err)
+		return
+	}
+
+	change(&stats)
+	if err = saveStats(stats); err != nil {
+		logError("Failed to save lifetime stats", "err", err)
+	}
+}
+
+// countGeneratedMaze accounts a newly generated maze.
+func countGeneratedMaze() {
+	updateStats(func(stats *lifetimeStats) {
+		stats.generated++
+	})
+}
+
+// accountRun adds the moves made and time played of the current run into
+// the statistics along with its efficiency when <won>. A run is only
+// accounted once so it can be called at each way a run may end.
+func (gm *Game) accountRun(won bool) {
+	if gm.run.accounted || gm.maze == nil {
+		return
+	}
+	gm.run.accounted = true
+
+	efficiency := 0.0
+	if won && gm.run.moves > 0 {
+		in, _ := mazeDoors(gm.maze)
+		efficiency = float64(optimalMoves(gm.maze, 2*in[0]+1)) / float64(gm.run.moves)
+		if efficiency > 1 {
+			efficiency = 1
+		}
+	}
+
+	updateStats(func(stats *lifetimeStats) {
+		stats.moves += int64(gm.run.moves)
+		stats.playTime += gm.runElapsed()
+		if won {
+			stats.completed++
+			stats.efficiencySum += efficiency
+		}
+	})
+}
+
+// formatStats returns the lines of the stats dashboard.
+func formatStats(stats lifetimeStats) string {
+	completion, efficiency := 0.0, 0.0
+	if stats.generated > 0 {
+		completion = 100 * float64(stats.completed) / float64(stats.generated)
+	}
+	if stats.completed > 0 {
+		efficiency = 100 * stats.efficiencySum / float64(stats.completed)
+	}
+
+	lines := formatFields(
+		[2]string{"Mazes generated", strconv.FormatInt(stats.generated, 10)},
+		[2]string{"Mazes completed", fmt.Sprintf("%d (%.0f%%)", stats.completed, completion)},
+		[2]string{"Total moves", strconv.FormatInt(stats.moves, 10)},
+		[2]string{"Total play time", formatSeconds(int64(stats.playTime / time.Second))},
+		[2]string{"Avg efficiency", fmt.Sprintf("%.0f%%", efficiency)},
+	)
+	return "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// displayStatsView displays the lifetime statistics dashboard.
+func (gm *Game) displayStatsView(g *gocui.Gui, cv *gocui.View) error {
+	stats, err := loadStats()
+	if err != nil {
+		logError("Failed to load lifetime stats", "err", err)
+		return nil
+	}
+
+	return gm.displayPopupView(g, cv, STATS, " Lifetime Stats ", formatStats(stats), STWIDTH, actionKeys(OUTPUTS, "stats")...)
+}
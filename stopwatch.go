@@ -0,0 +1,114 @@
+package main
+
+// This file provides the stopwatch measuring the time played. It relies on
+// the monotonic clock and does not depend on the gui so the durations never
+// drift with the display refreshes.
+
+import (
+	"sync"
+	"time"
+)
+
+// Stopwatch measures the time elapsed while running. Paused durations are
+// not counted. It is safe for concurrent use.
+type Stopwatch struct {
+	mu sync.Mutex
+	// clock source. time.Now carries the monotonic clock reading.
+	now func() time.Time
+	// start of the current running period if running.
+	running   bool
+	startedAt time.Time
+	// time elapsed over the previous running periods.
+	elapsed time.Duration
+}
+
+// NewStopwatch returns a stopped stopwatch reading time from <now>.
+// It defaults to time.Now when <now> is nil.
+func NewStopwatch(now func() time.Time) *Stopwatch {
+	if now == nil {
+		now = time.Now
+	}
+	return &Stopwatch{now: now}
+}
+
+// Start runs the stopwatch. It does nothing if already running.
+func (s *Stopwatch) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start()
+}
+
+// Pause stops the stopwatch and keeps the time elapsed so far.
+func (s *Stopwatch) Pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pause()
+}
+
+// Toggle pauses a running stopwatch or starts a paused one.
+// It returns true when the stopwatch is running afterwards.
+func (s *Stopwatch) Toggle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		s.pause()
+	} else {
+		s.start()
+	}
+	return s.running
+}
+
+// start runs the stopwatch. The caller holds the lock.
+func (s *Stopwatch) start() {
+	if !s.running {
+		s.running = true
+		s.startedAt = s.now()
+	}
+}
+
+// pause stops the stopwatch. The caller holds the lock.
+func (s *Stopwatch) pause() {
+	if s.running {
+		s.running = false
+		s.elapsed += s.now().Sub(s.startedAt)
+	}
+}
+
+// Reset clears the time elapsed. A running stopwatch keeps running.
+func (s *Stopwatch) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elapsed = 0
+	s.startedAt = s.now()
+}
+
+// Set replaces the time elapsed by <d>. It allows to resume a
+// measure saved earlier. A running stopwatch keeps running.
+func (s *Stopwatch) Set(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.elapsed = d
+	s.startedAt = s.now()
+}
+
+// Running tells if the stopwatch is running.
+func (s *Stopwatch) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Elapsed returns the total running time.
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return s.elapsed + s.now().Sub(s.startedAt)
+	}
+	return s.elapsed
+}
+
+// Seconds returns the total running time in whole seconds.
+func (s *Stopwatch) Seconds() int64 {
+	return int64(s.Elapsed() / time.Second)
+}
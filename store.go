@@ -0,0 +1,307 @@
+package main
+
+// This file defines where the saved sessions are kept. The sessions are read
+// and written through a SessionStore so they can live into the data folder
+// or on a remote WebDAV server to be synced across machines.
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// timeout of each request sent to a remote store.
+	STORE_TIMEOUT = 15 * time.Second
+)
+
+// SessionStore lists, loads, saves and deletes the raw content of the
+// saved sessions by their id. A missing session is reported with an
+// error matching fs.ErrNotExist.
+type SessionStore interface {
+	List() ([]savedFile, error)
+	Load(id string) ([]byte, error)
+	Save(id string, data []byte) error
+	Delete(id string) error
+}
+
+// savedFile describes a stored session.
+type savedFile struct {
+	name     string
+	size     int64
+	modified time.Time
+}
+
+var (
+	// store of the saved sessions. defaults to the data folder.
+	sessionStore SessionStore = fileStore{}
+	// folder of the saved sessions files. empty means the data folder.
+	savesDir string
+)
+
+// setupSessionStore selects the store of the saved sessions from <location>.
+// An empty location keeps the data folder while an http(s) url points to a
+// WebDAV collection. Credentials may be given into the url user info.
+func setupSessionStore(location string) error {
+	if location == "" {
+		sessionStore = fileStore{}
+		return nil
+	}
+
+	store, err := newWebDAVStore(location)
+	if err != nil {
+		return err
+	}
+
+	sessionStore = store
+	return nil
+}
+
+// fileStore keeps the saved sessions as files into the data folder.
+type fileStore struct{}
+
+// sessionsFolder returns the folder of the session files.
+func sessionsFolder() string {
+	if savesDir != "" {
+		return savesDir
+	}
+	return dataPath(SESSIONS_FOLDER)
+}
+
+// sessionPath returns the file path of the session named <id>.
+func sessionPath(id string) string {
+	return sessionsFolder() + string(os.PathSeparator) + id
+}
+
+// List returns the session files of the folder. A missing
+// folder means there is no saved session yet.
+func (fileStore) List() ([]savedFile, error) {
+	entries, err := os.ReadDir(sessionsFolder())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	files := make([]savedFile, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, savedFile{entry.Name(), info.Size(), info.ModTime()})
+	}
+
+	return files, nil
+}
+
+// Load reads the session file named <id>.
+func (fileStore) Load(id string) ([]byte, error) {
+	return os.ReadFile(sessionPath(id))
+}
+
+// Save writes the session file named <id> and creates the folder if needed.
+func (fileStore) Save(id string, data []byte) error {
+	if err := os.MkdirAll(sessionsFolder(), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(sessionPath(id), data, 0666)
+}
+
+// Delete removes the session file named <id>.
+func (fileStore) Delete(id string) error {
+	return os.Remove(sessionPath(id))
+}
+
+// webDAVStore keeps the saved sessions into a remote WebDAV collection.
+type webDAVStore struct {
+	base     *url.URL
+	user     string
+	password string
+	client   *http.Client
+}
+
+// newWebDAVStore parses the url of the WebDAV collection <location>.
+func newWebDAVStore(location string) (*webDAVStore, error) {
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported sessions store %q", location)
+	}
+
+	store := &webDAVStore{client: &http.Client{Timeout: STORE_TIMEOUT}}
+	if base.User != nil {
+		store.user = base.User.Username()
+		store.password, _ = base.User.Password()
+		base.User = nil
+	}
+
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	store.base = base
+
+	return store, nil
+}
+
+// do sends a request with <method> to the session named <id> or to the
+// collection itself when empty and checks the response status.
+func (s *webDAVStore) do(method, id string, body io.Reader, header map[string]string) (*http.Response, error) {
+	target := *s.base
+	target.Path = path.Join(s.base.Path, id)
+	if id == "" {
+		target.Path = s.base.Path
+	}
+
+	req, err := http.NewRequest(method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// a conflict means the collection of the session is missing.
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusConflict {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %w", method, target.Path, fs.ErrNotExist)
+	}
+
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, target.Path, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// davMultistatus is the body of a PROPFIND response.
+type davMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Prop struct {
+				Length       int64     `xml:"getcontentlength"`
+				LastModified string    `xml:"getlastmodified"`
+				Collection   *struct{} `xml:"resourcetype>collection"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// List returns the sessions of the collection. A missing collection
+// means there is no saved session yet.
+func (s *webDAVStore) List() ([]savedFile, error) {
+	resp, err := s.do("PROPFIND", "", nil, map[string]string{"Depth": "1"})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var status davMultistatus
+	if err = xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+
+	var files []savedFile
+	for _, r := range status.Responses {
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			continue
+		}
+
+		name := path.Base(strings.TrimSuffix(href, "/"))
+		if strings.HasSuffix(href, "/") || name == "" {
+			// the collection itself or a sub-collection.
+			continue
+		}
+
+		file := savedFile{name: name}
+		for _, ps := range r.Propstat {
+			if ps.Prop.Collection != nil {
+				file.name = ""
+				break
+			}
+
+			if ps.Prop.Length > 0 {
+				file.size = ps.Prop.Length
+			}
+
+			if t, err := http.ParseTime(ps.Prop.LastModified); err == nil {
+				file.modified = t
+			}
+		}
+
+		if file.name != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// Load downloads the session named <id>.
+func (s *webDAVStore) Load(id string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// Save uploads the session named <id>. The collection is created
+// when the server reports it is missing.
+func (s *webDAVStore) Save(id string, data []byte) error {
+	resp, err := s.do(http.MethodPut, id, bytes.NewReader(data), nil)
+	if errors.Is(err, fs.ErrNotExist) {
+		if mresp, merr := s.do("MKCOL", "", nil, nil); merr == nil {
+			mresp.Body.Close()
+		}
+		resp, err = s.do(http.MethodPut, id, bytes.NewReader(data), nil)
+	}
+
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Delete removes the session named <id>.
+func (s *webDAVStore) Delete(id string) error {
+	resp, err := s.do(http.MethodDelete, id, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
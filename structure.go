@@ -0,0 +1,285 @@
+package main
+
+// This file measures the structure of a maze to compare the generation
+// algorithms. The maze is seen as a graph whose nodes are the dead ends and
+// the junctions, linked by branches made of the corridors between them.
+// The analysis is shown over the maze view and printed by the analyze command.
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	STRUCTURE = "structure"
+	SRWIDTH   = 44
+)
+
+// mazeStructure describes the shape of the passages of a maze.
+type mazeStructure struct {
+	cells int
+	// cells with a single opening.
+	deadEnds int
+	// cells with more than two passages.
+	junctions int
+	// most cells with two passages in a row.
+	longestCorridor int
+	// paths between two dead ends or junctions and their total moves.
+	branches    int
+	branchMoves int
+	// passages to close to leave a single path between any two cells.
+	loops int
+}
+
+// averageBranch returns the average moves of the branches.
+func (s mazeStructure) averageBranch() float64 {
+	if s.branches == 0 {
+		return 0
+	}
+	return float64(s.branchMoves) / float64(s.branches)
+}
+
+// analyzeStructure measures the structure of <maze>.
+func analyzeStructure(maze *Grid) mazeStructure {
+	var opposite = [W + 1]int{N: S, S: N, E: W, W: E}
+	width, height := maze.Width(), maze.Height()
+	s := mazeStructure{cells: width * height, deadEnds: countDeadEnds(maze)}
+
+	// links holds the directions of the passages of each cell.
+	links := make([]uint8, s.cells)
+	degree := make([]uint8, s.cells)
+	passages := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			for _, d := range [4]int{N, S, E, W} {
+				nX, nY := moveTo(x, y, d)
+				if maze.Inside(nX, nY) && maze.At(x, y)&d != 0 && maze.At(nX, nY)&opposite[d] != 0 {
+					links[y*width+x] |= uint8(d)
+					degree[y*width+x]++
+					passages++
+				}
+			}
+			if degree[y*width+x] > 2 {
+				s.junctions++
+			}
+		}
+	}
+	passages /= 2
+
+	// the branches are walked from both their ends so they count twice.
+	walked := make([]bool, s.cells)
+	walk := func(from, d int) (moves, corridor int) {
+		for i := from; ; {
+			x, y := moveTo(i%width, i/width, d)
+			i, moves = y*width+x, moves+1
+			if degree[i] != 2 || i == from {
+				return moves, corridor
+			}
+			walked[i] = true
+			corridor++
+			// leave by the passage other than the one used to come in.
+			d = int(links[i]) &^ opposite[d]
+		}
+	}
+	for i := range links {
+		if degree[i] == 2 {
+			continue
+		}
+		for _, d := range [4]int{N, S, E, W} {
+			if int(links[i])&d == 0 {
+				continue
+			}
+			moves, corridor := walk(i, d)
+			s.branches++
+			s.branchMoves += moves
+			if corridor > s.longestCorridor {
+				s.longestCorridor = corridor
+			}
+		}
+	}
+	s.branches /= 2
+	s.branchMoves /= 2
+
+	// the corridors left are rings without any dead end or junction.
+	for i := range links {
+		if degree[i] != 2 || walked[i] {
+			continue
+		}
+		walked[i] = true
+		_, corridor := walk(i, int(links[i])&-int(links[i]))
+		if corridor+1 > s.longestCorridor {
+			s.longestCorridor = corridor + 1
+		}
+	}
+
+	// each passage added to a spanning forest of the cells closes a loop.
+	s.loops = passages - s.cells + countRegions(links, width)
+	return s
+}
+
+// countRegions returns the number of groups of cells linked together
+// by the passages <links> of a maze <width> cells wide.
+func countRegions(links []uint8, width int) int {
+	seen := make([]bool, len(links))
+	stack := make([]int32, 0, 64)
+	regions := 0
+	for start := range links {
+		if seen[start] {
+			continue
+		}
+
+		regions++
+		seen[start] = true
+		stack = append(stack[:0], int32(start))
+		for len(stack) > 0 {
+			i := int(stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+			for _, d := range [4]int{N, S, E, W} {
+				if int(links[i])&d == 0 {
+					continue
+				}
+				x, y := moveTo(i%width, i/width, d)
+				if j := y*width + x; !seen[j] {
+					seen[j] = true
+					stack = append(stack, int32(j))
+				}
+			}
+		}
+	}
+	return regions
+}
+
+// formatStructure returns the structure <s> as shown into the popup view.
+func formatStructure(s mazeStructure) string {
+	share := 0.0
+	if s.cells > 0 {
+		share = 100 * float64(s.deadEnds) / float64(s.cells)
+	}
+
+	lines := formatFields(
+		[2]string{"Cells", strconv.Itoa(s.cells)},
+		[2]string{"Dead ends", fmt.Sprintf("%d (%.1f%%)", s.deadEnds, share)},
+		[2]string{"Junctions", strconv.Itoa(s.junctions)},
+		[2]string{"Longest corridor", trf("%d cells", s.longestCorridor)},
+		[2]string{"Branches", strconv.Itoa(s.branches)},
+		[2]string{"Avg branch", trf("%.1f moves", s.averageBranch())},
+		[2]string{"Loops", strconv.Itoa(s.loops)},
+	)
+	return "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// displayStructureView displays the structure of the maze played.
+func (gm *Game) displayStructureView(g *gocui.Gui, cv *gocui.View) error {
+	if gm.maze == nil {
+		return nil
+	}
+
+	return gm.displayPopupView(g, cv, STRUCTURE, " Maze Structure ", formatStructure(analyzeStructure(gm.maze)), SRWIDTH, actionKeys(MAZE, "structure")...)
+}
+
+// newStructureReport returns the JSON report of the structure <s> of <maze>.
+func newStructureReport(maze *Grid, s mazeStructure) *structureReport {
+	return &structureReport{
+		Width:           maze.Width(),
+		Height:          maze.Height(),
+		Cells:           s.cells,
+		DeadEnds:        s.deadEnds,
+		Junctions:       s.junctions,
+		LongestCorridor: s.longestCorridor,
+		Branches:        s.branches,
+		AverageBranch:   s.averageBranch(),
+		Loops:           s.loops,
+	}
+}
+
+// runAnalyze prints the structure of a maze read from a file or of a
+// batch of generated mazes with their average to compare the algorithms.
+func runAnalyze(args []string) error {
+	opts := &mazeOptions{}
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "analyze [flags]", "print the dead ends, the longest corridor, the average branch and the loops of mazes read from a file or generated")
+	opts.register(fs)
+	input := fs.String("i", "", "maze file in text or JSON format or saved session to analyze, - for the standard input (default generated mazes)")
+	count := fs.Int("count", 1, "number of mazes to generate with consecutive seeds and to average")
+	if err := opts.parse(fs, args); err != nil {
+		return err
+	}
+
+	if *count < 1 {
+		return fmt.Errorf("invalid count %d", *count)
+	}
+
+	if *input != "" {
+		if *count > 1 {
+			return errors.New("count only applies to the generated mazes")
+		}
+
+		maze, err := readMazeFile(*input)
+		if err != nil {
+			return err
+		}
+
+		s := analyzeStructure(maze)
+		if opts.json {
+			report := newStructureReport(maze, s)
+			report.File = *input
+			return writeReport(opts, report)
+		}
+		return writeOutput(opts, strings.TrimPrefix(formatStructure(s), "\n"))
+	}
+
+	base := opts.seed
+	if !opts.set["seed"] {
+		base = time.Now().UnixNano()
+	}
+	opts.set["seed"] = true
+
+	var reports []*structureReport
+	var total mazeStructure
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-20s %9s %9s %9s %9s %10s %7s\n", "seed", "dead_ends", "junctions", "corridor", "branches", "avg_branch", "loops")
+	for i := 0; i < *count; i++ {
+		opts.seed = base + int64(i)
+		maze, err := opts.generate()
+		if err != nil {
+			return err
+		}
+
+		s := analyzeStructure(maze)
+		if *count == 1 && !opts.json {
+			return writeOutput(opts, strings.TrimPrefix(formatStructure(s), "\n"))
+		}
+
+		report := newStructureReport(maze, s)
+		seed := opts.seed
+		report.Seed, report.Algorithm = &seed, mazeAlgorithm
+		reports = append(reports, report)
+
+		total.cells += s.cells
+		total.deadEnds += s.deadEnds
+		total.junctions += s.junctions
+		total.longestCorridor += s.longestCorridor
+		total.branches += s.branches
+		total.branchMoves += s.branchMoves
+		total.loops += s.loops
+		fmt.Fprintf(&b, "%-20d %9d %9d %9d %9d %10.2f %7d\n", opts.seed, s.deadEnds, s.junctions, s.longestCorridor, s.branches, s.averageBranch(), s.loops)
+	}
+
+	if opts.json {
+		if *count == 1 {
+			return writeReport(opts, reports[0])
+		}
+		return writeReport(opts, reports)
+	}
+
+	n := float64(*count)
+	fmt.Fprintf(&b, "%-20s %9.1f %9.1f %9.1f %9.1f %10.2f %7.1f\n", "average", float64(total.deadEnds)/n, float64(total.junctions)/n,
+		float64(total.longestCorridor)/n, float64(total.branches)/n, total.averageBranch(), float64(total.loops)/n)
+	return writeOutput(opts, b.String())
+}
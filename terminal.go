@@ -0,0 +1,65 @@
+package main
+
+// This file gathers what depends on the terminal backend. The views come
+// from the awesome-gocui fork of gocui which runs over tcell, so the game
+// gets true colors, wide runes and resize events. Creating the gui and the
+// views or turning colors into ansi sequences goes through here.
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// newGui initializes the terminal. The colors are passed as they are to
+// the terminal so the color schemes may use any of them.
+func newGui() (*gocui.Gui, error) {
+	return gocui.NewGui(gocui.OutputTrue, false)
+}
+
+// setView creates the view named <name> or moves it to the given position.
+// Like gocui, it returns ErrUnknownView when the view was just created.
+func setView(g *gocui.Gui, name string, x0, y0, x1, y1 int) (*gocui.View, error) {
+	return g.SetView(name, x0, y0, x1, y1, 0)
+}
+
+// colorSequence returns the ansi sequence setting <color> as foreground
+// color with <base> 30 or as background color with <base> 40. The default
+// color gives an empty sequence.
+func colorSequence(color gocui.Attribute, base int) string {
+	color &= gocui.AttrColorBits
+	if !color.IsValidColor() {
+		return ""
+	}
+
+	if color&gocui.AttrIsRGBColor != 0 {
+		r, g, b := color.RGB()
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", base+8, r, g, b)
+	}
+
+	index := int(color & 0xFF)
+	if index >= 8 {
+		return fmt.Sprintf("\x1b[%d;5;%dm", base+8, index)
+	}
+	return fmt.Sprintf("\x1b[%dm", base+index)
+}
+
+// clearView empties <v> but keeps its cursor and origin which the
+// backend resets, so the player and the selected lines stay in place.
+func clearView(v *gocui.View) {
+	cx, cy := v.Cursor()
+	ox, oy := v.Origin()
+	v.Clear()
+	_ = v.SetCursorUnrestricted(cx, cy)
+	_ = v.SetOrigin(ox, oy)
+}
+
+// setCursor places the cursor of <v> at (x, y) of its visible area, even
+// before its content is written like the maze cursor at the entrance.
+func setCursor(v *gocui.View, x, y int) error {
+	maxX, maxY := v.Size()
+	if x < 0 || x >= maxX || y < 0 || y >= maxY {
+		return gocui.ErrInvalidPoint
+	}
+	return v.SetCursorUnrestricted(x, y)
+}
@@ -0,0 +1,139 @@
+package main
+
+// This file guards the game against terminals too small for its layout. The
+// bottom bar has fixed widths so below a minimum size a screen asking to
+// enlarge the terminal is displayed over the views until it is large enough.
+
+import (
+	"fmt"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	SMALL = "small"
+
+	// minimum terminal size to display the bottom bar with some
+	// room for its infos view and a small maze above it.
+	MIN_TERM_WIDTH  = SDWIDTH + 18
+	MIN_TERM_HEIGHT = 16
+)
+
+var (
+	// set while the too small terminal screen is displayed.
+	isTermTooSmall = false
+	// view focused and cursor visibility before the screen was displayed.
+	smallFocus  string
+	smallCursor bool
+	// set when the game was paused by the screen.
+	smallPaused = false
+)
+
+// layoutSize returns the terminal size used to place the views. It is
+// never below the minimum so the views always have valid dimensions,
+// the ones out of the terminal being hidden by the screen anyway.
+func layoutSize(g *gocui.Gui) (int, int) {
+	maxX, maxY := g.Size()
+	if maxX < MIN_TERM_WIDTH {
+		maxX = MIN_TERM_WIDTH
+	}
+	if maxY < MIN_TERM_HEIGHT {
+		maxY = MIN_TERM_HEIGHT
+	}
+	return maxX, maxY
+}
+
+// guardTermSize displays the too small terminal screen when the terminal
+// size (maxX, maxY) is below the minimum and removes it once enlarged. The
+// ongoing game is paused meanwhile then resumed. It tells if the terminal
+// is too small.
+func (gm *Game) guardTermSize(g *gocui.Gui, maxX, maxY int) bool {
+	if maxX >= MIN_TERM_WIDTH && maxY >= MIN_TERM_HEIGHT {
+		if isTermTooSmall {
+			gm.restoreTermSize(g)
+		}
+		return false
+	}
+
+	if !isTermTooSmall {
+		isTermTooSmall = true
+		logWarn("Terminal too small", "size", fmt.Sprintf("%dx%d", maxX, maxY), "expected", fmt.Sprintf("%dx%d", MIN_TERM_WIDTH, MIN_TERM_HEIGHT))
+		smallFocus, smallCursor = "", g.Cursor
+		if cv := g.CurrentView(); cv != nil {
+			smallFocus = cv.Name()
+		}
+
+		if mv, err := g.View(MAZE); err == nil && !gm.paused && !gm.over {
+			if err = gm.togglePause(g, mv); err != nil {
+				logError("Failed to pause the game for the too small terminal", "err", err)
+			} else {
+				smallPaused = true
+			}
+		}
+	}
+
+	// a view needs at least one character inside its frame.
+	if maxX < 3 || maxY < 3 {
+		return true
+	}
+
+	smallView, err := setView(g, SMALL, 0, 0, maxX-1, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display too small terminal view", "err", err)
+		return true
+	}
+
+	smallView.Frame = true
+	smallView.FgColor = gocui.ColorYellow | gocui.AttrBold
+	smallView.Wrap = true
+	_, _ = g.SetViewOnTop(SMALL)
+	_, _ = g.SetCurrentView(SMALL)
+	g.Cursor = false
+
+	lines := []string{
+		tr("Terminal too small"),
+		"",
+		tr("Enlarge your terminal"),
+		trf("to at least %d x %d", MIN_TERM_WIDTH, MIN_TERM_HEIGHT),
+		trf("(now %d x %d)", maxX, maxY),
+	}
+	if smallPaused {
+		lines = append(lines, "", tr("The game is paused."))
+	}
+
+	clearView(smallView)
+	for i := 0; i < (maxY-2-len(lines))/2; i++ {
+		fmt.Fprintln(smallView)
+	}
+	for _, line := range lines {
+		fmt.Fprintln(smallView, center(line, maxX-2, " "))
+	}
+
+	return true
+}
+
+// restoreTermSize removes the too small terminal screen, gives the focus
+// back and resumes the game it paused.
+func (gm *Game) restoreTermSize(g *gocui.Gui) {
+	isTermTooSmall = false
+	logInfo("Terminal large enough again")
+	if err := g.DeleteView(SMALL); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete too small terminal view", "err", err)
+	}
+
+	g.Cursor = smallCursor
+	focus := smallFocus
+	if _, err := g.View(focus); err != nil {
+		focus = OUTPUTS
+	}
+	_, _ = g.SetCurrentView(focus)
+
+	if smallPaused {
+		smallPaused = false
+		if mv, err := g.View(MAZE); err == nil && gm.paused {
+			if err = gm.togglePause(g, mv); err != nil {
+				logError("Failed to resume the game after the too small terminal", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+// This file measures and pads the texts written into the views by the
+// columns they take on screen rather than by their bytes, so the labels
+// with accents, the emoji and the wide characters stay aligned.
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// textWidth returns the number of columns taken by <s> on screen.
+func textWidth(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// centers a given string within a width of columns by padding with the
+// single column <fill>. The string is returned as is when it does not fit
+// into the width.
+func center(s string, width int, fill string) string {
+	pad := (width - textWidth(s)) / 2
+	if pad <= 0 {
+		return s
+	}
+	return strings.Repeat(fill, pad) + s + strings.Repeat(fill, pad)
+}
+
+// padText pads <s> with spaces on its right up to <width> columns
+// like the %-*s verb does for the texts of single column characters.
+func padText(s string, width int) string {
+	if pad := width - textWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package main
+
+// This file provides the decorative themes of maze walls. When enabled, each
+// maze gets a glyph and color set picked from its seed so it looks distinct.
+
+import (
+	"hash/fnv"
+	"image/color"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+// wallTheme describes how walls are drawn on the gui and on images.
+// Glyphs must be single byte since collisions read the view buffer.
+type wallTheme struct {
+	name       string
+	horizontal byte
+	vertical   byte
+	color      gocui.Attribute
+	imageColor color.RGBA
+}
+
+var (
+	// default underscore/pipe walls.
+	classicTheme = wallTheme{"classic", '_', '|', gocui.ColorYellow, color.RGBA{0x00, 0x00, 0x00, 0xFF}}
+
+	// themes available for decorative walls.
+	wallThemes = []wallTheme{
+		{"hedge", ',', '#', gocui.ColorGreen, color.RGBA{0x22, 0x8B, 0x22, 0xFF}},
+		{"stone", '_', 'H', gocui.ColorWhite, color.RGBA{0x70, 0x70, 0x70, 0xFF}},
+		{"neon", '.', ':', gocui.ColorMagenta | gocui.AttrBold, color.RGBA{0xE0, 0x10, 0xE0, 0xFF}},
+	}
+
+	isThemedWalls = false
+)
+
+// pickTheme returns the walls theme of a maze based on its seed.
+func pickTheme(seed int64) wallTheme {
+	if !isThemedWalls {
+		return classicTheme
+	}
+
+	return wallThemes[uint64(seed)%uint64(len(wallThemes))]
+}
+
+// themeMaze replaces the classic glyphs of an ascii maze by the theme ones.
+func themeMaze(data string, t wallTheme) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_':
+			return rune(t.horizontal)
+		case '|':
+			return rune(t.vertical)
+		}
+		return r
+	}, data)
+}
+
+// hashSeed derives a seed from maze data for mazes without known seed.
+func hashSeed(data string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(data))
+	return int64(h.Sum64())
+}
+
+// toggleThemedWalls switches the decorative walls on/off and keeps
+// it into the config file. It applies to the next maze displayed.
+func (gm *Game) toggleThemedWalls(g *gocui.Gui, v *gocui.View) error {
+	isThemedWalls = !isThemedWalls
+	refreshOutputsTitle(g)
+	if err := gm.saveSettings(); err != nil {
+		logError("Failed to save settings file", "err", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+// This file provides the notifications shown to the player for a short
+// while at the bottom of the outputs view, like a game saved or a failure
+// which would otherwise only be found into the logs file.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	TOAST = "toast"
+	// time a notification stays displayed.
+	TOAST_DURATION = 3 * time.Second
+)
+
+// notifications waiting to be displayed. Sending never blocks so they
+// can be pushed from anywhere, even before the gui runs.
+var toasts = make(chan string, 10)
+
+// notify pushes a notification to display. It is dropped
+// when too many are waiting.
+func notify(format string, args ...interface{}) {
+	select {
+	case toasts <- trf(format, args...):
+	default:
+	}
+}
+
+// updateToastView displays each notification in turn for TOAST_DURATION.
+func updateToastView(g *gocui.Gui) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case message := <-toasts:
+			g.Update(func(g *gocui.Gui) error {
+				displayToast(g, message)
+				return nil
+			})
+		}
+
+		select {
+		case <-exit:
+			return
+		case <-time.After(TOAST_DURATION):
+		}
+
+		if len(toasts) == 0 {
+			g.Update(func(g *gocui.Gui) error {
+				closeToastView(g)
+				return nil
+			})
+		}
+	}
+}
+
+// displayToast shows <message> centered at the bottom of the outputs view.
+func displayToast(g *gocui.Gui, message string) {
+	maxX, maxY := g.Size()
+	width := textWidth(message) + 3
+	if width > maxX-4 {
+		width = maxX - 4
+	}
+
+	toastView, err := setView(g, TOAST, (maxX-width)/2, maxY-7, (maxX+width)/2, maxY-5)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display toast view", "err", err)
+		return
+	}
+
+	toastView.Frame = true
+	toastView.FgColor = scheme().accent
+	toastView.Editable = false
+	toastView.Wrap = false
+	clearView(toastView)
+	fmt.Fprint(toastView, " "+message)
+	_, _ = g.SetViewOnTop(TOAST)
+}
+
+// closeToastView removes the notification displayed if any.
+func closeToastView(g *gocui.Gui) {
+	if err := g.DeleteView(TOAST); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete toast view", "err", err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+// This file implements the fast travel. The player moves in a direction
+// until a wall or a junction in a single action. The cells passed are
+// recorded as moves but the position and the maze are only updated once at
+// the cell reached, which keeps big mazes playable. The travels default to
+// Shift with the arrows, to PgUp, PgDn, Home and End for the terminals not
+// reporting Shift, and to the Shift letters of the keys schemes.
+
+import (
+	"github.com/awesome-gocui/gocui"
+)
+
+// travelUp travels up until a wall or a junction.
+func (gm *Game) travelUp(g *gocui.Gui, v *gocui.View) error {
+	return gm.travel(g, v, 0, -1)
+}
+
+// travelDown travels down until a wall or a junction.
+func (gm *Game) travelDown(g *gocui.Gui, v *gocui.View) error {
+	return gm.travel(g, v, 0, 1)
+}
+
+// travelLeft travels left until a wall or a junction.
+func (gm *Game) travelLeft(g *gocui.Gui, v *gocui.View) error {
+	return gm.travel(g, v, -1, 0)
+}
+
+// travelRight travels right until a wall or a junction.
+func (gm *Game) travelRight(g *gocui.Gui, v *gocui.View) error {
+	return gm.travel(g, v, 1, 0)
+}
+
+// travel moves the cursor by (dx, dy) as long as the way ahead is the
+// only one. It also stops on the cells where the round may change like
+// the exit, a checkpoint, an ice tile or the last move of the budget.
+func (gm *Game) travel(g *gocui.Gui, v *gocui.View, dx, dy int) error {
+	if v == nil || !gm.canMove() {
+		return nil
+	}
+
+	if !gm.isWayFree(v, dx, dy) {
+		gm.bumpWall(g, v, dx, dy)
+		return nil
+	}
+
+	// a travel cannot be longer than the maze itself.
+	for limit := cursorPositions(gm.maze); limit > 0; limit-- {
+		gm.moveMazeCursor(v, dx, dy)
+		if gm.isTravelStop(v, dx, dy) {
+			break
+		}
+
+		// passed cells only count as moves.
+		gm.recordMove(v)
+		gm.countMove(g)
+	}
+
+	cx, cy := gm.mazeCursor(v)
+	publishMove(cx, cy)
+	gm.playerMoved(g, v)
+	gm.slide(g, v, dx, dy)
+	return nil
+}
+
+// isTravelStop tells whether a travel in the direction (dx, dy)
+// ends at the current cursor position.
+func (gm *Game) isTravelStop(v *gocui.View, dx, dy int) bool {
+	cx, cy := gm.mazeCursor(v)
+	if gm.isAtExit(v) || gm.isOnIce(cx, cy) || gm.isCheckpoint(cx, cy) {
+		return true
+	}
+
+	if gm.moves.limited && gm.maze != nil && gm.moves.left <= 1 {
+		return true
+	}
+
+	for _, way := range [4][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}} {
+		if way[0] == -dx && way[1] == -dy {
+			continue
+		}
+
+		// any way other than straight ahead makes a junction.
+		if gm.isWayFree(v, way[0], way[1]) != (way[0] == dx && way[1] == dy) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package main
+
+// This file gives a single solution to the mazes published as puzzles. The
+// cells are walked from the entrance into a tree. Each passage left out of
+// the tree closes a loop, which offers another way to the exit when it goes
+// across the path of the tree from the entrance to the exit. Closing those
+// passages leaves a single path while the other loops are kept. The cells
+// out of reach are linked to the tree first so the repair keeps them all.
+
+// solutionRepair tells how a maze was changed to get a single solution.
+type solutionRepair struct {
+	// walls opened to link the cells out of reach of the entrance.
+	opened int
+	// passages closed to cut the other ways to the exit.
+	closed int
+	// cells of the single path from the entrance to the exit.
+	length int
+}
+
+// walkMaze returns the tree of the cells of <maze> reached from <in>, as
+// the parent of each cell and the cells in the order reached. The cells are
+// indexed by y*width+x and the parent is -1 for the cells not reached. When
+// <link>, the cells out of reach are linked to the tree by opening a wall
+// and the number of walls opened is returned.
+func walkMaze(maze *Grid, in [2]int, link bool) ([]int32, []int32, int) {
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+	width, height := maze.Width(), maze.Height()
+
+	parents := make([]int32, width*height)
+	for i := range parents {
+		parents[i] = -1
+	}
+	root := in[1]*width + in[0]
+	parents[root] = int32(root)
+	order := append(make([]int32, 0, width*height), int32(root))
+
+	head := 0
+	expand := func() {
+		for ; head < len(order); head++ {
+			i := int(order[head])
+			x, y := i%width, i/width
+			for _, d := range [4]int{N, S, E, W} {
+				nX, nY := moveTo(x, y, d)
+				if !maze.Inside(nX, nY) || maze.At(x, y)&d == 0 || maze.At(nX, nY)&oppositeDirections[d] == 0 {
+					continue
+				}
+				if j := nY*width + nX; parents[j] < 0 {
+					parents[j] = int32(i)
+					order = append(order, int32(j))
+				}
+			}
+		}
+	}
+	expand()
+
+	// each cell out of reach next to the tree gets linked to it, then its
+	// own passages are walked before looking for the next one.
+	opened := 0
+	for grown := link; grown && len(order) < width*height; {
+		grown = false
+		for i := range parents {
+			if parents[i] >= 0 {
+				continue
+			}
+
+			x, y := i%width, i/width
+			for _, d := range [4]int{N, S, E, W} {
+				nX, nY := moveTo(x, y, d)
+				if !maze.Inside(nX, nY) || parents[nY*width+nX] < 0 {
+					continue
+				}
+
+				maze.Open(x, y, d)
+				maze.Open(nX, nY, oppositeDirections[d])
+				parents[i] = int32(nY*width + nX)
+				order = append(order, int32(i))
+				opened++
+				grown = true
+				expand()
+				break
+			}
+		}
+	}
+
+	return parents, order, opened
+}
+
+// solutionForks returns the passages of <maze> out of the tree walked into
+// <parents> and <order> which close a loop going across the path of the
+// tree to <out>. Each passage is given by its cell and direction.
+func solutionForks(maze *Grid, parents, order []int32, out [2]int) [][3]int {
+	width := maze.Width()
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+
+	// index on the path of each of its cells from the entrance.
+	steps := make([]int32, len(parents))
+	for i := range steps {
+		steps[i] = -1
+	}
+	var path []int32
+	for i := int32(out[1]*width + out[0]); ; i = parents[i] {
+		path = append(path, i)
+		if parents[i] == i {
+			break
+		}
+	}
+	for n, i := range path {
+		steps[i] = int32(len(path) - 1 - n)
+	}
+
+	// index of the last cell of the path met from the entrance to each cell.
+	// a loop crosses the path when its cells do not share that last cell.
+	last := make([]int32, len(parents))
+	for _, i := range order {
+		if last[i] = steps[i]; last[i] < 0 {
+			last[i] = last[parents[i]]
+		}
+	}
+
+	var forks [][3]int
+	for _, i := range order {
+		x, y := int(i)%width, int(i)/width
+		// each passage is seen once from its top or left cell.
+		for _, d := range [2]int{S, W} {
+			nX, nY := moveTo(x, y, d)
+			if !maze.Inside(nX, nY) || maze.At(x, y)&d == 0 || maze.At(nX, nY)&oppositeDirections[d] == 0 {
+				continue
+			}
+
+			j := int32(nY*width + nX)
+			if parents[j] < 0 || parents[j] == i || parents[i] == j {
+				continue
+			}
+			if last[i] != last[j] {
+				forks = append(forks, [3]int{x, y, d})
+			}
+		}
+	}
+
+	return forks
+}
+
+// makeSolutionUnique links the cells of <maze> out of reach of the entrance
+// then closes the passages offering other ways to the exit, so a single path
+// links the entrance to the exit.
+func makeSolutionUnique(maze *Grid) solutionRepair {
+	var oppositeDirections = map[int]int{N: S, S: N, E: W, W: E}
+	in, out := mazeDoors(maze)
+	parents, order, opened := walkMaze(maze, in, true)
+
+	forks := solutionForks(maze, parents, order, out)
+	for _, f := range forks {
+		nX, nY := moveTo(f[0], f[1], f[2])
+		maze.Close(f[0], f[1], f[2])
+		maze.Close(nX, nY, oppositeDirections[f[2]])
+	}
+
+	length := 0
+	for i := int32(out[1]*maze.Width() + out[0]); ; i = parents[i] {
+		length++
+		if parents[i] == i {
+			break
+		}
+	}
+
+	return solutionRepair{opened: opened, closed: len(forks), length: length}
+}
+
+// hasUniqueSolution tells if a single path links the entrance of <maze>
+// to its exit.
+func hasUniqueSolution(maze *Grid) bool {
+	in, out := mazeDoors(maze)
+	parents, order, _ := walkMaze(maze, in, false)
+	if parents[out[1]*maze.Width()+out[0]] < 0 {
+		return false
+	}
+	return len(solutionForks(maze, parents, order, out)) == 0
+}
@@ -0,0 +1,354 @@
+package main
+
+// This file checks mazes read from files and, in debug mode, the mazes just
+// generated. A valid maze has consistent walls between neighbor cells, a
+// single entrance on its top row and a single exit on its bottom row, every
+// cell reachable and no loop unless allowed.
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// maximum number of problems reported of each kind.
+	VALIDATE_MAX_REPORTED = 50
+)
+
+// mazeProblem describes an issue found on a maze at cell (x,y). The
+// coordinates are -1 when the issue is not about a given cell.
+type mazeProblem struct {
+	Kind    string `json:"kind"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Message string `json:"message"`
+}
+
+// validationReport is the JSON report of the validate command.
+type validationReport struct {
+	File     string        `json:"file"`
+	Width    int           `json:"width"`
+	Height   int           `json:"height"`
+	Valid    bool          `json:"valid"`
+	Problems []mazeProblem `json:"problems"`
+}
+
+// hasPassage tells if the cells (x,y) and its neighbor toward <d> are
+// linked. Both cells must have their facing walls opened.
+func hasPassage(maze *Grid, x, y, d int) bool {
+	opposite := map[int]int{N: S, S: N, E: W, W: E}
+	nX, nY := moveTo(x, y, d)
+	if nY < 0 || nY >= maze.Height() || nX < 0 || nX >= maze.Width() {
+		return false
+	}
+	return (maze.At(x, y)&d) != 0 && (maze.At(nX, nY)&opposite[d]) != 0
+}
+
+// Validation is the structure of a maze found by inspectGrid. The cells
+// are given by their (x, y) coordinates and the walls by their cell with
+// the direction of the wall as third value.
+type Validation struct {
+	// cells opening a wall on the top row and on the bottom row.
+	Entrances [][2]int
+	Exits     [][2]int
+	// walls opened out of the maze other than the doors.
+	OpenBorders [][3]int
+	// walls opened toward a neighbor cell whose facing wall is closed.
+	OneSided [][3]int
+	// cells not linked to the first entrance.
+	Unreachable [][2]int
+	// passages between linked cells closing a loop.
+	Loops [][2][2]int
+	// set when the first exit is linked to the first entrance.
+	ExitReachable bool
+}
+
+// Perfect tells if the maze has consistent walls, a single entrance and a
+// single exit linked together, every cell reachable and no loop.
+func (v Validation) Perfect() bool {
+	return v.Valid(false)
+}
+
+// Valid tells if the maze is perfect once its loops are ignored when
+// <allowLoops>, like for the braided mazes.
+func (v Validation) Valid(allowLoops bool) bool {
+	return len(v.Problems(allowLoops)) == 0
+}
+
+// inspectGrid walks the cells of the grid <g> and returns its doors, its wall
+// inconsistencies, its cells unreachable from the entrance and its loops.
+// It only relies on the walls of the cells so it checks any generator.
+func inspectGrid(g *Grid) Validation {
+	height, width := g.Height(), g.Width()
+	opposite := map[int]int{N: S, S: N, E: W, W: E}
+	v := Validation{}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			for _, d := range [4]int{N, S, E, W} {
+				if (g.At(x, y) & d) == 0 {
+					continue
+				}
+
+				nX, nY := moveTo(x, y, d)
+				switch {
+				case d == N && nY < 0:
+					v.Entrances = append(v.Entrances, [2]int{x, y})
+				case d == S && nY >= height:
+					v.Exits = append(v.Exits, [2]int{x, y})
+				case !g.Inside(nX, nY):
+					v.OpenBorders = append(v.OpenBorders, [3]int{x, y, d})
+				case (g.At(nX, nY) & opposite[d]) == 0:
+					v.OneSided = append(v.OneSided, [3]int{x, y, d})
+				}
+			}
+		}
+	}
+
+	// walk each group of linked cells starting with the entrance one. a
+	// passage to an already visited cell other than the parent is a loop.
+	start := [2]int{0, 0}
+	if len(v.Entrances) > 0 {
+		start = v.Entrances[0]
+	}
+
+	// index of the group and of the parent of each cell. -1 means not visited yet.
+	groups := make([]int32, width*height)
+	parents := make([]int32, width*height)
+	for i := range groups {
+		groups[i] = -1
+	}
+
+	roots := [][2]int{start}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			roots = append(roots, [2]int{x, y})
+		}
+	}
+
+	for i, root := range roots {
+		if groups[root[1]*width+root[0]] >= 0 {
+			continue
+		}
+
+		if i > 0 {
+			v.Unreachable = append(v.Unreachable, root)
+		}
+
+		groups[root[1]*width+root[0]] = int32(i)
+		parents[root[1]*width+root[0]] = int32(root[1]*width + root[0])
+		queue := [][2]int{root}
+		for len(queue) > 0 {
+			cell := queue[0]
+			queue = queue[1:]
+			for _, d := range [4]int{N, S, E, W} {
+				if !hasPassage(g, cell[0], cell[1], d) {
+					continue
+				}
+
+				nX, nY := moveTo(cell[0], cell[1], d)
+				if groups[nY*width+nX] < 0 {
+					groups[nY*width+nX] = int32(i)
+					parents[nY*width+nX] = int32(cell[1]*width + cell[0])
+					queue = append(queue, [2]int{nX, nY})
+					if i > 0 {
+						v.Unreachable = append(v.Unreachable, [2]int{nX, nY})
+					}
+					continue
+				}
+
+				// each passage closing a loop is seen from both sides.
+				if int(parents[cell[1]*width+cell[0]]) != nY*width+nX && (nY > cell[1] || (nY == cell[1] && nX > cell[0])) {
+					v.Loops = append(v.Loops, [2][2]int{cell, {nX, nY}})
+				}
+			}
+		}
+	}
+
+	if len(v.Exits) > 0 && len(v.Entrances) > 0 {
+		v.ExitReachable = groups[v.Exits[0][1]*width+v.Exits[0][0]] == 0
+	}
+
+	return v
+}
+
+// Problems describes what prevents the maze from being perfect, at most
+// VALIDATE_MAX_REPORTED of each kind. Loops are left out when <allowLoops>.
+func (v Validation) Problems(allowLoops bool) []mazeProblem {
+	names := map[int]string{N: "north", S: "south", E: "east", W: "west"}
+
+	var problems []mazeProblem
+	counts := make(map[string]int)
+	report := func(kind string, x, y int, format string, args ...interface{}) {
+		counts[kind]++
+		if counts[kind] <= VALIDATE_MAX_REPORTED {
+			problems = append(problems, mazeProblem{kind, x, y, fmt.Sprintf(format, args...)})
+		}
+	}
+
+	for _, w := range v.OpenBorders {
+		report("border", w[0], w[1], "cell (%d,%d) opens %s out of the maze", w[0], w[1], names[w[2]])
+	}
+
+	for _, w := range v.OneSided {
+		nX, nY := moveTo(w[0], w[1], w[2])
+		report("wall", w[0], w[1], "cell (%d,%d) opens %s but cell (%d,%d) is closed", w[0], w[1], names[w[2]], nX, nY)
+	}
+
+	if len(v.Entrances) == 0 {
+		report("entrance", -1, -1, "no entrance on the top row")
+	}
+	for i := 1; i < len(v.Entrances); i++ {
+		report("entrance", v.Entrances[i][0], v.Entrances[i][1], "extra entrance at cell (%d,%d)", v.Entrances[i][0], v.Entrances[i][1])
+	}
+
+	if len(v.Exits) == 0 {
+		report("exit", -1, -1, "no exit on the bottom row")
+	}
+	for i := 1; i < len(v.Exits); i++ {
+		report("exit", v.Exits[i][0], v.Exits[i][1], "extra exit at cell (%d,%d)", v.Exits[i][0], v.Exits[i][1])
+	}
+
+	for _, c := range v.Unreachable {
+		report("unreachable", c[0], c[1], "cell (%d,%d) cannot be reached from the entrance", c[0], c[1])
+	}
+
+	if !allowLoops {
+		for _, l := range v.Loops {
+			report("loop", l[0][0], l[0][1], "loop closed between cells (%d,%d) and (%d,%d)", l[0][0], l[0][1], l[1][0], l[1][1])
+		}
+	}
+
+	if len(v.Exits) > 0 && len(v.Entrances) > 0 && !v.ExitReachable {
+		report("exit", v.Exits[0][0], v.Exits[0][1], "exit at cell (%d,%d) cannot be reached from the entrance", v.Exits[0][0], v.Exits[0][1])
+	}
+
+	for _, kind := range []string{"border", "wall", "entrance", "exit", "unreachable", "loop"} {
+		if count := counts[kind]; count > VALIDATE_MAX_REPORTED {
+			problems = append(problems, mazeProblem{kind, -1, -1, fmt.Sprintf("%d more %s problems not listed", count-VALIDATE_MAX_REPORTED, kind)})
+		}
+	}
+
+	return problems
+}
+
+// validateMaze returns the problems found on <maze>. Loops are reported
+// unless <allowLoops> since a perfect maze has a single path between cells.
+func validateMaze(maze *Grid, allowLoops bool) []mazeProblem {
+	return inspectGrid(maze).Problems(allowLoops)
+}
+
+// checkGeneratedMaze logs the problems of the <maze> just built by the
+// generator named <algorithm> from <seed>. Loops are expected once braided.
+func checkGeneratedMaze(maze *Grid, algorithm string, seed int64, braided bool) {
+	problems := validateMaze(maze, braided)
+	if len(problems) == 0 {
+		logDebug("Generated maze is valid", "algorithm", algorithm, "seed", seed, "width", maze.Width(), "height", maze.Height())
+		return
+	}
+
+	for _, p := range problems {
+		logWarn("Generated maze is not valid", "algorithm", algorithm, "seed", seed, "kind", p.Kind, "problem", p.Message)
+	}
+}
+
+// readMazeFile reads a maze from the file at <path> or from the standard
+// input with "-". Saved sessions are read as well as the ascii and JSON
+// formats of the mazes.
+func readMazeFile(path string) (*Grid, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) || bytes.Contains(data, []byte(SESSION_CHECKSUM)) {
+		s, _, err := decodeSession(data)
+		if err != nil {
+			return nil, err
+		}
+		return GridFromRows(s.Grid), nil
+	}
+
+	maze, err := readMaze(bytes.NewReader(data))
+	if err != nil {
+		// sessions saved by the older versions start with the cursor.
+		if s, _, serr := decodeSession(data); serr == nil {
+			return GridFromRows(s.Grid), nil
+		}
+		return nil, err
+	}
+
+	return maze, nil
+}
+
+// runValidate checks the mazes of the given files and reports their
+// problems. It fails when any maze is not valid.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "validate [flags] <file>...", "check the walls, doors, connectivity and loops of mazes read from files")
+	allowLoops := fs.Bool("allow-loops", false, "accept mazes with loops like the braided ones")
+	asJSON := fs.Bool("json", false, "print the problems found as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no maze file to validate")
+	}
+
+	var reports []validationReport
+	invalid := 0
+	for _, path := range fs.Args() {
+		report := validationReport{File: path, Problems: []mazeProblem{}}
+		maze, err := readMazeFile(path)
+		if err != nil {
+			report.Problems = append(report.Problems, mazeProblem{"read", -1, -1, err.Error()})
+		} else {
+			report.Width, report.Height = maze.Width(), maze.Height()
+			report.Problems = append(report.Problems, validateMaze(maze, *allowLoops)...)
+		}
+
+		report.Valid = len(report.Problems) == 0
+		if !report.Valid {
+			invalid++
+		}
+		reports = append(reports, report)
+
+		if *asJSON {
+			continue
+		}
+
+		if report.Valid {
+			fmt.Printf("%s: valid maze %d x %d\n", path, report.Width, report.Height)
+			continue
+		}
+
+		fmt.Printf("%s: %d problems\n", path, len(report.Problems))
+		for _, p := range report.Problems {
+			fmt.Printf("  [%s] %s\n", p.Kind, p.Message)
+		}
+	}
+
+	if *asJSON {
+		if err := writeJSON(os.Stdout, reports); err != nil {
+			return err
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d mazes are not valid", invalid, len(reports))
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+// This file reports the build of the program. The version, the commit and
+// the build date are injected at build time with the linker flags below,
+// otherwise the module version recorded by go install is used if any.
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" .
+//
+// The saved sessions are stamped with the version so a file written by
+// a newer program can be told apart from a corrupt one.
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+// appVersion returns the version of the program.
+func appVersion() string {
+	if version == "dev" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+	}
+	return version
+}
+
+// versionLine returns the version, the commit and the build date on one line.
+func versionLine() string {
+	return fmt.Sprintf("gomazes %s (commit %s, built %s)", appVersion(), commit, buildDate)
+}
+
+// runVersion prints the build of the program.
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	fs.Usage = commandUsage(fs, "version [flags]", "print the version, the commit and the build date of the program")
+	asJSON := fs.Bool("json", false, "print the build as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(os.Stdout, map[string]string{
+			"version":    appVersion(),
+			"commit":     commit,
+			"build_date": buildDate,
+			"go":         runtime.Version(),
+			"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+		})
+	}
+
+	fmt.Println(versionLine())
+	fmt.Printf("%s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+// This file celebrates the exit reached. The maze flashes while confetti
+// pops over its cells for a short while, with the terminal bell rung when
+// enabled, so a won round cannot be mistaken for a stop near the edge.
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	VICTORY = "victory"
+	// frames of the animation and the delay between them.
+	VICTORY_FRAMES = 12
+	VICTORY_FRAME  = 120 * time.Millisecond
+	// confetti displayed on each frame.
+	VICTORY_CONFETTI = 10
+)
+
+var (
+	confettiGlyphs = []rune{'*', '+', 'o', '~', '%'}
+	confettiColors = []gocui.Attribute{gocui.ColorRed, gocui.ColorGreen, gocui.ColorYellow, gocui.ColorBlue, gocui.ColorMagenta, gocui.ColorCyan, gocui.ColorWhite}
+)
+
+// celebrateVictory starts the victory animation over the maze view
+// and rings the bell if enabled.
+func (gm *Game) celebrateVictory(g *gocui.Gui) {
+	bellVictory.ring(g)
+
+	gm.closeVictory(g)
+	if gm.maze == nil {
+		return
+	}
+
+	for i := 0; i < VICTORY_CONFETTI; i++ {
+		gm.confetti = append(gm.confetti, gm.newMarker(fmt.Sprintf("%s%d", VICTORY, i), 0, 0))
+	}
+
+	gm.stopVictory = make(chan struct{})
+	wg.Add(1)
+	go gm.animateVictory(g, gm.maze.Width(), gm.maze.Height(), gm.stopVictory)
+}
+
+// animateVictory draws the frames of the victory animation then
+// restores the maze view.
+func (gm *Game) animateVictory(g *gocui.Gui, width, height int, stop chan struct{}) {
+	defer wg.Done()
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for frame := 0; frame < VICTORY_FRAMES; frame++ {
+		flash := frame%2 == 0
+		g.Update(func(g *gocui.Gui) error {
+			select {
+			case <-stop:
+				// animation was closed in the meantime.
+				return nil
+			default:
+			}
+
+			gm.drawVictoryFrame(g, r, width, height, flash)
+			return nil
+		})
+
+		select {
+		case <-exit:
+			return
+		case <-stop:
+			return
+		case <-time.After(VICTORY_FRAME):
+		}
+	}
+
+	g.Update(func(g *gocui.Gui) error {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+
+		gm.closeVictory(g)
+		return nil
+	})
+}
+
+// drawVictoryFrame scatters the confetti over random cells of the maze
+// of size (width, height) and flashes its background.
+func (gm *Game) drawVictoryFrame(g *gocui.Gui, r *rand.Rand, width, height int, flash bool) {
+	bg := scheme().path
+	if flash {
+		bg = scheme().doors
+	}
+	setMazeBackground(g, bg)
+
+	for _, c := range gm.confetti {
+		c.glyph = confettiGlyphs[r.Intn(len(confettiGlyphs))]
+		c.color = confettiColors[r.Intn(len(confettiColors))] | gocui.AttrBold
+		_ = gm.renderer.DrawOverlay(c, 2*r.Intn(width)+1, r.Intn(height)+1)
+	}
+}
+
+// setMazeBackground paints the background of the maze and zoomed views.
+func setMazeBackground(g *gocui.Gui, bg gocui.Attribute) {
+	for _, name := range []string{MAZE, ZOOM} {
+		if v, err := g.View(name); err == nil {
+			v.BgColor = bg
+		}
+	}
+}
+
+// closeVictory stops the victory animation if any and removes its confetti.
+func (gm *Game) closeVictory(g *gocui.Gui) {
+	if gm.stopVictory == nil {
+		return
+	}
+
+	close(gm.stopVictory)
+	gm.stopVictory = nil
+	for _, c := range gm.confetti {
+		c.close(g)
+	}
+	gm.confetti = nil
+	setMazeBackground(g, scheme().path)
+}
@@ -0,0 +1,25 @@
+package main
+
+// This file embeds the web page of the serve command. The page is a single
+// file drawing the mazes on a canvas and playing them with the arrows or
+// WASD keys. It only talks to the REST api so any other client may do the
+// same, and the maze id in the address of the page makes it shareable.
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// newWebHandler returns the handler serving the embedded web page.
+func newWebHandler() http.Handler {
+	root, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		// the folder is embedded at build time so it always exists.
+		panic(err)
+	}
+	return http.FileServer(http.FS(root))
+}
@@ -0,0 +1,302 @@
+package main
+
+// This file draws the maze with large cells of 3 columns and 2 lines instead
+// of 2 columns and 1 line. The maze view keeps the game going with its small
+// cells hidden below the outputs view, and it is moved after each move so its
+// cursor stands on the player position into the zoomed view.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awesome-gocui/gocui"
+)
+
+const (
+	ZOOM       = "zoom"
+	ZOOM_ERROR = "zoomerror"
+)
+
+var (
+	// large cells wanted by the player.
+	isZoomed = false
+	// set while the zoomed view is displayed since the maze
+	// may be too large to be zoomed into the terminal.
+	isZoomShown = false
+)
+
+// zoomCursor converts the maze view cursor (cx, cy) into the zoomed view
+// coordinates. A cell takes the 2 columns after its wall column and the
+// line above the line of its south wall.
+func zoomCursor(cx, cy int) (int, int) {
+	zx := 3 * (cx / 2)
+	if cx%2 == 1 {
+		zx++
+	}
+
+	if cy == 0 {
+		return zx, 0
+	}
+	return zx, 2*cy - 1
+}
+
+// zoomToCursor converts the zoomed view coordinates (zx, zy) into the maze
+// view cursor. The wall lines belong to the row of cells above them.
+func zoomToCursor(zx, zy int) (int, int) {
+	cx := 2 * (zx / 3)
+	if zx%3 != 0 {
+		cx++
+	}
+
+	return cx, (zy + 1) / 2
+}
+
+// zoomFits tells if the zoomed maze fits into the outputs view of size (vx, vy).
+func (gm *Game) zoomFits(vx, vy int) bool {
+	return 3*gm.width+2 <= vx && 2*gm.height+2 <= vy
+}
+
+// zoomViewPosition returns the coordinates of the zoomed view centered
+// into the outputs view of size (vx, vy).
+func (gm *Game) zoomViewPosition(vx, vy int) (int, int, int, int) {
+	zx1 := (vx - (3*gm.width + 2)) / 2
+	zy1 := (vy - (2*gm.height + 2)) / 2
+	return zx1, zy1, zx1 + (3*gm.width + 2), zy1 + (2*gm.height + 2)
+}
+
+// zoomGlyphs returns the corners, horizontal and vertical glyphs drawing
+// the zoomed walls with the walls style and the theme in use. The ascii
+// style draws the corners with plus signs and the classic horizontal walls
+// with dashes since underscores would stick to the line below.
+func zoomGlyphs(style wallStyle, t wallTheme) ([16]rune, rune, rune) {
+	if style.corners != nil {
+		return *style.corners, style.horizontal, style.corners[3]
+	}
+
+	horizontal, vertical, corner := rune(t.horizontal), rune(t.vertical), rune(t.vertical)
+	if horizontal == '_' {
+		horizontal = '-'
+	}
+	if t.name == classicTheme.name {
+		corner = '+'
+	}
+
+	var corners [16]rune
+	for i := range corners {
+		corners[i] = corner
+	}
+	corners[0] = ' '
+	return corners, horizontal, vertical
+}
+
+// zoomMazeLines draws the maze of the classic ascii format <ascii> with
+// large cells. The lines alternate between the walls lines and the cells
+// lines so each corner is joined by the right glyph.
+func (gm *Game) zoomMazeLines(ascii string, style wallStyle, t wallTheme) [][]rune {
+	rows := strings.Split(ascii, "\n")
+	at := func(x, y int) byte {
+		if y < 0 || y >= len(rows) || x < 0 || x >= len(rows[y]) {
+			return ' '
+		}
+		return rows[y][x]
+	}
+
+	// wallBelow tells if the border line <y> has a wall above the
+	// cell column x and wallRight if the vertical border x has a wall
+	// along the row y.
+	wallBelow := func(x, y int) bool { return at(2*x+1, y) == '_' }
+	wallRight := func(x, y int) bool { return at(2*x, y+1) == '|' }
+
+	corners, horizontal, vertical := zoomGlyphs(style, t)
+	lines := make([][]rune, 0, 2*gm.height+1)
+	for y := 0; y <= gm.height; y++ {
+		line := make([]rune, 0, 3*gm.width+1)
+		for x := 0; x <= gm.width; x++ {
+			glyph := 0
+			if y > 0 && wallRight(x, y-1) {
+				glyph |= 1
+			}
+			if y < gm.height && wallRight(x, y) {
+				glyph |= 2
+			}
+			if x > 0 && wallBelow(x-1, y) {
+				glyph |= 4
+			}
+			if x < gm.width && wallBelow(x, y) {
+				glyph |= 8
+			}
+			line = append(line, corners[glyph])
+
+			if x < gm.width {
+				if wallBelow(x, y) {
+					line = append(line, horizontal, horizontal)
+				} else {
+					line = append(line, ' ', ' ')
+				}
+			}
+		}
+		lines = append(lines, line)
+
+		if y == gm.height {
+			break
+		}
+
+		line = make([]rune, 0, 3*gm.width+1)
+		for x := 0; x <= gm.width; x++ {
+			if wallRight(x, y) {
+				line = append(line, vertical)
+			} else {
+				line = append(line, ' ')
+			}
+
+			if x < gm.width {
+				line = append(line, ' ', ' ')
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// applyZoom displays the zoomed view of the maze when wanted and when it
+// fits. Otherwise it removes it and places the maze view back.
+func (gm *Game) applyZoom(g *gocui.Gui, mv *gocui.View) {
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		return
+	}
+
+	vx, vy := ov.Size()
+	if !isZoomed || !gm.zoomFits(vx, vy) {
+		if !isZoomShown {
+			return
+		}
+
+		isZoomShown = false
+		if err = g.DeleteView(ZOOM); err != nil && err != gocui.ErrUnknownView {
+			logError("Failed to delete zoom view", "err", err)
+		}
+		mx1, my1, mx2, my2 := gm.mazeViewPosition(vx, vy)
+		if _, err = setView(g, MAZE, mx1, my1, mx2, my2); err != nil {
+			logError("Failed to move maze view back", "err", err)
+		}
+		_, _ = g.SetViewOnTop(MAZE)
+		raiseInfoViews(g)
+		redrawMarkers(g)
+		return
+	}
+
+	zx1, zy1, zx2, zy2 := gm.zoomViewPosition(vx, vy)
+	zoomView, err := setView(g, ZOOM, zx1, zy1, zx2, zy2)
+	if err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to display zoom view", "err", err)
+		return
+	}
+
+	zoomView.Frame = false
+	zoomView.FgColor = mv.FgColor
+	zoomView.BgColor = mv.BgColor
+	_, _ = g.SetViewOnTop(ZOOM)
+	// the outputs view hides the small cells.
+	_, _ = g.SetViewOnBottom(MAZE)
+
+	cx, cy := gm.mazeCursor(mv)
+	var drawn strings.Builder
+	for zy, line := range gm.zoomMazeLines(gm.data.String(), wallStyles[currentWallStyle], gm.theme) {
+		if zy > 0 {
+			drawn.WriteString("\n")
+		}
+
+		for zx, glyph := range line {
+			x, y := zoomToCursor(zx, zy)
+			switch {
+			case gm.fog > 0 && (abs(x-cx) > 2*gm.fog+1 || abs(y-cy) > gm.fog):
+				drawn.WriteByte(' ')
+			case zy%2 == 1 || zy == 0:
+				// only the cells lines are painted.
+				gm.writePainted(&drawn, glyph, x, y)
+			default:
+				drawn.WriteRune(glyph)
+			}
+		}
+	}
+
+	clearView(zoomView)
+	fmt.Fprint(zoomView, drawn.String())
+
+	wasShown := isZoomShown
+	isZoomShown = true
+	gm.placeZoomCursor(g, mv)
+	if !wasShown {
+		redrawMarkers(g)
+	}
+}
+
+// placeZoomCursor moves the hidden maze view so its cursor
+// stands on the player position into the zoomed view.
+func (gm *Game) placeZoomCursor(g *gocui.Gui, mv *gocui.View) {
+	if !isZoomShown {
+		return
+	}
+
+	zx, zy, _, _, err := g.ViewPosition(ZOOM)
+	if err != nil {
+		return
+	}
+
+	cx, cy := gm.mazeCursor(mv)
+	zcx, zcy := zoomCursor(cx, cy)
+	mx, my := zx+zcx-cx, zy+zcy-cy
+	if _, err = setView(g, MAZE, mx, my, mx+(2*gm.width+2), my+(gm.height+2)); err != nil {
+		logError("Failed to move maze view under zoom view", "err", err)
+	}
+}
+
+// mazeScreenPosition returns the terminal position of the maze cursor
+// (cx, cy) whether the maze is zoomed or scrolled or not.
+func (gm *Game) mazeScreenPosition(g *gocui.Gui, cx, cy int) (int, int, error) {
+	if isZoomShown {
+		zx, zy, _, _, err := g.ViewPosition(ZOOM)
+		zcx, zcy := zoomCursor(cx, cy)
+		return zx + 1 + zcx, zy + 1 + zcy, err
+	}
+
+	mx, my, _, _, err := g.ViewPosition(MAZE)
+	return mx + 1 + cx - gm.scrollX, my + 1 + cy - gm.scrollY, err
+}
+
+// toggleZoom switches between the small and the large cells and redraws
+// the displayed maze in place. A maze too large to be zoomed is kept
+// with small cells.
+func (gm *Game) toggleZoom(g *gocui.Gui, v *gocui.View) error {
+	isZoomed = !isZoomed
+	refreshOutputsTitle(g)
+
+	mv, err := g.View(MAZE)
+	if err != nil || gm.lines == nil {
+		return nil
+	}
+
+	gm.drawMaze(mv)
+
+	if isZoomed && !isZoomShown {
+		ov, err := g.View(OUTPUTS)
+		if err != nil {
+			return err
+		}
+		isZoomed = false
+		refreshOutputsTitle(g)
+		message := "\n " + trf("The maze of size %d x %d is too large", gm.width, gm.height) + "\n " + tr("to be zoomed into this terminal.") + "\n\n " + tr("Press Esc to close.")
+		return gm.displayPopupView(g, ov, ZOOM_ERROR, " Zoom ", message, SEWIDTH)
+	}
+	return nil
+}
+
+// closeZoomView removes the zoomed view if any.
+func closeZoomView(g *gocui.Gui) {
+	isZoomShown = false
+	if err := g.DeleteView(ZOOM); err != nil && err != gocui.ErrUnknownView {
+		logError("Failed to delete zoom view", "err", err)
+	}
+}